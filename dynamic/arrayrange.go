@@ -0,0 +1,63 @@
+package dynamic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+)
+
+// FromJSONArrayRange is similar to FromJSONImplied, while it only converts the window [start, end) of the
+// json array referenced by the JSON pointer in b, into a tuple. The end is clamped to the array length.
+//
+// The array is scanned as a token stream: the elements before the window are skipped without being decoded,
+// and the scan stops once the window is filled. Hence only the attr.Values of the window are built, while
+// the memory used besides that is bounded by the largest single element, not by the array length. Note that
+// b itself is still validated as a whole, as jsonset.Get does.
+func FromJSONArrayRange(b []byte, pointer string, start, end int) (types.Dynamic, error) {
+	if start < 0 || end < start {
+		return types.Dynamic{}, fmt.Errorf("invalid range [%d, %d)", start, end)
+	}
+	raw, err := jsonset.Get(b, pointer)
+	if err != nil {
+		return types.Dynamic{}, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	tok, err := dec.Token()
+	if err != nil {
+		return types.Dynamic{}, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return types.Dynamic{}, fmt.Errorf("%q: not an array", pointer)
+	}
+
+	d := newImpliedDecoder(Options{})
+	eTypes := []attr.Type{}
+	eVals := []attr.Value{}
+	for i := 0; i < end && dec.More(); i++ {
+		var elem json.RawMessage
+		if err := dec.Decode(&elem); err != nil {
+			return types.Dynamic{}, err
+		}
+		if i < start {
+			continue
+		}
+		eType, eVal, err := d.decode(elem)
+		if err != nil {
+			return types.Dynamic{}, err
+		}
+		eTypes = append(eTypes, eType)
+		eVals = append(eVals, eVal)
+	}
+
+	val, diags := types.TupleValue(eTypes, eVals)
+	if diags.HasError() {
+		diag := diags.Errors()[0]
+		return types.Dynamic{}, fmt.Errorf("%s: %s", diag.Summary(), diag.Detail())
+	}
+	return types.DynamicValue(val), nil
+}
@@ -0,0 +1,91 @@
+package dynamic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJSONArrayRange(t *testing.T) {
+	doc := `{"items": [{"id": 0}, {"id": 1}, "two", 3, [4], null], "name": "x"}`
+
+	cases := []struct {
+		name    string
+		pointer string
+		start   int
+		end     int
+		output  string
+		err     bool
+	}{
+		{
+			name:    "window in the middle",
+			pointer: "/items",
+			start:   1,
+			end:     4,
+			output:  `[{"id":1},"two",3]`,
+		},
+		{
+			name:    "end clamped",
+			pointer: "/items",
+			start:   4,
+			end:     100,
+			output:  `[[4],null]`,
+		},
+		{
+			name:    "empty window",
+			pointer: "/items",
+			start:   2,
+			end:     2,
+			output:  `[]`,
+		},
+		{
+			name:    "start beyond the length",
+			pointer: "/items",
+			start:   10,
+			end:     20,
+			output:  `[]`,
+		},
+		{
+			name:    "nested array",
+			pointer: "/items/4",
+			start:   0,
+			end:     1,
+			output:  `[4]`,
+		},
+		{
+			name:    "invalid range",
+			pointer: "/items",
+			start:   3,
+			end:     1,
+			err:     true,
+		},
+		{
+			name:    "not an array",
+			pointer: "/name",
+			start:   0,
+			end:     1,
+			err:     true,
+		},
+		{
+			name:    "not found",
+			pointer: "/nope",
+			start:   0,
+			end:     1,
+			err:     true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := FromJSONArrayRange([]byte(doc), tt.pointer, tt.start, tt.end)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			b, err := ToJSON(d)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.output, string(b))
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package dynamic
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// benchObject builds an object of n attributes of mixed primitive types.
+func benchObject(n int) types.Object {
+	attrTypes := map[string]attr.Type{}
+	attrVals := map[string]attr.Value{}
+	for i := 0; i < n; i++ {
+		switch i % 4 {
+		case 0:
+			attrTypes[fmt.Sprintf("str_%d", i)] = types.StringType
+			attrVals[fmt.Sprintf("str_%d", i)] = types.StringValue(fmt.Sprintf("value <%d> & more", i))
+		case 1:
+			attrTypes[fmt.Sprintf("num_%d", i)] = types.NumberType
+			attrVals[fmt.Sprintf("num_%d", i)] = types.NumberValue(big.NewFloat(float64(i) * 1.5))
+		case 2:
+			attrTypes[fmt.Sprintf("bool_%d", i)] = types.BoolType
+			attrVals[fmt.Sprintf("bool_%d", i)] = types.BoolValue(i%3 == 0)
+		case 3:
+			attrTypes[fmt.Sprintf("int_%d", i)] = types.Int64Type
+			attrVals[fmt.Sprintf("int_%d", i)] = types.Int64Value(int64(i))
+		}
+	}
+	return types.ObjectValueMust(attrTypes, attrVals)
+}
+
+// benchNested builds an object nested in depth levels, each level has width attributes besides the nested one.
+func benchNested(depth, width int) types.Object {
+	obj := benchObject(width)
+	for i := 0; i < depth; i++ {
+		attrTypes := obj.AttributeTypes(nil)
+		attrVals := obj.Attributes()
+		attrTypes["nested"] = obj.Type(nil)
+		attrVals["nested"] = obj
+		obj = types.ObjectValueMust(attrTypes, attrVals)
+	}
+	return obj
+}
+
+// benchArray builds a tuple of n objects.
+func benchArray(n, width int) types.Tuple {
+	var elemTypes []attr.Type
+	var elems []attr.Value
+	for i := 0; i < n; i++ {
+		obj := benchObject(width)
+		elemTypes = append(elemTypes, obj.Type(nil))
+		elems = append(elems, obj)
+	}
+	return types.TupleValueMust(elemTypes, elems)
+}
+
+func BenchmarkToJSON(b *testing.B) {
+	cases := []struct {
+		name  string
+		input types.Dynamic
+	}{
+		{name: "large", input: types.DynamicValue(benchObject(1000))},
+		{name: "nested", input: types.DynamicValue(benchNested(50, 20))},
+		{name: "array", input: types.DynamicValue(benchArray(500, 8))},
+	}
+	for _, tt := range cases {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ToJSON(tt.input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package dynamic
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+)
+
+// KeyPriority returns a Options.KeyCollator that emits the specified keys first, in the specified order, followed
+// by the other keys in the sorted order.
+func KeyPriority(keys ...string) func(a, b string) bool {
+	rank := map[string]int{}
+	for i, k := range keys {
+		if _, ok := rank[k]; !ok {
+			rank[k] = i
+		}
+	}
+	return func(a, b string) bool {
+		ra, oka := rank[a]
+		rb, okb := rank[b]
+		switch {
+		case oka && okb:
+			return ra < rb
+		case oka:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// sortedKeys returns the keys of the object in the emitting order. See Options.KeyCollator.
+func (e *jsonEncoder) sortedKeys(in map[string]attr.Value) []string {
+	keys := slices.Sorted(maps.Keys(in))
+	if less := e.opts.KeyCollator; less != nil {
+		slices.SortStableFunc(keys, func(a, b string) int {
+			switch {
+			case less(a, b):
+				return -1
+			case less(b, a):
+				return 1
+			default:
+				return 0
+			}
+		})
+	}
+	return keys
+}
@@ -0,0 +1,58 @@
+package dynamic
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToJSONOptsKeyCollator(t *testing.T) {
+	inner := types.ObjectValueMust(
+		map[string]attr.Type{"name": types.StringType, "id": types.StringType, "a": types.StringType},
+		map[string]attr.Value{"name": types.StringValue("n"), "id": types.StringValue("i"), "a": types.StringValue("a")},
+	)
+	in := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{
+			"z":     types.StringType,
+			"id":    types.StringType,
+			"props": inner.Type(nil),
+			"tags":  types.MapType{ElemType: types.StringType},
+		},
+		map[string]attr.Value{
+			"z":     types.StringValue("z"),
+			"id":    types.StringValue("i"),
+			"props": inner,
+			"tags":  types.MapValueMust(types.StringType, map[string]attr.Value{"b": types.StringValue("b"), "id": types.StringValue("i")}),
+		},
+	))
+
+	cases := []struct {
+		name   string
+		opts   Options
+		expect string
+	}{
+		{
+			name:   "default",
+			expect: `{"id":"i","props":{"a":"a","id":"i","name":"n"},"tags":{"b":"b","id":"i"},"z":"z"}`,
+		},
+		{
+			name:   "priority",
+			opts:   Options{KeyCollator: KeyPriority("z", "id")},
+			expect: `{"z":"z","id":"i","props":{"id":"i","a":"a","name":"n"},"tags":{"id":"i","b":"b"}}`,
+		},
+		{
+			name:   "reverse",
+			opts:   Options{KeyCollator: func(a, b string) bool { return a > b }},
+			expect: `{"z":"z","tags":{"id":"i","b":"b"},"props":{"name":"n","id":"i","a":"a"},"id":"i"}`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ToJSONOpts(in, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, string(b))
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package dynamic
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// ToCtyValue converts the dynamic value to a cty value, e.g. to bridge to the code based on the SDKv2.
+// The objects, tuples, lists, sets, maps and primitives are mapped to their cty counterparts. The null and unknown
+// values are preserved at any level, with their types if known, e.g. a null dynamic value results into
+// cty.NullVal(cty.DynamicPseudoType), while a null string attribute results into cty.NullVal(cty.String).
+func ToCtyValue(d types.Dynamic) (cty.Value, error) {
+	v, err := d.ToTerraformValue(context.Background())
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyValueFromTerraform(v)
+}
+
+// FromCtyValue converts the cty value to a dynamic value, as the inverse of ToCtyValue.
+// The capsule types are not supported.
+func FromCtyValue(v cty.Value) (types.Dynamic, error) {
+	tv, err := terraformValueFromCty(v)
+	if err != nil {
+		return types.Dynamic{}, err
+	}
+	av, err := types.DynamicType.ValueFromTerraform(context.Background(), tv)
+	if err != nil {
+		return types.Dynamic{}, err
+	}
+	return av.(types.Dynamic), nil
+}
+
+func ctyTypeFromTerraform(typ tftypes.Type) (cty.Type, error) {
+	switch {
+	case typ.Is(tftypes.DynamicPseudoType):
+		return cty.DynamicPseudoType, nil
+	case typ.Is(tftypes.String):
+		return cty.String, nil
+	case typ.Is(tftypes.Number):
+		return cty.Number, nil
+	case typ.Is(tftypes.Bool):
+		return cty.Bool, nil
+	case typ.Is(tftypes.Object{}):
+		attrTypes := map[string]cty.Type{}
+		for k, at := range typ.(tftypes.Object).AttributeTypes {
+			ct, err := ctyTypeFromTerraform(at)
+			if err != nil {
+				return cty.NilType, err
+			}
+			attrTypes[k] = ct
+		}
+		return cty.Object(attrTypes), nil
+	case typ.Is(tftypes.Tuple{}):
+		elemTypes := []cty.Type{}
+		for _, et := range typ.(tftypes.Tuple).ElementTypes {
+			ct, err := ctyTypeFromTerraform(et)
+			if err != nil {
+				return cty.NilType, err
+			}
+			elemTypes = append(elemTypes, ct)
+		}
+		return cty.Tuple(elemTypes), nil
+	case typ.Is(tftypes.List{}):
+		et, err := ctyTypeFromTerraform(typ.(tftypes.List).ElementType)
+		if err != nil {
+			return cty.NilType, err
+		}
+		return cty.List(et), nil
+	case typ.Is(tftypes.Set{}):
+		et, err := ctyTypeFromTerraform(typ.(tftypes.Set).ElementType)
+		if err != nil {
+			return cty.NilType, err
+		}
+		return cty.Set(et), nil
+	case typ.Is(tftypes.Map{}):
+		et, err := ctyTypeFromTerraform(typ.(tftypes.Map).ElementType)
+		if err != nil {
+			return cty.NilType, err
+		}
+		return cty.Map(et), nil
+	default:
+		return cty.NilType, fmt.Errorf("unsupported type %s", typ)
+	}
+}
+
+func ctyValueFromTerraform(v tftypes.Value) (cty.Value, error) {
+	typ, err := ctyTypeFromTerraform(v.Type())
+	if err != nil {
+		return cty.NilVal, err
+	}
+	if !v.IsKnown() {
+		return cty.UnknownVal(typ), nil
+	}
+	if v.IsNull() {
+		return cty.NullVal(typ), nil
+	}
+
+	switch {
+	case typ.Equals(cty.String):
+		var s string
+		if err := v.As(&s); err != nil {
+			return cty.NilVal, err
+		}
+		return cty.StringVal(s), nil
+	case typ.Equals(cty.Number):
+		var f big.Float
+		if err := v.As(&f); err != nil {
+			return cty.NilVal, err
+		}
+		return cty.NumberVal(&f), nil
+	case typ.Equals(cty.Bool):
+		var b bool
+		if err := v.As(&b); err != nil {
+			return cty.NilVal, err
+		}
+		return cty.BoolVal(b), nil
+	case typ.IsObjectType(), typ.IsMapType():
+		var m map[string]tftypes.Value
+		if err := v.As(&m); err != nil {
+			return cty.NilVal, err
+		}
+		vals := map[string]cty.Value{}
+		for k, tv := range m {
+			cv, err := ctyValueFromTerraform(tv)
+			if err != nil {
+				return cty.NilVal, fmt.Errorf("%q: %v", k, err)
+			}
+			vals[k] = cv
+		}
+		if typ.IsObjectType() {
+			if len(vals) == 0 {
+				return cty.EmptyObjectVal, nil
+			}
+			return cty.ObjectVal(vals), nil
+		}
+		if len(vals) == 0 {
+			return cty.MapValEmpty(typ.ElementType()), nil
+		}
+		if err := checkCtyElementTypes(typ, mapValues(vals)); err != nil {
+			return cty.NilVal, err
+		}
+		return cty.MapVal(vals), nil
+	default:
+		var l []tftypes.Value
+		if err := v.As(&l); err != nil {
+			return cty.NilVal, err
+		}
+		var vals []cty.Value
+		for i, tv := range l {
+			cv, err := ctyValueFromTerraform(tv)
+			if err != nil {
+				return cty.NilVal, fmt.Errorf("[%d]: %v", i, err)
+			}
+			vals = append(vals, cv)
+		}
+		switch {
+		case typ.IsTupleType():
+			if len(vals) == 0 {
+				return cty.EmptyTupleVal, nil
+			}
+			return cty.TupleVal(vals), nil
+		case len(vals) == 0 && typ.IsListType():
+			return cty.ListValEmpty(typ.ElementType()), nil
+		case len(vals) == 0:
+			return cty.SetValEmpty(typ.ElementType()), nil
+		}
+		if err := checkCtyElementTypes(typ, vals); err != nil {
+			return cty.NilVal, err
+		}
+		if typ.IsListType() {
+			return cty.ListVal(vals), nil
+		}
+		return cty.SetVal(vals), nil
+	}
+}
+
+// checkCtyElementTypes checks the elements of a cty collection having the same type, which cty.ListVal, cty.SetVal
+// and cty.MapVal panic on otherwise, e.g. for a tftypes collection of the dynamic element type.
+func checkCtyElementTypes(typ cty.Type, vals []cty.Value) error {
+	for _, v := range vals[1:] {
+		if !v.Type().Equals(vals[0].Type()) {
+			return fmt.Errorf("inconsistent element types of %s: %s and %s", typ.FriendlyName(), vals[0].Type().FriendlyName(), v.Type().FriendlyName())
+		}
+	}
+	return nil
+}
+
+func mapValues(m map[string]cty.Value) []cty.Value {
+	var vals []cty.Value
+	for _, v := range m {
+		vals = append(vals, v)
+	}
+	return vals
+}
+
+func terraformTypeFromCty(typ cty.Type) (tftypes.Type, error) {
+	switch {
+	case typ.Equals(cty.DynamicPseudoType):
+		return tftypes.DynamicPseudoType, nil
+	case typ.Equals(cty.String):
+		return tftypes.String, nil
+	case typ.Equals(cty.Number):
+		return tftypes.Number, nil
+	case typ.Equals(cty.Bool):
+		return tftypes.Bool, nil
+	case typ.IsObjectType():
+		attrTypes := map[string]tftypes.Type{}
+		for k, at := range typ.AttributeTypes() {
+			tt, err := terraformTypeFromCty(at)
+			if err != nil {
+				return nil, err
+			}
+			attrTypes[k] = tt
+		}
+		return tftypes.Object{AttributeTypes: attrTypes}, nil
+	case typ.IsTupleType():
+		elemTypes := []tftypes.Type{}
+		for _, et := range typ.TupleElementTypes() {
+			tt, err := terraformTypeFromCty(et)
+			if err != nil {
+				return nil, err
+			}
+			elemTypes = append(elemTypes, tt)
+		}
+		return tftypes.Tuple{ElementTypes: elemTypes}, nil
+	case typ.IsListType(), typ.IsSetType(), typ.IsMapType():
+		et, err := terraformTypeFromCty(typ.ElementType())
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case typ.IsListType():
+			return tftypes.List{ElementType: et}, nil
+		case typ.IsSetType():
+			return tftypes.Set{ElementType: et}, nil
+		default:
+			return tftypes.Map{ElementType: et}, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cty type %s", typ.FriendlyName())
+	}
+}
+
+func terraformValueFromCty(v cty.Value) (tftypes.Value, error) {
+	typ, err := terraformTypeFromCty(v.Type())
+	if err != nil {
+		return tftypes.Value{}, err
+	}
+	if !v.IsKnown() {
+		return tftypes.NewValue(typ, tftypes.UnknownValue), nil
+	}
+	if v.IsNull() {
+		return tftypes.NewValue(typ, nil), nil
+	}
+
+	ctyType := v.Type()
+	switch {
+	case ctyType.Equals(cty.String):
+		return tftypes.NewValue(typ, v.AsString()), nil
+	case ctyType.Equals(cty.Number):
+		return tftypes.NewValue(typ, v.AsBigFloat()), nil
+	case ctyType.Equals(cty.Bool):
+		return tftypes.NewValue(typ, v.True()), nil
+	case ctyType.IsObjectType(), ctyType.IsMapType():
+		vals := map[string]tftypes.Value{}
+		for k, cv := range v.AsValueMap() {
+			tv, err := terraformValueFromCty(cv)
+			if err != nil {
+				return tftypes.Value{}, fmt.Errorf("%q: %v", k, err)
+			}
+			vals[k] = tv
+		}
+		return tftypes.NewValue(typ, vals), nil
+	default:
+		vals := []tftypes.Value{}
+		for i, cv := range v.AsValueSlice() {
+			tv, err := terraformValueFromCty(cv)
+			if err != nil {
+				return tftypes.Value{}, fmt.Errorf("[%d]: %v", i, err)
+			}
+			vals = append(vals, tv)
+		}
+		return tftypes.NewValue(typ, vals), nil
+	}
+}
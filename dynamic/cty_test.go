@@ -0,0 +1,161 @@
+package dynamic
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCtyValue(t *testing.T) {
+	huge, _, err := big.ParseFloat("12345678901234567890.123456789", 10, 512, big.ToNearestEven)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name  string
+		input types.Dynamic
+		cty   cty.Value
+	}{
+		{
+			name:  "null",
+			input: types.DynamicNull(),
+			cty:   cty.NullVal(cty.DynamicPseudoType),
+		},
+		{
+			name:  "unknown",
+			input: types.DynamicUnknown(),
+			cty:   cty.DynamicVal,
+		},
+		{
+			name:  "typed null",
+			input: types.DynamicValue(types.StringNull()),
+			cty:   cty.NullVal(cty.String),
+		},
+		{
+			name:  "typed unknown",
+			input: types.DynamicValue(types.ListUnknown(types.NumberType)),
+			cty:   cty.UnknownVal(cty.List(cty.Number)),
+		},
+		{
+			name:  "string",
+			input: types.DynamicValue(types.StringValue("foo")),
+			cty:   cty.StringVal("foo"),
+		},
+		{
+			name:  "number",
+			input: types.DynamicValue(types.NumberValue(huge)),
+			cty:   cty.NumberVal(huge),
+		},
+		{
+			name:  "bool",
+			input: types.DynamicValue(types.BoolValue(true)),
+			cty:   cty.True,
+		},
+		{
+			name: "object",
+			input: types.DynamicValue(types.ObjectValueMust(
+				map[string]attr.Type{
+					"str":     types.StringType,
+					"null":    types.StringType,
+					"unknown": types.BoolType,
+					"dynamic": types.DynamicType,
+					"empty":   types.ObjectType{AttrTypes: map[string]attr.Type{}},
+				},
+				map[string]attr.Value{
+					"str":     types.StringValue("foo"),
+					"null":    types.StringNull(),
+					"unknown": types.BoolUnknown(),
+					"dynamic": types.DynamicNull(),
+					"empty":   types.ObjectValueMust(map[string]attr.Type{}, map[string]attr.Value{}),
+				},
+			)),
+			cty: cty.ObjectVal(map[string]cty.Value{
+				"str":     cty.StringVal("foo"),
+				"null":    cty.NullVal(cty.String),
+				"unknown": cty.UnknownVal(cty.Bool),
+				"dynamic": cty.NullVal(cty.DynamicPseudoType),
+				"empty":   cty.EmptyObjectVal,
+			}),
+		},
+		{
+			name: "tuple",
+			input: types.DynamicValue(types.TupleValueMust(
+				[]attr.Type{types.NumberType, types.DynamicType, types.StringType},
+				[]attr.Value{types.NumberValue(big.NewFloat(1)), types.DynamicUnknown(), types.StringNull()},
+			)),
+			cty: cty.TupleVal([]cty.Value{cty.NumberIntVal(1), cty.DynamicVal, cty.NullVal(cty.String)}),
+		},
+		{
+			name:  "empty tuple",
+			input: types.DynamicValue(types.TupleValueMust([]attr.Type{}, []attr.Value{})),
+			cty:   cty.EmptyTupleVal,
+		},
+		{
+			name: "list",
+			input: types.DynamicValue(types.ListValueMust(
+				types.StringType,
+				[]attr.Value{types.StringValue("a"), types.StringUnknown(), types.StringNull()},
+			)),
+			cty: cty.ListVal([]cty.Value{cty.StringVal("a"), cty.UnknownVal(cty.String), cty.NullVal(cty.String)}),
+		},
+		{
+			name:  "empty list",
+			input: types.DynamicValue(types.ListValueMust(types.StringType, []attr.Value{})),
+			cty:   cty.ListValEmpty(cty.String),
+		},
+		{
+			name: "set",
+			input: types.DynamicValue(types.SetValueMust(
+				types.BoolType,
+				[]attr.Value{types.BoolValue(true)},
+			)),
+			cty: cty.SetVal([]cty.Value{cty.True}),
+		},
+		{
+			name:  "empty set",
+			input: types.DynamicValue(types.SetValueMust(types.BoolType, []attr.Value{})),
+			cty:   cty.SetValEmpty(cty.Bool),
+		},
+		{
+			name: "map",
+			input: types.DynamicValue(types.MapValueMust(
+				types.ListType{ElemType: types.StringType},
+				map[string]attr.Value{
+					"a": types.ListValueMust(types.StringType, []attr.Value{types.StringValue("x")}),
+					"b": types.ListNull(types.StringType),
+				},
+			)),
+			cty: cty.MapVal(map[string]cty.Value{
+				"a": cty.ListVal([]cty.Value{cty.StringVal("x")}),
+				"b": cty.NullVal(cty.List(cty.String)),
+			}),
+		},
+		{
+			name:  "empty map",
+			input: types.DynamicValue(types.MapValueMust(types.NumberType, map[string]attr.Value{})),
+			cty:   cty.MapValEmpty(cty.Number),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ToCtyValue(tt.input)
+			require.NoError(t, err)
+			require.True(t, tt.cty.RawEquals(v), "%#v", v)
+
+			d, err := FromCtyValue(tt.cty)
+			require.NoError(t, err)
+			require.True(t, tt.input.Equal(d), d.String())
+		})
+	}
+}
+
+func TestFromCtyValueUnsupported(t *testing.T) {
+	capsule := cty.Capsule("capsule", reflect.TypeOf(0))
+	_, err := FromCtyValue(cty.ObjectVal(map[string]cty.Value{"a": cty.NullVal(capsule)}))
+	require.Error(t, err)
+}
@@ -0,0 +1,212 @@
+package dynamic
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable  = DurationType{}
+	_ basetypes.StringValuable = Duration{}
+)
+
+// DurationType is a custom string type for time durations. The Terraform value is a Go duration string
+// (e.g. "1h30m"), while its JSON representation (via ToJSON and FromJSON) is an ISO 8601 duration
+// string (e.g. "PT1H30M").
+type DurationType struct {
+	basetypes.StringType
+}
+
+func (t DurationType) Equal(o attr.Type) bool {
+	other, ok := o.(DurationType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t DurationType) String() string {
+	return "dynamic.DurationType"
+}
+
+func (t DurationType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return Duration{StringValue: in}, nil
+}
+
+func (t DurationType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return Duration{StringValue: stringValue}, nil
+}
+
+func (t DurationType) ValueType(_ context.Context) attr.Value {
+	return Duration{}
+}
+
+// Duration is the value of the DurationType.
+type Duration struct {
+	basetypes.StringValue
+}
+
+// NewDurationValue returns a known Duration.
+func NewDurationValue(d time.Duration) Duration {
+	return Duration{StringValue: basetypes.NewStringValue(d.String())}
+}
+
+// NewDurationNull returns a null Duration.
+func NewDurationNull() Duration {
+	return Duration{StringValue: basetypes.NewStringNull()}
+}
+
+// NewDurationUnknown returns an unknown Duration.
+func NewDurationUnknown() Duration {
+	return Duration{StringValue: basetypes.NewStringUnknown()}
+}
+
+func (v Duration) Equal(o attr.Value) bool {
+	other, ok := o.(Duration)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v Duration) Type(_ context.Context) attr.Type {
+	return DurationType{}
+}
+
+// ValueDuration parses the Go duration string of the known value.
+func (v Duration) ValueDuration() (time.Duration, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	d, err := time.ParseDuration(v.ValueString())
+	if err != nil {
+		diags.AddError("Invalid duration", err.Error())
+	}
+	return d, diags
+}
+
+// FormatISO8601Duration formats the duration as an ISO 8601 duration string, with the hour as the largest unit,
+// e.g. "PT1H30M", "PT0.5S". A negative duration is prefixed with "-", e.g. "-PT1H".
+func FormatISO8601Duration(d time.Duration) string {
+	if d == 0 {
+		return "PT0S"
+	}
+	var sb strings.Builder
+	// Work on the absolute value as uint64, so that the minimal duration doesn't overflow.
+	u := uint64(d)
+	if d < 0 {
+		sb.WriteString("-")
+		u = -u
+	}
+	sb.WriteString("PT")
+	if h := u / uint64(time.Hour); h > 0 {
+		sb.WriteString(strconv.FormatUint(h, 10) + "H")
+		u -= h * uint64(time.Hour)
+	}
+	if m := u / uint64(time.Minute); m > 0 {
+		sb.WriteString(strconv.FormatUint(m, 10) + "M")
+		u -= m * uint64(time.Minute)
+	}
+	if u > 0 {
+		s := strconv.FormatUint(u/uint64(time.Second), 10)
+		if ns := u % uint64(time.Second); ns > 0 {
+			s += strings.TrimRight(fmt.Sprintf(".%09d", ns), "0")
+		}
+		sb.WriteString(s + "S")
+	}
+	return sb.String()
+}
+
+// ParseISO8601Duration parses the ISO 8601 duration string, in the form of "[-]P[nW][nD][T[nH][nM][nS]]".
+// Only the last component can have a fraction. Years and months are not supported, as their lengths vary.
+// A day is regarded as 24 hours.
+func ParseISO8601Duration(s string) (time.Duration, error) {
+	orig := s
+	var neg bool
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = s[1:]
+	} else if strings.HasPrefix(s, "+") {
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") || len(s) == 1 {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+	}
+	s = s[1:]
+
+	const (
+		dateUnits = "WD"
+		timeUnits = "HMS"
+	)
+	unitDurations := map[byte]time.Duration{
+		'W': 7 * 24 * time.Hour,
+		'D': 24 * time.Hour,
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+	}
+	var (
+		total    = new(big.Float)
+		units    = dateUnits
+		next     int
+		inTime   bool
+		num      string
+		fraction bool
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 'T':
+			if inTime || num != "" || i == len(s)-1 {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+			}
+			inTime = true
+			units, next = timeUnits, 0
+		case c >= '0' && c <= '9' || c == '.' || c == ',':
+			if c == ',' {
+				c = '.'
+			}
+			num += string(c)
+		default:
+			// Each unit can only appear once, in order, and only the last one can have a fraction.
+			idx := strings.IndexByte(units, c)
+			if idx < next || num == "" || fraction {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+			}
+			next = idx + 1
+			v, ok := new(big.Float).SetString(num)
+			if !ok {
+				return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+			}
+			fraction = strings.Contains(num, ".")
+			total.Add(total, v.Mul(v, new(big.Float).SetInt64(int64(unitDurations[c]))))
+			num = ""
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", orig)
+	}
+	if neg {
+		total.Neg(total)
+	}
+	if total.Cmp(new(big.Float).SetInt64(int64(1<<63-1))) > 0 || total.Cmp(new(big.Float).SetInt64(-1<<63)) < 0 {
+		return 0, fmt.Errorf("ISO 8601 duration %q out of range", orig)
+	}
+	d, _ := total.Int64()
+	return time.Duration(d), nil
+}
@@ -0,0 +1,77 @@
+package dynamic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestISO8601Duration(t *testing.T) {
+	cases := []struct {
+		duration time.Duration
+		iso      string
+	}{
+		{duration: 0, iso: "PT0S"},
+		{duration: 90 * time.Minute, iso: "PT1H30M"},
+		{duration: 36 * time.Hour, iso: "PT36H"},
+		{duration: 1500 * time.Millisecond, iso: "PT1.5S"},
+		{duration: time.Nanosecond, iso: "PT0.000000001S"},
+		{duration: -time.Hour - time.Second, iso: "-PT1H1S"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.iso, func(t *testing.T) {
+			require.Equal(t, tt.iso, FormatISO8601Duration(tt.duration))
+			d, err := ParseISO8601Duration(tt.iso)
+			require.NoError(t, err)
+			require.Equal(t, tt.duration, d)
+		})
+	}
+
+	for iso, expect := range map[string]time.Duration{
+		"P1D":       24 * time.Hour,
+		"P1W":       7 * 24 * time.Hour,
+		"P1DT1H":    25 * time.Hour,
+		"PT0,5H":    30 * time.Minute,
+		"+PT1M":     time.Minute,
+		"-P1DT0.5S": -24*time.Hour - 500*time.Millisecond,
+	} {
+		d, err := ParseISO8601Duration(iso)
+		require.NoError(t, err, iso)
+		require.Equal(t, expect, d, iso)
+	}
+
+	for _, iso := range []string{"", "P", "PT", "1H", "P1Y", "P1M", "PT1S1M", "PT1.5M1S", "PT1H1H", "P1", "PT1HT1M"} {
+		_, err := ParseISO8601Duration(iso)
+		require.Error(t, err, iso)
+	}
+}
+
+func TestDurationJSON(t *testing.T) {
+	typ := types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"timeout": DurationType{},
+			"null":    DurationType{},
+		},
+	}
+	input := types.DynamicValue(types.ObjectValueMust(
+		typ.AttrTypes,
+		map[string]attr.Value{
+			"timeout": NewDurationValue(-90 * time.Minute),
+			"null":    NewDurationNull(),
+		},
+	))
+
+	b, err := ToJSON(input)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"timeout": "-PT1H30M", "null": null}`, string(b))
+
+	output, err := FromJSON(b, typ)
+	require.NoError(t, err)
+	require.Equal(t, input, output)
+
+	_, err = FromJSON([]byte(`{"timeout": "1h"}`), typ)
+	require.Error(t, err)
+}
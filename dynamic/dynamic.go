@@ -1,98 +1,76 @@
 package dynamic
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 )
 
+// ToJSON converts the dynamic value to JSON. A null or unknown value results into nil, and a nested unknown value is
+// emitted as null (see Options.DisallowUnknownValues).
+// The numbers are emitted without precision loss, i.e. the int64 values and the numbers that are not exactly
+// represented by float64 (e.g. an integer beyond 2^53 or a high-precision decimal) are emitted in their full
+// precision, rather than being routed through float64.
 func ToJSON(d types.Dynamic) ([]byte, error) {
-	if d.IsNull() || d.IsUnknown() {
-		return nil, nil
-	}
-	return attrValueToJSON(d)
+	return ToJSONOpts(d, Options{})
 }
 
-func attrListToJSON(in []attr.Value) ([]json.RawMessage, error) {
-	l := []json.RawMessage{}
-	for _, v := range in {
-		vv, err := attrValueToJSON(v)
-		if err != nil {
-			return nil, err
-		}
-		l = append(l, json.RawMessage(vv))
-	}
-	return l, nil
+// ToJSONOpts is similar to ToJSON, with the conversion tuned by opts.
+func ToJSONOpts(d types.Dynamic, opts Options) ([]byte, error) {
+	return ToJSONWithSchema(d, nil, opts)
 }
 
-func attrMapToJSON(in map[string]attr.Value) (map[string]json.RawMessage, error) {
-	m := map[string]json.RawMessage{}
-	for k, v := range in {
-		vv, err := attrValueToJSON(v)
-		if err != nil {
-			return nil, err
-		}
-		m[k] = json.RawMessage(vv)
+// ToJSONWithSchema is similar to ToJSON, with the conversion tuned by opts, which can refer to the
+// schema information (e.g. the default values) that is not carried by the dynamic value itself.
+// The schema can be nil.
+func ToJSONWithSchema(d types.Dynamic, schema *Schema, opts Options) ([]byte, error) {
+	if d.IsUnknown() && opts.DisallowUnknownValues {
+		return nil, unknownValueError(nil)
 	}
-	return m, nil
-}
-
-func attrValueToJSON(val attr.Value) ([]byte, error) {
-	if val.IsNull() || val.IsUnknown() {
-		return json.Marshal(nil)
-	}
-	if dval, ok := val.(types.Dynamic); ok {
-		val = dval.UnderlyingValue()
-	}
-	switch value := val.(type) {
-	case types.Bool:
-		return json.Marshal(value.ValueBool())
-	case types.String:
-		return json.Marshal(value.ValueString())
-	case types.Int64:
-		return json.Marshal(value.ValueInt64())
-	case types.Float64:
-		return json.Marshal(value.ValueFloat64())
-	case types.Number:
-		v, _ := value.ValueBigFloat().Float64()
-		return json.Marshal(v)
-	case types.List:
-		l, err := attrListToJSON(value.Elements())
-		if err != nil {
-			return nil, err
-		}
-		return json.Marshal(l)
-	case types.Set:
-		l, err := attrListToJSON(value.Elements())
-		if err != nil {
-			return nil, err
-		}
-		return json.Marshal(l)
-	case types.Tuple:
-		l, err := attrListToJSON(value.Elements())
-		if err != nil {
-			return nil, err
-		}
-		return json.Marshal(l)
-	case types.Map:
-		m, err := attrMapToJSON(value.Elements())
-		if err != nil {
-			return nil, err
+	if d.IsNull() || d.IsUnknown() {
+		return nil, nil
+	}
+	if opts.DropUnknownSubtrees && !IsFullyKnown(d) {
+		switch d.UnderlyingValue().(type) {
+		case types.Object, types.Map:
+		default:
+			return nil, nil
 		}
-		return json.Marshal(m)
-	case types.Object:
-		m, err := attrMapToJSON(value.Attributes())
-		if err != nil {
+	}
+	e := newJSONEncoder(opts)
+	defer e.release()
+	var err error
+	if opts.FieldMaskAttribute == "" {
+		err = e.encode(d, schema)
+	} else {
+		err = e.encodeWithFieldMaskAttribute(d, schema)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := e.checkFieldMask(); err != nil {
+		return nil, err
+	}
+	if opts.Indent != "" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, e.buf, "", opts.Indent); err != nil {
 			return nil, err
 		}
-		return json.Marshal(m)
-	default:
-		return nil, fmt.Errorf("Unhandled type: %T", value)
+		return buf.Bytes(), nil
 	}
+	return bytes.Clone(e.buf), nil
 }
 
 // FromJSON converts a JSON to dynamic types, instructed by the typ.
@@ -104,6 +82,86 @@ func FromJSON(b []byte, typ attr.Type) (types.Dynamic, error) {
 	return types.DynamicValue(v), nil
 }
 
+// FromJSONWithType is similar to FromJSON, with the conversion tuned by opts:
+//   - DisallowUnknownKeys: Error if the JSON has object keys that are not defined by the object types in typ.
+//   - EnumMaps: Translate the enum values back. See Options.EnumMaps.
+func FromJSONWithType(b []byte, typ attr.Type, opts Options) (types.Dynamic, error) {
+	b, err := untranslateEnums(b, opts)
+	if err != nil {
+		return types.Dynamic{}, err
+	}
+	if opts.DisallowUnknownKeys {
+		var unknown []string
+		if err := unknownKeys(b, typ, path.Empty(), &unknown); err != nil {
+			return types.Dynamic{}, err
+		}
+		if len(unknown) != 0 {
+			return types.Dynamic{}, fmt.Errorf("unknown keys: %s", strings.Join(unknown, ", "))
+		}
+	}
+	return FromJSON(b, typ)
+}
+
+// unknownKeys collects the paths of the JSON object keys that are not defined by the object types in typ.
+// The JSON is not validated against typ otherwise, which is left to attrValueFromJSON.
+func unknownKeys(b []byte, typ attr.Type, p path.Path, unknown *[]string) error {
+	if b == nil || string(b) == "null" {
+		return nil
+	}
+	switch typ := typ.(type) {
+	case basetypes.ObjectType:
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(b, &m); err != nil {
+			return err
+		}
+		attrTypes := typ.AttributeTypes()
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			attrType, ok := attrTypes[k]
+			if !ok {
+				*unknown = append(*unknown, p.AtName(k).String())
+				continue
+			}
+			if err := unknownKeys(m[k], attrType, p.AtName(k), unknown); err != nil {
+				return err
+			}
+		}
+	case basetypes.MapType:
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(b, &m); err != nil {
+			return err
+		}
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			if err := unknownKeys(m[k], typ.ElemType, p.AtMapKey(k), unknown); err != nil {
+				return err
+			}
+		}
+	case basetypes.ListType, basetypes.SetType, basetypes.TupleType:
+		var l []json.RawMessage
+		if err := json.Unmarshal(b, &l); err != nil {
+			return err
+		}
+		for i, e := range l {
+			var etyp attr.Type
+			switch typ := typ.(type) {
+			case basetypes.ListType:
+				etyp = typ.ElemType
+			case basetypes.SetType:
+				etyp = typ.ElemType
+			case basetypes.TupleType:
+				if i >= len(typ.ElemTypes) {
+					// The size mismatch is reported by attrValueFromJSON.
+					return nil
+				}
+				etyp = typ.ElemTypes[i]
+			}
+			if err := unknownKeys(e, etyp, p.AtListIndex(i), unknown); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func attrListFromJSON(b []byte, etyp attr.Type) ([]attr.Value, error) {
 	var l []json.RawMessage
 	if err := json.Unmarshal(b, &l); err != nil {
@@ -140,6 +198,40 @@ func attrValueFromJSON(b []byte, typ attr.Type) (attr.Value, error) {
 			return nil, err
 		}
 		return types.StringValue(v), nil
+	case DurationType:
+		if b == nil || string(b) == "null" {
+			return NewDurationNull(), nil
+		}
+		var v string
+		if err := json.Unmarshal(b, &v); err != nil {
+			return nil, err
+		}
+		d, err := ParseISO8601Duration(v)
+		if err != nil {
+			return nil, err
+		}
+		return NewDurationValue(d), nil
+	case TimestampType:
+		if b == nil || string(b) == "null" {
+			return NewTimestampNull(), nil
+		}
+		var v string
+		if err := json.Unmarshal(b, &v); err != nil {
+			return nil, err
+		}
+		if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+			return nil, err
+		}
+		return Timestamp{StringValue: types.StringValue(v)}, nil
+	case RawJSONStringType:
+		if b == nil || string(b) == "null" {
+			return NewRawJSONStringNull(), nil
+		}
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, b); err != nil {
+			return nil, err
+		}
+		return NewRawJSONStringValue(buf.String()), nil
 	case basetypes.Int64Type:
 		if b == nil || string(b) == "null" {
 			return types.Int64Null(), nil
@@ -162,11 +254,15 @@ func attrValueFromJSON(b []byte, typ attr.Type) (attr.Value, error) {
 		if b == nil || string(b) == "null" {
 			return types.NumberNull(), nil
 		}
-		var v float64
+		var v json.Number
 		if err := json.Unmarshal(b, &v); err != nil {
 			return nil, err
 		}
-		return types.NumberValue(big.NewFloat(v)), nil
+		f, err := parseNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		return types.NumberValue(f), nil
 	case basetypes.ListType:
 		if b == nil || string(b) == "null" {
 			return types.ListNull(typ.ElemType), nil
@@ -279,24 +375,71 @@ func attrValueFromJSON(b []byte, typ attr.Type) (attr.Value, error) {
 // FromJSONImplied is similar to FromJSON, while it is for typeless case.
 // In which case, the following type conversion rules are applied (Go -> TF):
 // - bool: bool
-// - float64: number
+// - json.Number: number (without precision loss, so that it round trips through ToJSON)
 // - string: string
 // - []interface{}: tuple
 // - map[string]interface{}: object
 // - nil: null (dynamic)
-// In case the input json is of zero-length, it returns null (dynamic).
+// In case the input json is of zero-length, or is a top level null, it returns null (dynamic). The empty arrays and
+// objects are kept as the empty tuples and objects, distinct from null.
+//
+// It is the inverse of ToJSON for the arbitrary JSON, e.g. the nullified ephemeral body read back from the private
+// state, or an API response payload. The typed counterpart is FromJSON.
 func FromJSONImplied(b []byte) (types.Dynamic, error) {
+	return FromJSONOpts(b, Options{})
+}
+
+// FromJSONOpts is similar to FromJSONImplied, with the conversion tuned by opts.
+func FromJSONOpts(b []byte, opts Options) (types.Dynamic, error) {
 	if len(b) == 0 {
 		return types.DynamicNull(), nil
 	}
-	_, v, err := attrValueFromJSONImplied(b)
+	b, err := untranslateEnums(b, opts)
+	if err != nil {
+		return types.Dynamic{}, err
+	}
+	_, v, err := newImpliedDecoder(opts).decode(b)
 	if err != nil {
 		return types.Dynamic{}, err
 	}
+	if v.IsNull() {
+		return types.DynamicNull(), nil
+	}
 	return types.DynamicValue(v), nil
 }
 
-func attrValueFromJSONImplied(b []byte) (attr.Type, attr.Value, error) {
+type impliedDecoder struct {
+	opts Options
+
+	// strs holds the interned strings, only used when opts.InternStrings is set.
+	strs map[string]string
+
+	// path is the attribute path of the value being decoded.
+	path []string
+
+	mapPaths []attrPattern
+}
+
+func newImpliedDecoder(opts Options) *impliedDecoder {
+	d := &impliedDecoder{opts: opts, mapPaths: parseAttrPatterns(opts.MapPaths)}
+	if opts.InternStrings {
+		d.strs = map[string]string{}
+	}
+	return d
+}
+
+func (d *impliedDecoder) intern(s string) string {
+	if d.strs == nil {
+		return s
+	}
+	if is, ok := d.strs[s]; ok {
+		return is
+	}
+	d.strs[s] = s
+	return s
+}
+
+func (d *impliedDecoder) decode(b []byte) (attr.Type, attr.Value, error) {
 	if string(b) == "null" {
 		return types.DynamicType, types.DynamicNull(), nil
 	}
@@ -306,11 +449,21 @@ func attrValueFromJSONImplied(b []byte) (attr.Type, attr.Value, error) {
 		attrTypes := map[string]attr.Type{}
 		attrVals := map[string]attr.Value{}
 		for k, v := range object {
-			attrTypes[k], attrVals[k], err = attrValueFromJSONImplied(v)
+			d.path = append(d.path, k)
+			attrTypes[k], attrVals[k], err = d.decode(v)
+			d.path = d.path[:len(d.path)-1]
 			if err != nil {
 				return nil, nil, err
 			}
 		}
+		if etyp := d.mapElemType(attrTypes); etyp != nil {
+			val, diags := types.MapValue(etyp, attrVals)
+			if diags.HasError() {
+				diag := diags.Errors()[0]
+				return nil, nil, fmt.Errorf("%s: %s", diag.Summary(), diag.Detail())
+			}
+			return types.MapType{ElemType: etyp}, val, nil
+		}
 		typ := types.ObjectType{AttrTypes: attrTypes}
 		val, diags := types.ObjectValue(attrTypes, attrVals)
 		if diags.HasError() {
@@ -325,7 +478,7 @@ func attrValueFromJSONImplied(b []byte) (attr.Type, attr.Value, error) {
 		eTypes := []attr.Type{}
 		eVals := []attr.Value{}
 		for _, e := range array {
-			eType, eVal, err := attrValueFromJSONImplied(e)
+			eType, eVal, err := d.decode(e)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -343,22 +496,80 @@ func attrValueFromJSONImplied(b []byte) (attr.Type, attr.Value, error) {
 
 	// Primitives
 	var v interface{}
-	if err := json.Unmarshal(b, &v); err != nil {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
 		return nil, nil, fmt.Errorf("failed to unmarshal %s: %v", string(b), err)
 	}
 
 	switch v := v.(type) {
 	case bool:
 		return types.BoolType, types.BoolValue(v), nil
-	case float64:
-		return types.NumberType, types.NumberValue(big.NewFloat(v)), nil
+	case json.Number:
+		f, err := parseNumber(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		return types.NumberType, types.NumberValue(f), nil
 	case string:
-		return types.StringType, types.StringValue(v), nil
+		return types.StringType, types.StringValue(d.intern(v)), nil
 	default:
 		return nil, nil, fmt.Errorf("Unhandled type: %T", v)
 	}
 }
 
+// mapElemType returns the element type if the object at the current path shall be decoded as a map, i.e. the
+// object is non-empty and homogeneous, while either PreferMap is set or the path matches MapPaths.
+// Otherwise, nil is returned.
+func (d *impliedDecoder) mapElemType(attrTypes map[string]attr.Type) attr.Type {
+	if len(attrTypes) == 0 {
+		return nil
+	}
+	if !d.opts.PreferMap && !matchAnyAttr(d.mapPaths, d.path) {
+		return nil
+	}
+	var etyp attr.Type
+	for _, t := range attrTypes {
+		if etyp == nil {
+			etyp = t
+			continue
+		}
+		if !etyp.Equal(t) {
+			return nil
+		}
+	}
+	return etyp
+}
+
+// parseNumber parses the JSON number. The numbers that are exactly represented by the shortest form of the nearest
+// float64 (e.g. 1.23, 100) are kept as the float64 (in 53 bits precision), the others (e.g. the integers beyond
+// 2^53) are parsed in a higher precision, so that they round trip through ToJSON without precision loss.
+func parseNumber(n json.Number) (*big.Float, error) {
+	f, err := strconv.ParseFloat(n.String(), 64)
+	switch {
+	case errors.Is(err, strconv.ErrRange):
+		// The finite number beyond the float64 range, e.g. 1e400, is held by the big.Float below.
+	case err != nil:
+		return nil, fmt.Errorf("invalid number %s: %v", n, err)
+	default:
+		exact, ok := new(big.Rat).SetString(n.String())
+		if !ok {
+			return nil, fmt.Errorf("invalid number %s", n)
+		}
+		if shortest, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64)); shortest.Cmp(exact) == 0 {
+			return big.NewFloat(f), nil
+		}
+	}
+	bf, _, err := big.ParseFloat(n.String(), 10, numberPrec, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %s: %v", n, err)
+	}
+	return bf, nil
+}
+
+// numberPrec is the precision of the numbers that can't be held by a float64.
+const numberPrec = 512
+
 // IsFullyKnown returns true if `val` is known. If `val` is an aggregate type,
 // IsFullyKnown only returns true if all elements and attributes are known, as
 // well.
@@ -2,7 +2,9 @@ package dynamic
 
 import (
 	"context"
+	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
@@ -729,3 +731,686 @@ func TestFromJSONImplied(t *testing.T) {
 		})
 	}
 }
+
+func TestFromJSONOptsInternStrings(t *testing.T) {
+	input := `{"a": "eastus", "b": ["eastus", "westus", "eastus"], "c": {"d": "westus"}}`
+
+	expect, err := FromJSONImplied([]byte(input))
+	require.NoError(t, err)
+
+	actual, err := FromJSONOpts([]byte(input), Options{InternStrings: true})
+	require.NoError(t, err)
+	require.Equal(t, expect, actual)
+}
+
+func TestFromJSONOptsPreferMap(t *testing.T) {
+	input := `{"tags": {"a": "x", "b": "y"}, "mixed": {"a": "x", "b": 1}, "empty": {}, "rules": [{"labels": {"k": "v"}}]}`
+
+	cases := []struct {
+		name  string
+		opts  Options
+		types map[string]attr.Type
+	}{
+		{
+			name: "global",
+			opts: Options{PreferMap: true},
+			types: map[string]attr.Type{
+				"tags":  types.MapType{ElemType: types.StringType},
+				"mixed": types.ObjectType{AttrTypes: map[string]attr.Type{"a": types.StringType, "b": types.NumberType}},
+				"empty": types.ObjectType{AttrTypes: map[string]attr.Type{}},
+				"rules": types.TupleType{ElemTypes: []attr.Type{
+					types.MapType{ElemType: types.MapType{ElemType: types.StringType}},
+				}},
+			},
+		},
+		{
+			name: "paths",
+			opts: Options{MapPaths: []string{"rules.labels", "mixed"}},
+			types: map[string]attr.Type{
+				"tags":  types.ObjectType{AttrTypes: map[string]attr.Type{"a": types.StringType, "b": types.StringType}},
+				"mixed": types.ObjectType{AttrTypes: map[string]attr.Type{"a": types.StringType, "b": types.NumberType}},
+				"empty": types.ObjectType{AttrTypes: map[string]attr.Type{}},
+				"rules": types.TupleType{ElemTypes: []attr.Type{
+					types.ObjectType{AttrTypes: map[string]attr.Type{"labels": types.MapType{ElemType: types.StringType}}},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := FromJSONOpts([]byte(input), tt.opts)
+			require.NoError(t, err)
+			// The top level object is heterogeneous, hence kept as an object.
+			obj, ok := d.UnderlyingValue().(types.Object)
+			require.True(t, ok)
+			require.Equal(t, tt.types, obj.AttributeTypes(context.Background()))
+
+			b, err := ToJSON(d)
+			require.NoError(t, err)
+			require.JSONEq(t, input, string(b))
+		})
+	}
+}
+
+func BenchmarkFromJSONOpts(b *testing.B) {
+	var elems []string
+	for i := 0; i < 1000; i++ {
+		elems = append(elems, `{"location": "eastus", "kind": "Microsoft.Storage/storageAccounts", "sku": "Standard_LRS"}`)
+	}
+	input := []byte("[" + strings.Join(elems, ",") + "]")
+
+	for _, intern := range []bool{false, true} {
+		b.Run(fmt.Sprintf("InternStrings=%t", intern), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := FromJSONOpts(input, Options{InternStrings: intern}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestToJSONOptsStringNullPolicy(t *testing.T) {
+	input := types.DynamicValue(
+		types.ObjectValueMust(
+			map[string]attr.Type{
+				"null":  types.StringType,
+				"empty": types.StringType,
+				"dyn":   types.DynamicType,
+				"nested": types.ObjectType{
+					AttrTypes: map[string]attr.Type{
+						"null":  types.StringType,
+						"empty": types.StringType,
+					},
+				},
+			},
+			map[string]attr.Value{
+				"null":  types.StringNull(),
+				"empty": types.StringValue(""),
+				"dyn":   types.DynamicNull(),
+				"nested": types.ObjectValueMust(
+					map[string]attr.Type{
+						"null":  types.StringType,
+						"empty": types.StringType,
+					},
+					map[string]attr.Value{
+						"null":  types.StringNull(),
+						"empty": types.StringValue(""),
+					},
+				),
+			},
+		),
+	)
+
+	cases := []struct {
+		name   string
+		opts   Options
+		expect string
+	}{
+		{
+			name:   "as is",
+			opts:   Options{},
+			expect: `{"null": null, "empty": "", "dyn": null, "nested": {"null": null, "empty": ""}}`,
+		},
+		{
+			name:   "null as empty",
+			opts:   Options{StringNullPolicy: StringNullAsEmpty},
+			expect: `{"null": "", "empty": "", "dyn": null, "nested": {"null": "", "empty": ""}}`,
+		},
+		{
+			name:   "empty as null",
+			opts:   Options{StringNullPolicy: StringEmptyAsNull},
+			expect: `{"null": null, "empty": null, "dyn": null, "nested": {"null": null, "empty": null}}`,
+		},
+		{
+			name:   "null as empty for selected paths",
+			opts:   Options{StringNullPolicy: StringNullAsEmpty, StringNullPolicyPaths: []string{"nested.null"}},
+			expect: `{"null": null, "empty": "", "dyn": null, "nested": {"null": "", "empty": ""}}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ToJSONOpts(input, tt.opts)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expect, string(b))
+		})
+	}
+}
+
+func TestToJSONFormat(t *testing.T) {
+	// The output must be identical to json.Marshal, i.e. compact, with keys sorted and HTML characters escaped.
+	input := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{
+			"s1":    types.StringType,
+			"s2":    types.StringType,
+			"s3":    types.StringType,
+			"k<&>":  types.StringType,
+			"f1":    types.Float64Type,
+			"f2":    types.Float64Type,
+			"f3":    types.Float64Type,
+			"n1":    types.NumberType,
+			"l":     types.ListType{ElemType: types.StringType},
+			"m":     types.MapType{ElemType: types.BoolType},
+			"empty": types.ObjectType{AttrTypes: map[string]attr.Type{}},
+		},
+		map[string]attr.Value{
+			"s1":   types.StringValue("\u2028\x01\b\f\n\r\t\"\\/"),
+			"s2":   types.StringValue("é中文"),
+			"s3":   types.StringValue("\xff bad"),
+			"k<&>": types.StringNull(),
+			"f1":   types.Float64Value(1e21),
+			"f2":   types.Float64Value(1e-7),
+			"f3":   types.Float64Value(-123456789.125),
+			"n1":   types.NumberValue(big.NewFloat(0.1)),
+			"l":    types.ListValueMust(types.StringType, []attr.Value{}),
+			"m": types.MapValueMust(types.BoolType, map[string]attr.Value{
+				"b": types.BoolValue(false),
+				"a": types.BoolUnknown(),
+			}),
+			"empty": types.ObjectValueMust(map[string]attr.Type{}, map[string]attr.Value{}),
+		},
+	))
+	b, err := ToJSON(input)
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"empty":{},"f1":1e+21,"f2":1e-7,"f3":-123456789.125,"k\u003c\u0026\u003e":null,"l":[],"m":{"a":null,"b":false},"n1":0.1,"s1":"\u2028\u0001\b\f\n\r\t\"\\/","s2":"é中文","s3":"� bad"}`,
+		string(b),
+	)
+}
+
+func TestToJSONOptsFieldMask(t *testing.T) {
+	input, err := FromJSONImplied([]byte(`{"a": {"b": 1, "c": 2}, "c": "x", "d": [{"e": 1, "f": 2}], "g": null}`))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		opts   Options
+		expect string
+		err    bool
+	}{
+		{
+			name:   "no mask",
+			opts:   Options{},
+			expect: `{"a": {"b": 1, "c": 2}, "c": "x", "d": [{"e": 1, "f": 2}], "g": null}`,
+		},
+		{
+			name:   "masked paths and ancestors",
+			opts:   Options{FieldMask: []string{"a.b", "c"}},
+			expect: `{"a": {"b": 1}, "c": "x"}`,
+		},
+		{
+			name:   "subtree and list elements",
+			opts:   Options{FieldMask: []string{"a", "d.f"}},
+			expect: `{"a": {"b": 1, "c": 2}, "d": [{"f": 2}]}`,
+		},
+		{
+			name:   "missing path is ignored",
+			opts:   Options{FieldMask: []string{"c", "x.y"}},
+			expect: `{"c": "x"}`,
+		},
+		{
+			name: "missing path errors in strict mode",
+			opts: Options{FieldMask: []string{"c", "x.y"}, StrictFieldMask: true},
+			err:  true,
+		},
+		{
+			name:   "null attribute exists in strict mode",
+			opts:   Options{FieldMask: []string{"g"}, StrictFieldMask: true},
+			expect: `{"g": null}`,
+		},
+		{
+			name:   "mask attribute",
+			opts:   Options{FieldMask: []string{"a.b", "c"}, FieldMaskAttribute: "updateMask"},
+			expect: `{"a": {"b": 1}, "c": "x", "updateMask": "a.b,c"}`,
+		},
+		{
+			name: "mask attribute conflicts",
+			opts: Options{FieldMask: []string{"a"}, FieldMaskAttribute: "c"},
+			err:  true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ToJSONOpts(input, tt.opts)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expect, string(b))
+		})
+	}
+}
+
+func TestFromJSONWithTypeDisallowUnknownKeys(t *testing.T) {
+	typ := types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"name": types.StringType,
+			"rules": types.ListType{ElemType: types.ObjectType{
+				AttrTypes: map[string]attr.Type{"port": types.Int64Type},
+			}},
+			"tags": types.MapType{ElemType: types.ObjectType{
+				AttrTypes: map[string]attr.Type{"value": types.StringType},
+			}},
+			"extra": types.DynamicType,
+		},
+	}
+
+	cases := []struct {
+		name   string
+		input  string
+		strict bool
+		err    string
+	}{
+		{
+			name:  "lenient by default",
+			input: `{"name": "foo", "unknown": 1}`,
+		},
+		{
+			name:   "known keys",
+			input:  `{"name": "foo", "rules": [{"port": 80}], "tags": {"a": {"value": "x"}}, "extra": {"any": 1}}`,
+			strict: true,
+		},
+		{
+			name:   "unknown keys are listed with paths",
+			input:  `{"name": "foo", "z": 1, "rules": [{"port": 80}, {"port": 443, "proto": "tcp"}], "tags": {"a": {"value": "x", "b": 1}}}`,
+			strict: true,
+			err:    `unknown keys: rules[1].proto, tags["a"].b, z`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromJSONWithType([]byte(tt.input), typ, Options{DisallowUnknownKeys: tt.strict})
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestToJSONOptsSortArrayPaths(t *testing.T) {
+	input, err := FromJSONImplied([]byte(`{
+	"tags": ["b", "a", null, 10, 9.5, true, false, "A"],
+	"seq": [3, 1, 2],
+	"rules": [{"name": "y"}, {"name": "x"}],
+	"nested": {"tags": ["z", "y"]}
+}`))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		opts   Options
+		expect string
+	}{
+		{
+			name:   "no sort",
+			opts:   Options{},
+			expect: `{"tags": ["b", "a", null, 10, 9.5, true, false, "A"], "seq": [3, 1, 2], "rules": [{"name": "y"}, {"name": "x"}], "nested": {"tags": ["z", "y"]}}`,
+		},
+		{
+			name:   "scalar arrays",
+			opts:   Options{SortArrayPaths: []string{"tags", "seq", "rules", "*.tags"}},
+			expect: `{"tags": [null, false, true, 9.5, 10, "A", "a", "b"], "seq": [1, 2, 3], "rules": [{"name": "y"}, {"name": "x"}], "nested": {"tags": ["y", "z"]}}`,
+		},
+		{
+			name:   "object arrays",
+			opts:   Options{SortArrayPaths: []string{"rules"}, SortObjectArrays: true},
+			expect: `{"tags": ["b", "a", null, 10, 9.5, true, false, "A"], "seq": [3, 1, 2], "rules": [{"name": "x"}, {"name": "y"}], "nested": {"tags": ["z", "y"]}}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ToJSONOpts(input, tt.opts)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expect, string(b))
+		})
+	}
+}
+
+func TestToJSONOptsMaxDepth(t *testing.T) {
+	nested := func(depth int) types.Dynamic {
+		var (
+			typ attr.Type  = types.StringType
+			val attr.Value = types.StringValue("x")
+		)
+		for range depth {
+			typ, val = types.ListType{ElemType: typ}, types.ListValueMust(typ, []attr.Value{val})
+		}
+		return types.DynamicValue(val)
+	}
+
+	_, err := ToJSONOpts(nested(3), Options{MaxDepth: 3})
+	require.NoError(t, err)
+
+	_, err = ToJSONOpts(nested(4), Options{MaxDepth: 3})
+	require.ErrorIs(t, err, ErrMaxDepthExceeded)
+}
+
+func TestToJSONOptsDropUnknownSubtrees(t *testing.T) {
+	inner := types.ObjectValueMust(
+		map[string]attr.Type{"a": types.StringType, "b": types.StringType},
+		map[string]attr.Value{"a": types.StringValue("x"), "b": types.StringUnknown()},
+	)
+	known := types.ObjectValueMust(
+		map[string]attr.Type{"a": types.StringType},
+		map[string]attr.Value{"a": types.StringValue("y")},
+	)
+	cases := []struct {
+		name   string
+		input  types.Dynamic
+		output string
+	}{
+		{
+			name: "object with unknown descendants",
+			input: types.DynamicValue(types.ObjectValueMust(
+				map[string]attr.Type{
+					"inner": inner.Type(context.Background()),
+					"known": known.Type(context.Background()),
+					"list":  types.ListType{ElemType: types.StringType},
+					"id":    types.StringType,
+					"null":  types.StringType,
+				},
+				map[string]attr.Value{
+					"inner": inner,
+					"known": known,
+					"list":  types.ListValueMust(types.StringType, []attr.Value{types.StringValue("x"), types.StringUnknown()}),
+					"id":    types.StringUnknown(),
+					"null":  types.StringNull(),
+				},
+			)),
+			output: `{"known":{"a":"y"},"null":null}`,
+		},
+		{
+			name: "map with unknown dynamic element",
+			input: types.DynamicValue(types.MapValueMust(types.DynamicType, map[string]attr.Value{
+				"a": types.DynamicValue(types.StringValue("x")),
+				"b": types.DynamicUnknown(),
+			})),
+			output: `{"a":"x"}`,
+		},
+		{
+			name:   "top level list with unknown element",
+			input:  types.DynamicValue(types.ListValueMust(types.StringType, []attr.Value{types.StringValue("x"), types.StringUnknown()})),
+			output: ``,
+		},
+		{
+			name:   "fully known top level list",
+			input:  types.DynamicValue(types.ListValueMust(types.StringType, []attr.Value{types.StringValue("x")})),
+			output: `["x"]`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ToJSONOpts(tt.input, Options{DropUnknownSubtrees: true})
+			require.NoError(t, err)
+			require.Equal(t, tt.output, string(b))
+		})
+	}
+}
+
+func TestToJSONNumberPrecision(t *testing.T) {
+	cases := []struct {
+		name   string
+		typ    attr.Type
+		number string
+	}{
+		{name: "int64 beyond 2^53", typ: types.Int64Type, number: "9007199254740993"},
+		{name: "max int64", typ: types.Int64Type, number: "9223372036854775807"},
+		{name: "number beyond 2^53", typ: types.NumberType, number: "9007199254740993"},
+		{name: "number beyond 2^64", typ: types.NumberType, number: "-18446744073709551617"},
+		{name: "high-precision decimal", typ: types.NumberType, number: "0.1000000000000000000001"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			typ := types.ObjectType{AttrTypes: map[string]attr.Type{"id": tt.typ}}
+			input := `{"id":` + tt.number + `}`
+			d, err := FromJSON([]byte(input), typ)
+			require.NoError(t, err)
+			b, err := ToJSON(d)
+			require.NoError(t, err)
+			require.Equal(t, input, string(b))
+		})
+	}
+
+	f, _, err := big.ParseFloat("9007199254740993", 10, 512, big.ToNearestEven)
+	require.NoError(t, err)
+	b, err := ToJSON(types.DynamicValue(types.NumberValue(f)))
+	require.NoError(t, err)
+	require.Equal(t, "9007199254740993", string(b))
+}
+
+func TestFromJSONImpliedRoundTrip(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{name: "object", input: `{"a":{"b":[1,"x",true,null]},"c":{},"d":[]}`},
+		{name: "big integer", input: `{"id":12345678901234567890,"neg":-9007199254740993}`},
+		{name: "precise fraction", input: `[0.12345678901234567890123,1.5,0.1,1e+21]`},
+		{name: "beyond float64 range", input: `[1e+400,-2.5e+400,1e-400]`},
+		{name: "top level array", input: `[[],{},[{}]]`},
+		{name: "top level scalar", input: `"x"`},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := FromJSONImplied([]byte(tt.input))
+			require.NoError(t, err)
+			b, err := ToJSON(d)
+			require.NoError(t, err)
+			require.Equal(t, tt.input, string(b))
+
+			d2, err := FromJSONImplied(b)
+			require.NoError(t, err)
+			require.True(t, d.Equal(d2))
+		})
+	}
+
+	d, err := FromJSONImplied([]byte(`null`))
+	require.NoError(t, err)
+	require.True(t, d.IsNull())
+}
+
+func TestToJSONOptsOmitNulls(t *testing.T) {
+	innerType := map[string]attr.Type{"x": types.StringType}
+	in := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{
+			"a":     types.StringType,
+			"b":     types.StringType,
+			"empty": types.StringType,
+			"inner": types.ObjectType{AttrTypes: innerType},
+			"list":  types.ListType{ElemType: types.StringType},
+			"dyn":   types.DynamicType,
+		},
+		map[string]attr.Value{
+			"a":     types.StringValue("x"),
+			"b":     types.StringNull(),
+			"empty": types.StringValue(""),
+			"inner": types.ObjectValueMust(innerType, map[string]attr.Value{"x": types.StringNull()}),
+			"list":  types.ListValueMust(types.StringType, []attr.Value{types.StringNull(), types.StringValue("y")}),
+			"dyn":   types.DynamicNull(),
+		},
+	))
+
+	cases := []struct {
+		name   string
+		opts   Options
+		output string
+	}{
+		{
+			name:   "default",
+			output: `{"a":"x","b":null,"dyn":null,"empty":"","inner":{"x":null},"list":[null,"y"]}`,
+		},
+		{
+			name:   "omit nulls",
+			opts:   Options{OmitNulls: true},
+			output: `{"a":"x","empty":"","inner":{},"list":[null,"y"]}`,
+		},
+		{
+			name:   "omit nulls with empty as null",
+			opts:   Options{OmitNulls: true, StringNullPolicy: StringEmptyAsNull},
+			output: `{"a":"x","inner":{},"list":[null,"y"]}`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ToJSONOpts(in, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.output, string(b))
+		})
+	}
+}
+
+func TestToJSONOptsIndent(t *testing.T) {
+	in := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{
+			"id":   types.StringType,
+			"list": types.ListType{ElemType: types.Int64Type},
+			"obj":  types.ObjectType{AttrTypes: map[string]attr.Type{}},
+		},
+		map[string]attr.Value{
+			"id":   types.StringValue("x"),
+			"list": types.ListValueMust(types.Int64Type, []attr.Value{types.Int64Value(2), types.Int64Value(1)}),
+			"obj":  types.ObjectValueMust(map[string]attr.Type{}, map[string]attr.Value{}),
+		},
+	))
+
+	cases := []struct {
+		name   string
+		opts   Options
+		output string
+	}{
+		{
+			name:   "default",
+			output: `{"id":"x","list":[2,1],"obj":{}}`,
+		},
+		{
+			name:   "indent",
+			opts:   Options{Indent: "  "},
+			output: "{\n  \"id\": \"x\",\n  \"list\": [\n    2,\n    1\n  ],\n  \"obj\": {}\n}",
+		},
+		{
+			name:   "indent with key collator and sorted arrays",
+			opts:   Options{Indent: "\t", KeyCollator: KeyPriority("obj"), SortArrayPaths: []string{"list"}},
+			output: "{\n\t\"obj\": {},\n\t\"id\": \"x\",\n\t\"list\": [\n\t\t1,\n\t\t2\n\t]\n}",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ToJSONOpts(in, tt.opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.output, string(b))
+		})
+	}
+}
+
+func TestToJSONOptsDisallowUnknownValues(t *testing.T) {
+	objType := map[string]attr.Type{
+		"a":    types.StringType,
+		"list": types.ListType{ElemType: types.StringType},
+	}
+	obj := func(a, elem types.String) types.Dynamic {
+		return types.DynamicValue(types.ObjectValueMust(objType, map[string]attr.Value{
+			"a":    a,
+			"list": types.ListValueMust(types.StringType, []attr.Value{elem}),
+		}))
+	}
+
+	cases := []struct {
+		name   string
+		in     types.Dynamic
+		opts   Options
+		output string
+		err    string
+	}{
+		{
+			name:   "nested unknown emitted as null by default",
+			in:     obj(types.StringUnknown(), types.StringValue("x")),
+			output: `{"a":null,"list":["x"]}`,
+		},
+		{
+			name: "unknown",
+			in:   types.DynamicUnknown(),
+			opts: Options{DisallowUnknownValues: true},
+			err:  `unknown value: the value at "." is unknown`,
+		},
+		{
+			name: "nested unknown attribute",
+			in:   obj(types.StringUnknown(), types.StringValue("x")),
+			opts: Options{DisallowUnknownValues: true},
+			err:  `unknown value: the value at "a" is unknown`,
+		},
+		{
+			name: "nested unknown element",
+			in:   obj(types.StringValue("x"), types.StringUnknown()),
+			opts: Options{DisallowUnknownValues: true},
+			err:  `unknown value: the value at "list" is unknown`,
+		},
+		{
+			name:   "dropped unknown subtrees",
+			in:     obj(types.StringValue("x"), types.StringUnknown()),
+			opts:   Options{DisallowUnknownValues: true, DropUnknownSubtrees: true},
+			output: `{"a":"x"}`,
+		},
+		{
+			name:   "null",
+			in:     types.DynamicNull(),
+			opts:   Options{DisallowUnknownValues: true},
+			output: "",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ToJSONOpts(tt.in, tt.opts)
+			if tt.err != "" {
+				require.ErrorIs(t, err, ErrUnknownValue)
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.output, string(b))
+		})
+	}
+}
+
+func TestToJSONOptsMaxArrayLen(t *testing.T) {
+	list := func(n int) types.List {
+		elems := make([]attr.Value, n)
+		for i := range elems {
+			elems[i] = types.Int64Value(int64(i))
+		}
+		return types.ListValueMust(types.Int64Type, elems)
+	}
+	obj := func(n int) types.Dynamic {
+		return types.DynamicValue(types.ObjectValueMust(
+			map[string]attr.Type{"a": types.ObjectType{AttrTypes: map[string]attr.Type{"b": types.ListType{ElemType: types.Int64Type}}}},
+			map[string]attr.Value{"a": types.ObjectValueMust(
+				map[string]attr.Type{"b": types.ListType{ElemType: types.Int64Type}},
+				map[string]attr.Value{"b": list(n)},
+			)},
+		))
+	}
+
+	_, err := ToJSONOpts(obj(3), Options{MaxArrayLen: 3})
+	require.NoError(t, err)
+
+	_, err = ToJSONOpts(obj(100), Options{})
+	require.NoError(t, err)
+
+	_, err = ToJSONOpts(obj(4), Options{MaxArrayLen: 3})
+	require.ErrorIs(t, err, ErrMaxArrayLenExceeded)
+	require.ErrorContains(t, err, `"a.b"`)
+	require.ErrorContains(t, err, "limit of 3")
+
+	_, err = ToJSONOpts(types.DynamicValue(list(2)), Options{MaxArrayLen: 1})
+	require.ErrorIs(t, err, ErrMaxArrayLenExceeded)
+}
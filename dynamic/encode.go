@@ -0,0 +1,472 @@
+package dynamic
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ErrMaxDepthExceeded is returned (wrapped) when the value being converted is nested deeper than Options.MaxDepth.
+var ErrMaxDepthExceeded = errors.New("max depth exceeded")
+
+// ErrMaxArrayLenExceeded is returned (wrapped) when an array of the value being converted has more elements than
+// Options.MaxArrayLen.
+var ErrMaxArrayLenExceeded = errors.New("max array length exceeded")
+
+// ErrUnknownValue is returned (wrapped) when the value being converted is, or contains, an unknown value, with
+// Options.DisallowUnknownValues.
+var ErrUnknownValue = errors.New("unknown value")
+
+// bufPool pools the output buffers of the jsonEncoder, to reduce allocations for large values.
+var bufPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// jsonEncoder encodes the attribute values to JSON, by appending to a single buffer.
+// The output is identical to encoding the values via json.Marshal, i.e. compact, with object keys sorted
+// and HTML characters escaped.
+type jsonEncoder struct {
+	opts Options
+
+	buf []byte
+
+	// path is the attribute path of the value being encoded.
+	path []string
+
+	// depth is the nesting depth of the container being encoded.
+	depth    int
+	maxDepth int
+
+	stringNullPolicyPaths []attrPattern
+
+	sortArrayPaths []attrPattern
+
+	enumMaps []enumMap
+
+	fieldMask []attrPattern
+	// fieldMaskFound records whether each of the fieldMask paths is found.
+	fieldMaskFound []bool
+}
+
+func newJSONEncoder(opts Options) *jsonEncoder {
+	return &jsonEncoder{
+		opts:                  opts,
+		buf:                   (*bufPool.Get().(*[]byte))[:0],
+		stringNullPolicyPaths: parseAttrPatterns(opts.StringNullPolicyPaths),
+		maxDepth:              cmp.Or(opts.MaxDepth, DefaultMaxDepth),
+		sortArrayPaths:        parseAttrPatterns(opts.SortArrayPaths),
+		enumMaps:              parseEnumMaps(opts.EnumMaps),
+		fieldMask:             parseAttrPatterns(opts.FieldMask),
+		fieldMaskFound:        make([]bool, len(opts.FieldMask)),
+	}
+}
+
+// release returns the buffer to the pool. The encoder can't be used afterwards.
+func (e *jsonEncoder) release() {
+	// Avoid keeping overly large buffers in the pool.
+	if cap(e.buf) <= 1<<20 {
+		buf := e.buf[:0]
+		bufPool.Put(&buf)
+	}
+	e.buf = nil
+}
+
+// enter enters a container, returning an error if the depth exceeds the limit. It must be paired with leave.
+func (e *jsonEncoder) enter() error {
+	e.depth++
+	if e.depth > e.maxDepth {
+		return fmt.Errorf("%w: the value is nested deeper than %d", ErrMaxDepthExceeded, e.maxDepth)
+	}
+	return nil
+}
+
+func (e *jsonEncoder) leave() {
+	e.depth--
+}
+
+// inFieldMask tells whether the attribute at the path is covered by the field mask, i.e. it is either an ancestor of,
+// or inside the subtree of a field mask path.
+func (e *jsonEncoder) inFieldMask(path []string) bool {
+	if len(e.fieldMask) == 0 {
+		return true
+	}
+	if len(path) == 1 && path[0] == e.opts.FieldMaskAttribute {
+		return true
+	}
+	var in bool
+	for i, p := range e.fieldMask {
+		n := min(len(p), len(path))
+		if !p[:n].match(path[:n]) {
+			continue
+		}
+		in = true
+		if len(p) == len(path) {
+			e.fieldMaskFound[i] = true
+		}
+	}
+	return in
+}
+
+// checkFieldMask returns an error if any field mask path is not found, in case StrictFieldMask is set.
+func (e *jsonEncoder) checkFieldMask() error {
+	if !e.opts.StrictFieldMask {
+		return nil
+	}
+	for i, found := range e.fieldMaskFound {
+		if !found {
+			return fmt.Errorf("field mask path %q not found", e.opts.FieldMask[i])
+		}
+	}
+	return nil
+}
+
+// encodeWithFieldMaskAttribute encodes the object or map typed value, with the FieldMaskAttribute added.
+func (e *jsonEncoder) encodeWithFieldMaskAttribute(d types.Dynamic, schema *Schema) error {
+	var attrs map[string]attr.Value
+	switch v := d.UnderlyingValue().(type) {
+	case types.Object:
+		if !v.IsNull() && !v.IsUnknown() {
+			attrs = maps.Clone(v.Attributes())
+		}
+	case types.Map:
+		if !v.IsNull() && !v.IsUnknown() {
+			attrs = maps.Clone(v.Elements())
+		}
+	}
+	if attrs == nil {
+		return fmt.Errorf("field mask attribute %q can only be added to a known object or map", e.opts.FieldMaskAttribute)
+	}
+	if _, ok := attrs[e.opts.FieldMaskAttribute]; ok {
+		return fmt.Errorf("field mask attribute %q already exists", e.opts.FieldMaskAttribute)
+	}
+	attrs[e.opts.FieldMaskAttribute] = types.StringValue(strings.Join(e.opts.FieldMask, ","))
+	return e.encodeMap(attrs, schema)
+}
+
+// applyStringNullPolicy converts the string typed value according to the StringNullPolicy.
+func (e *jsonEncoder) applyStringNullPolicy(val attr.Value) attr.Value {
+	s, ok := val.(types.String)
+	if !ok || s.IsUnknown() || e.opts.StringNullPolicy == StringNullAsIs {
+		return val
+	}
+	if len(e.stringNullPolicyPaths) != 0 && !matchAnyAttr(e.stringNullPolicyPaths, e.path) {
+		return val
+	}
+	switch e.opts.StringNullPolicy {
+	case StringNullAsEmpty:
+		if s.IsNull() {
+			return types.StringValue("")
+		}
+	case StringEmptyAsNull:
+		if !s.IsNull() && s.ValueString() == "" {
+			return types.StringNull()
+		}
+	}
+	return val
+}
+
+// emitsNull tells whether the value of the attribute k (of the current path) is emitted as null, for OmitNulls.
+func (e *jsonEncoder) emitsNull(k string, v attr.Value) bool {
+	e.path = append(e.path, k)
+	defer func() { e.path = e.path[:len(e.path)-1] }()
+	v = e.applyStringNullPolicy(underlyingValue(v))
+	return v.IsNull()
+}
+
+// unknownValueError returns the error wrapping ErrUnknownValue for the unknown value at the attribute path.
+func unknownValueError(path []string) error {
+	p := strings.Join(path, ".")
+	if p == "" {
+		p = "."
+	}
+	return fmt.Errorf("%w: the value at %q is unknown", ErrUnknownValue, p)
+}
+
+func (e *jsonEncoder) encodeList(in []attr.Value, schema *Schema) error {
+	if err := e.enter(); err != nil {
+		return err
+	}
+	defer e.leave()
+	if e.opts.MaxArrayLen > 0 && len(in) > e.opts.MaxArrayLen {
+		p := strings.Join(e.path, ".")
+		if p == "" {
+			p = "."
+		}
+		return fmt.Errorf("%w: the array at %q has %d elements, beyond the limit of %d", ErrMaxArrayLenExceeded, p, len(in), e.opts.MaxArrayLen)
+	}
+	if len(e.sortArrayPaths) != 0 && matchAnyAttr(e.sortArrayPaths, e.path) {
+		return e.encodeSortedList(in, schema)
+	}
+	e.buf = append(e.buf, '[')
+	for i, v := range in {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		if err := e.encode(v, schema.element()); err != nil {
+			return err
+		}
+	}
+	e.buf = append(e.buf, ']')
+	return nil
+}
+
+// encodeSortedList encodes the list with the elements sorted. See Options.SortArrayPaths for the ordering.
+func (e *jsonEncoder) encodeSortedList(in []attr.Value, schema *Schema) error {
+	start := len(e.buf)
+	elems := make([][]byte, 0, len(in))
+	scalar := true
+	for _, v := range in {
+		mark := len(e.buf)
+		if err := e.encode(v, schema.element()); err != nil {
+			return err
+		}
+		elem := bytes.Clone(e.buf[mark:])
+		if elem[0] == '{' || elem[0] == '[' {
+			scalar = false
+		}
+		elems = append(elems, elem)
+	}
+	e.buf = e.buf[:start]
+
+	switch {
+	case scalar:
+		slices.SortStableFunc(elems, compareJSONScalar)
+	case e.opts.SortObjectArrays:
+		slices.SortStableFunc(elems, bytes.Compare)
+	}
+
+	e.buf = append(e.buf, '[')
+	for i, elem := range elems {
+		if i > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		e.buf = append(e.buf, elem...)
+	}
+	e.buf = append(e.buf, ']')
+	return nil
+}
+
+// compareJSONScalar compares two encoded JSON scalars: null < false < true < numbers < strings.
+func compareJSONScalar(a, b []byte) int {
+	rank := func(v []byte) int {
+		switch v[0] {
+		case 'n':
+			return 0
+		case 'f':
+			return 1
+		case 't':
+			return 2
+		case '"':
+			return 4
+		default:
+			return 3
+		}
+	}
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	switch ra {
+	case 3:
+		na, _ := new(big.Rat).SetString(string(a))
+		nb, _ := new(big.Rat).SetString(string(b))
+		if na != nil && nb != nil {
+			return na.Cmp(nb)
+		}
+	case 4:
+		var sa, sb string
+		if json.Unmarshal(a, &sa) == nil && json.Unmarshal(b, &sb) == nil {
+			return strings.Compare(sa, sb)
+		}
+	}
+	return bytes.Compare(a, b)
+}
+
+func (e *jsonEncoder) encodeMap(in map[string]attr.Value, schema *Schema) error {
+	if err := e.enter(); err != nil {
+		return err
+	}
+	defer e.leave()
+	e.buf = append(e.buf, '{')
+	first := true
+	for _, k := range e.sortedKeys(in) {
+		v := in[k]
+		if !e.inFieldMask(append(e.path, k)) {
+			continue
+		}
+		if e.opts.DropUnknownSubtrees && !IsFullyKnown(v) {
+			continue
+		}
+		if e.opts.OmitNulls && e.emitsNull(k, v) {
+			continue
+		}
+		asch := schema.attribute(k)
+		if e.opts.OmitDefaults && asch != nil && asch.Default != nil && valueEqual(v, asch.Default) {
+			continue
+		}
+		if !first {
+			e.buf = append(e.buf, ',')
+		}
+		first = false
+		e.buf = appendString(e.buf, k)
+		e.buf = append(e.buf, ':')
+		e.path = append(e.path, k)
+		err := e.encode(v, asch)
+		e.path = e.path[:len(e.path)-1]
+		if err != nil {
+			return err
+		}
+	}
+	e.buf = append(e.buf, '}')
+	return nil
+}
+
+func (e *jsonEncoder) encode(val attr.Value, schema *Schema) error {
+	if dval, ok := val.(types.Dynamic); ok && !dval.IsNull() && !dval.IsUnknown() {
+		val = dval.UnderlyingValue()
+	}
+	if val.IsUnknown() && e.opts.DisallowUnknownValues {
+		return unknownValueError(e.path)
+	}
+	val = e.applyStringNullPolicy(val)
+	if val.IsNull() || val.IsUnknown() {
+		e.buf = append(e.buf, "null"...)
+		return nil
+	}
+	switch value := val.(type) {
+	case types.Bool:
+		e.buf = strconv.AppendBool(e.buf, value.ValueBool())
+		return nil
+	case types.String:
+		s, err := translateEnum(e.enumMaps, e.path, value.ValueString(), e.opts.StrictEnumMaps)
+		if err != nil {
+			return err
+		}
+		e.buf = appendString(e.buf, s)
+		return nil
+	case types.Int64:
+		e.buf = strconv.AppendInt(e.buf, value.ValueInt64(), 10)
+		return nil
+	case types.Float64:
+		return e.encodeFloat(value.ValueFloat64())
+	case types.Number:
+		return e.encodeNumber(value.ValueBigFloat())
+	case Duration:
+		d, diags := value.ValueDuration()
+		if diags.HasError() {
+			diag := diags.Errors()[0]
+			return fmt.Errorf("%s: %s", diag.Summary(), diag.Detail())
+		}
+		e.buf = appendString(e.buf, FormatISO8601Duration(d))
+		return nil
+	case Timestamp:
+		t, diags := value.ValueTime()
+		if diags.HasError() {
+			diag := diags.Errors()[0]
+			return fmt.Errorf("%s: %s", diag.Summary(), diag.Detail())
+		}
+		if e.opts.TimeZone == TimeZoneUTC {
+			e.buf = appendString(e.buf, t.UTC().Format(time.RFC3339Nano))
+			return nil
+		}
+		e.buf = appendString(e.buf, value.ValueString())
+		return nil
+	case RawJSONString:
+		raw, diags := value.ValueRawJSON()
+		if diags.HasError() {
+			diag := diags.Errors()[0]
+			return fmt.Errorf("%s: %s", diag.Summary(), diag.Detail())
+		}
+		// Escape the same as json.Marshal does for the string values.
+		out := bytes.NewBuffer(e.buf)
+		json.HTMLEscape(out, raw)
+		e.buf = out.Bytes()
+		return nil
+	case types.List:
+		return e.encodeList(value.Elements(), schema)
+	case types.Set:
+		return e.encodeList(value.Elements(), schema)
+	case types.Tuple:
+		return e.encodeList(value.Elements(), schema)
+	case types.Map:
+		return e.encodeMap(value.Elements(), schema)
+	case types.Object:
+		return e.encodeMap(value.Attributes(), schema)
+	default:
+		return fmt.Errorf("Unhandled type: %T", value)
+	}
+}
+
+// encodeNumber encodes the number. The number that is exactly a float64 is encoded as encodeFloat does, the others
+// are encoded in their full precision. An integer is encoded in its digits, unless it only approximates its shortest
+// decimal representation, e.g. 1e400 parsed into the binary big.Float, which is encoded in that representation.
+func (e *jsonEncoder) encodeNumber(f *big.Float) error {
+	v, acc := f.Float64()
+	if acc == big.Exact || f.IsInf() {
+		return e.encodeFloat(v)
+	}
+	shortest := f.Text('g', -1)
+	if f.IsInt() {
+		exact, _ := f.Rat(nil)
+		if r, ok := new(big.Rat).SetString(shortest); ok && r.Cmp(exact) == 0 {
+			e.buf = exact.Num().Append(e.buf, 10)
+			return nil
+		}
+	}
+	e.buf = append(e.buf, shortest...)
+	return nil
+}
+
+// encodeFloat encodes the float64 in the same format as json.Marshal.
+func (e *jsonEncoder) encodeFloat(f float64) error {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return &json.UnsupportedValueError{Str: strconv.FormatFloat(f, 'g', -1, 64)}
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	e.buf = strconv.AppendFloat(e.buf, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		n := len(e.buf)
+		if n >= 4 && e.buf[n-4] == 'e' && e.buf[n-3] == '-' && e.buf[n-2] == '0' {
+			e.buf[n-2] = e.buf[n-1]
+			e.buf = e.buf[:n-1]
+		}
+	}
+	return nil
+}
+
+// appendString appends the JSON string in the same format as json.Marshal.
+// Strings that only consist of safe ASCII characters are appended directly, the others fall back to json.Marshal
+// to get the identical escaping.
+func appendString(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c >= utf8.RuneSelf || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			b, _ := json.Marshal(s)
+			return append(buf, b...)
+		}
+	}
+	buf = append(buf, '"')
+	buf = append(buf, s...)
+	return append(buf, '"')
+}
@@ -0,0 +1,111 @@
+package dynamic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// enumMap is a parsed entry of the Options.EnumMaps.
+type enumMap struct {
+	pattern attrPattern
+	path    string
+	values  map[string]string
+}
+
+func parseEnumMaps(enumMaps map[string]map[string]string) []enumMap {
+	var out []enumMap
+	for _, p := range slices.Sorted(maps.Keys(enumMaps)) {
+		out = append(out, enumMap{
+			pattern: strings.Split(p, "."),
+			path:    p,
+			values:  enumMaps[p],
+		})
+	}
+	return out
+}
+
+// translateEnum translates the string value at the path via the first matched enum map.
+func translateEnum(enumMaps []enumMap, path []string, s string, strict bool) (string, error) {
+	for _, m := range enumMaps {
+		if !m.pattern.match(path) {
+			continue
+		}
+		if v, ok := m.values[s]; ok {
+			return v, nil
+		}
+		if strict {
+			return "", fmt.Errorf("unmapped enum value %q at %q", s, strings.Join(path, "."))
+		}
+		return s, nil
+	}
+	return s, nil
+}
+
+// invertEnumMaps returns the enum maps with the values inverted, which errors if any map is not one-to-one.
+func invertEnumMaps(enumMaps []enumMap) ([]enumMap, error) {
+	var out []enumMap
+	for _, m := range enumMaps {
+		inv := map[string]string{}
+		for k, v := range m.values {
+			if _, ok := inv[v]; ok {
+				return nil, fmt.Errorf("enum map at %q is not invertible: %q is mapped from more than one value", m.path, v)
+			}
+			inv[v] = k
+		}
+		out = append(out, enumMap{pattern: m.pattern, path: m.path, values: inv})
+	}
+	return out, nil
+}
+
+// untranslateEnums translates the enum values in the JSON back, via the inverted Options.EnumMaps.
+func untranslateEnums(b []byte, opts Options) ([]byte, error) {
+	if len(opts.EnumMaps) == 0 || len(b) == 0 {
+		return b, nil
+	}
+	enumMaps, err := invertEnumMaps(parseEnumMaps(opts.EnumMaps))
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	v, err = untranslateEnumValue(enumMaps, nil, v, opts.StrictEnumMaps)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func untranslateEnumValue(enumMaps []enumMap, path []string, v interface{}, strict bool) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return translateEnum(enumMaps, path, v, strict)
+	case []interface{}:
+		for i, e := range v {
+			ne, err := untranslateEnumValue(enumMaps, path, e, strict)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = ne
+		}
+		return v, nil
+	case map[string]interface{}:
+		for k, e := range v {
+			ne, err := untranslateEnumValue(enumMaps, append(slices.Clone(path), k), e, strict)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = ne
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
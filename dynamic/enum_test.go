@@ -0,0 +1,78 @@
+package dynamic
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnumMaps(t *testing.T) {
+	enumMaps := map[string]map[string]string{
+		"status":      {"active": "ACTIVE", "inactive": "INACTIVE"},
+		"rules.proto": {"tcp": "TCP"},
+	}
+	typ := types.ObjectType{AttrTypes: map[string]attr.Type{
+		"status": types.StringType,
+		"name":   types.StringType,
+		"rules": types.ListType{ElemType: types.ObjectType{
+			AttrTypes: map[string]attr.Type{"proto": types.StringType},
+		}},
+	}}
+
+	cases := []struct {
+		name   string
+		input  string
+		strict bool
+		output string
+		err    bool
+	}{
+		{
+			name:   "mapped values",
+			input:  `{"status": "active", "name": "active", "rules": [{"proto": "tcp"}]}`,
+			output: `{"status": "ACTIVE", "name": "active", "rules": [{"proto": "TCP"}]}`,
+		},
+		{
+			name:   "unmapped values pass through",
+			input:  `{"status": "unknown", "name": "x", "rules": [{"proto": "udp"}]}`,
+			output: `{"status": "unknown", "name": "x", "rules": [{"proto": "udp"}]}`,
+		},
+		{
+			name:   "unmapped values error in strict mode",
+			input:  `{"status": "active", "name": "x", "rules": [{"proto": "udp"}]}`,
+			strict: true,
+			err:    true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{EnumMaps: enumMaps, StrictEnumMaps: tt.strict}
+			d, err := FromJSON([]byte(tt.input), typ)
+			require.NoError(t, err)
+
+			b, err := ToJSONOpts(d, opts)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.output, string(b))
+
+			// The inverse translation
+			rd, err := FromJSONWithType(b, typ, opts)
+			require.NoError(t, err)
+			require.True(t, d.Equal(rd))
+
+			rd, err = FromJSONOpts(b, opts)
+			require.NoError(t, err)
+			rb, err := ToJSON(rd)
+			require.NoError(t, err)
+			require.JSONEq(t, tt.input, string(rb))
+		})
+	}
+
+	_, err := FromJSONOpts([]byte(`{"status": "A"}`), Options{EnumMaps: map[string]map[string]string{"status": {"a": "A", "b": "A"}}})
+	require.ErrorContains(t, err, "not invertible")
+}
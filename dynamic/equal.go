@@ -0,0 +1,208 @@
+package dynamic
+
+import (
+	"maps"
+	"math/big"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// FirstDiff compares two dynamic values semantically, and returns the path of the first difference,
+// together with whether they differ at all. The comparison is performed depth-first, with the object
+// attributes and map keys visited in sorted order, so the returned path is deterministic.
+// A null or unknown value on either side differs at the path where it appears (unless both are null).
+func FirstDiff(a, b types.Dynamic) (path.Path, bool) {
+	return valueComparer{}.firstDiff(path.Empty(), a, b)
+}
+
+// EqualOptions tunes the comparison of EqualOpts.
+type EqualOptions struct {
+	// UnknownsEqual makes two unknown values equal, while an unknown value is still not equal to a known one.
+	UnknownsEqual bool
+}
+
+// Equal tells whether two dynamic values are semantically equal, e.g. to tell whether a dynamic attribute actually
+// changed in a plan modifier:
+//   - Object attributes and map elements are compared as sets of keys, regardless of the order.
+//   - Numbers (int64, float64, number) are compared by value, regardless of the type, e.g. 1 equals 1.0.
+//   - Set elements are compared regardless of the order, while the list and tuple elements are compared in order.
+//   - Two null values are equal.
+//   - Unknown values are never equal to anything, including another unknown value.
+func Equal(a, b types.Dynamic) bool {
+	return EqualOpts(a, b, EqualOptions{})
+}
+
+// EqualOpts is similar to Equal, with the comparison tuned by opts.
+func EqualOpts(a, b types.Dynamic, opts EqualOptions) bool {
+	return valueComparer{unknownsEqual: opts.UnknownsEqual}.equal(a, b)
+}
+
+// valueEqual tells whether two attribute values are semantically equal, as Equal does.
+func valueEqual(a, b attr.Value) bool {
+	return valueComparer{}.equal(a, b)
+}
+
+type valueComparer struct {
+	unknownsEqual bool
+}
+
+func (c valueComparer) firstDiff(p path.Path, a, b attr.Value) (path.Path, bool) {
+	a, b = underlyingValue(a), underlyingValue(b)
+	aNull, bNull := a == nil || a.IsNull(), b == nil || b.IsNull()
+	if aNull || bNull {
+		return p, aNull != bNull
+	}
+	if a.IsUnknown() || b.IsUnknown() {
+		return p, !(c.unknownsEqual && a.IsUnknown() && b.IsUnknown())
+	}
+
+	if an, ok := numberValue(a); ok {
+		bn, ok := numberValue(b)
+		return p, !ok || an.Cmp(bn) != 0
+	}
+
+	switch a := a.(type) {
+	case types.Bool:
+		b, ok := b.(types.Bool)
+		return p, !ok || a.ValueBool() != b.ValueBool()
+	case types.String:
+		b, ok := b.(types.String)
+		return p, !ok || a.ValueString() != b.ValueString()
+	case types.List:
+		l, ok := listElements(b)
+		if !ok {
+			return p, true
+		}
+		return c.elementsDiff(p, a.Elements(), l)
+	case types.Tuple:
+		l, ok := listElements(b)
+		if !ok {
+			return p, true
+		}
+		return c.elementsDiff(p, a.Elements(), l)
+	case types.Set:
+		b, ok := b.(types.Set)
+		return p, !ok || !c.setElementsEqual(a.Elements(), b.Elements())
+	case types.Map:
+		m, ok := mapElements(b)
+		if !ok {
+			return p, true
+		}
+		return c.attributesDiff(p, a.Elements(), m, path.Path.AtMapKey)
+	case types.Object:
+		m, ok := mapElements(b)
+		if !ok {
+			return p, true
+		}
+		return c.attributesDiff(p, a.Attributes(), m, path.Path.AtName)
+	default:
+		return p, !a.Equal(b)
+	}
+}
+
+// underlyingValue returns the underlying value of a known, non-null dynamic value, or the value itself otherwise.
+func underlyingValue(v attr.Value) attr.Value {
+	if dv, ok := v.(types.Dynamic); ok && !dv.IsNull() && !dv.IsUnknown() {
+		return dv.UnderlyingValue()
+	}
+	return v
+}
+
+func numberValue(v attr.Value) (*big.Float, bool) {
+	switch v := v.(type) {
+	case types.Int64:
+		return new(big.Float).SetInt64(v.ValueInt64()), true
+	case types.Float64:
+		return new(big.Float).SetFloat64(v.ValueFloat64()), true
+	case types.Number:
+		return v.ValueBigFloat(), true
+	default:
+		return nil, false
+	}
+}
+
+// listElements returns the elements of the ordered collection types (list and tuple).
+func listElements(v attr.Value) ([]attr.Value, bool) {
+	switch v := v.(type) {
+	case types.List:
+		return v.Elements(), true
+	case types.Tuple:
+		return v.Elements(), true
+	default:
+		return nil, false
+	}
+}
+
+// mapElements returns the elements of the keyed collection types (map and object).
+func mapElements(v attr.Value) (map[string]attr.Value, bool) {
+	switch v := v.(type) {
+	case types.Map:
+		return v.Elements(), true
+	case types.Object:
+		return v.Attributes(), true
+	default:
+		return nil, false
+	}
+}
+
+func (c valueComparer) elementsDiff(p path.Path, a, b []attr.Value) (path.Path, bool) {
+	for i := 0; i < min(len(a), len(b)); i++ {
+		if dp, diff := c.firstDiff(p.AtListIndex(i), a[i], b[i]); diff {
+			return dp, true
+		}
+	}
+	if len(a) != len(b) {
+		return p.AtListIndex(min(len(a), len(b))), true
+	}
+	return p, false
+}
+
+func (c valueComparer) equal(a, b attr.Value) bool {
+	_, diff := c.firstDiff(path.Empty(), a, b)
+	return !diff
+}
+
+func (c valueComparer) setElementsEqual(a, b []attr.Value) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	matched := make([]bool, len(b))
+	for _, av := range a {
+		found := false
+		for i, bv := range b {
+			if !matched[i] && c.equal(av, bv) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (c valueComparer) attributesDiff(p path.Path, a, b map[string]attr.Value, step func(path.Path, string) path.Path) (path.Path, bool) {
+	keys := slices.Collect(maps.Keys(a))
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		av, aok := a[k]
+		bv, bok := b[k]
+		if aok != bok {
+			return step(p, k), true
+		}
+		if dp, diff := c.firstDiff(step(p, k), av, bv); diff {
+			return dp, true
+		}
+	}
+	return p, false
+}
@@ -0,0 +1,179 @@
+package dynamic
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFirstDiff(t *testing.T) {
+	objType := map[string]attr.Type{
+		"a": types.NumberType,
+		"b": types.ListType{ElemType: types.StringType},
+	}
+	obj := func(a float64, b ...string) types.Dynamic {
+		var elems []attr.Value
+		for _, e := range b {
+			elems = append(elems, types.StringValue(e))
+		}
+		return types.DynamicValue(types.ObjectValueMust(objType, map[string]attr.Value{
+			"a": types.NumberValue(big.NewFloat(a)),
+			"b": types.ListValueMust(types.StringType, elems),
+		}))
+	}
+
+	cases := []struct {
+		name string
+		a    types.Dynamic
+		b    types.Dynamic
+		path path.Path
+		diff bool
+	}{
+		{
+			name: "equal",
+			a:    obj(1, "x", "y"),
+			b:    obj(1, "x", "y"),
+			diff: false,
+		},
+		{
+			name: "numbers are compared by value across types",
+			a:    types.DynamicValue(types.Int64Value(1)),
+			b:    types.DynamicValue(types.NumberValue(big.NewFloat(1))),
+			diff: false,
+		},
+		{
+			name: "differ at attribute",
+			a:    obj(1, "x", "y"),
+			b:    obj(2, "x", "y"),
+			path: path.Root("a"),
+			diff: true,
+		},
+		{
+			name: "differ at list element",
+			a:    obj(1, "x", "y"),
+			b:    obj(1, "x", "z"),
+			path: path.Root("b").AtListIndex(1),
+			diff: true,
+		},
+		{
+			name: "differ at list length",
+			a:    obj(1, "x"),
+			b:    obj(1, "x", "y"),
+			path: path.Root("b").AtListIndex(1),
+			diff: true,
+		},
+		{
+			name: "differ at missing attribute",
+			a: types.DynamicValue(types.ObjectValueMust(
+				map[string]attr.Type{"a": types.BoolType},
+				map[string]attr.Value{"a": types.BoolValue(true)},
+			)),
+			b: types.DynamicValue(types.ObjectValueMust(
+				map[string]attr.Type{"a": types.BoolType, "c": types.BoolType},
+				map[string]attr.Value{"a": types.BoolValue(true), "c": types.BoolValue(true)},
+			)),
+			path: path.Root("c"),
+			diff: true,
+		},
+		{
+			name: "both null",
+			a:    types.DynamicNull(),
+			b:    types.DynamicNull(),
+			diff: false,
+		},
+		{
+			name: "one side null",
+			a:    types.DynamicNull(),
+			b:    obj(1),
+			path: path.Empty(),
+			diff: true,
+		},
+		{
+			name: "unknown",
+			a:    types.DynamicUnknown(),
+			b:    types.DynamicUnknown(),
+			path: path.Empty(),
+			diff: true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			p, diff := FirstDiff(tt.a, tt.b)
+			require.Equal(t, tt.diff, diff)
+			if tt.diff {
+				require.Equal(t, tt.path, p)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	obj := func(attrs map[string]attr.Value) types.Dynamic {
+		attrTypes := map[string]attr.Type{}
+		for k, v := range attrs {
+			attrTypes[k] = v.Type(nil)
+		}
+		return types.DynamicValue(types.ObjectValueMust(attrTypes, attrs))
+	}
+
+	cases := []struct {
+		name  string
+		a     types.Dynamic
+		b     types.Dynamic
+		opts  EqualOptions
+		equal bool
+	}{
+		{
+			name:  "key order and number types",
+			a:     obj(map[string]attr.Value{"a": types.Int64Value(1), "b": types.StringValue("x")}),
+			b:     obj(map[string]attr.Value{"b": types.StringValue("x"), "a": types.NumberValue(big.NewFloat(1.0))}),
+			equal: true,
+		},
+		{
+			name:  "different numbers",
+			a:     types.DynamicValue(types.Float64Value(1)),
+			b:     types.DynamicValue(types.Float64Value(1.5)),
+			equal: false,
+		},
+		{
+			name:  "nulls",
+			a:     types.DynamicNull(),
+			b:     types.DynamicValue(types.StringNull()),
+			equal: true,
+		},
+		{
+			name:  "unknowns",
+			a:     types.DynamicUnknown(),
+			b:     types.DynamicUnknown(),
+			equal: false,
+		},
+		{
+			name:  "unknowns equal",
+			a:     obj(map[string]attr.Value{"a": types.StringUnknown()}),
+			b:     obj(map[string]attr.Value{"a": types.StringUnknown()}),
+			opts:  EqualOptions{UnknownsEqual: true},
+			equal: true,
+		},
+		{
+			name:  "unknown and known",
+			a:     types.DynamicUnknown(),
+			b:     types.DynamicValue(types.StringValue("x")),
+			opts:  EqualOptions{UnknownsEqual: true},
+			equal: false,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.equal, EqualOpts(tt.a, tt.b, tt.opts))
+			require.Equal(t, tt.equal, EqualOpts(tt.b, tt.a, tt.opts))
+			if tt.opts == (EqualOptions{}) {
+				require.Equal(t, tt.equal, Equal(tt.a, tt.b))
+			}
+		})
+	}
+}
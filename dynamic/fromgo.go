@@ -0,0 +1,24 @@
+package dynamic
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// FromGo converts the Go value to dynamic types, e.g. to build the dynamic values in tests without constructing
+// the tftypes values by hand. The value is marshaled to JSON (by encoding/json), then converted by FromJSONImplied,
+// so that map[string]interface{} (and structs) become objects, []interface{} become tuples, and the scalars become
+// the implied types. A nil value results into a null dynamic value.
+// The values that can't be marshaled to JSON, e.g. channels and functions, result into an error.
+func FromGo(v interface{}) (types.Dynamic, error) {
+	if v == nil {
+		return types.DynamicNull(), nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return types.Dynamic{}, fmt.Errorf("marshaling the Go value: %v", err)
+	}
+	return FromJSONImplied(b)
+}
@@ -0,0 +1,74 @@
+package dynamic
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromGo(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  interface{}
+		expect types.Dynamic
+		err    bool
+	}{
+		{
+			name:   "nil",
+			input:  nil,
+			expect: types.DynamicNull(),
+		},
+		{
+			name:   "scalar",
+			input:  "x",
+			expect: types.DynamicValue(types.StringValue("x")),
+		},
+		{
+			name: "nested",
+			input: map[string]interface{}{
+				"a": []interface{}{1, true, nil},
+				"b": map[string]interface{}{"c": 1.5},
+			},
+			expect: types.DynamicValue(types.ObjectValueMust(
+				map[string]attr.Type{
+					"a": types.TupleType{ElemTypes: []attr.Type{types.NumberType, types.BoolType, types.DynamicType}},
+					"b": types.ObjectType{AttrTypes: map[string]attr.Type{"c": types.NumberType}},
+				},
+				map[string]attr.Value{
+					"a": types.TupleValueMust(
+						[]attr.Type{types.NumberType, types.BoolType, types.DynamicType},
+						[]attr.Value{types.NumberValue(big.NewFloat(1)), types.BoolValue(true), types.DynamicNull()},
+					),
+					"b": types.ObjectValueMust(
+						map[string]attr.Type{"c": types.NumberType},
+						map[string]attr.Value{"c": types.NumberValue(big.NewFloat(1.5))},
+					),
+				},
+			)),
+		},
+		{
+			name:  "channel",
+			input: map[string]interface{}{"a": make(chan int)},
+			err:   true,
+		},
+		{
+			name:  "function",
+			input: func() {},
+			err:   true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := FromGo(tt.input)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, tt.expect.Equal(d), d.String())
+		})
+	}
+}
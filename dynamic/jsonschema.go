@@ -0,0 +1,406 @@
+package dynamic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ValidateJSONSchema validates the dynamic value against the JSON Schema document, via its JSON representation
+// (see ToJSON). Every violation is reported as an attribute error diagnostic, at the path (relative to the
+// dynamic value) where it occurs. Object keys are represented as attribute names, array elements as list indexes.
+// A null or unknown dynamic value is not validated.
+//
+// The following subset of the JSON Schema keywords is supported, other keywords are ignored:
+//   - Generic: type, enum, const, allOf, anyOf, oneOf, not, $ref (local references only, e.g. "#/$defs/foo")
+//   - Object: properties, required, additionalProperties, minProperties, maxProperties
+//   - Array: items (a single schema), minItems, maxItems, uniqueItems
+//   - String: minLength, maxLength, pattern (Go regexp syntax)
+//   - Number: minimum, maximum, exclusiveMinimum, exclusiveMaximum (numbers only), multipleOf
+//
+// A recursive schema is supported, while a circular $ref that doesn't descend into the value (e.g. {"$ref": "#"})
+// is an invalid schema.
+func ValidateJSONSchema(d types.Dynamic, schema []byte) diag.Diagnostics {
+	return ValidateJSONSchemaAtPath(path.Empty(), d, schema)
+}
+
+// ValidateJSONSchemaAtPath is similar to ValidateJSONSchema, while the diagnostic paths are relative to p,
+// which is usually the path of the dynamic attribute.
+func ValidateJSONSchemaAtPath(p path.Path, d types.Dynamic, schema []byte) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if d.IsNull() || d.IsUnknown() {
+		return diags
+	}
+
+	root, err := decodeJSONNumber(schema)
+	if err != nil {
+		diags.AddError("Invalid JSON Schema", err.Error())
+		return diags
+	}
+	b, err := ToJSON(d)
+	if err != nil {
+		diags.AddAttributeError(p, "Failed to marshal the value to JSON", err.Error())
+		return diags
+	}
+	v, err := decodeJSONNumber(b)
+	if err != nil {
+		diags.AddAttributeError(p, "Failed to unmarshal the value", err.Error())
+		return diags
+	}
+
+	sv := &schemaValidator{root: root}
+	for _, violation := range sv.validate(p, root, v) {
+		diags.AddAttributeError(violation.path, "JSON Schema violation", violation.msg)
+	}
+	if sv.err != nil {
+		diags.AddError("Invalid JSON Schema", sv.err.Error())
+	}
+	return diags
+}
+
+func decodeJSONNumber(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+type schemaViolation struct {
+	path path.Path
+	msg  string
+}
+
+type schemaValidator struct {
+	root interface{}
+	// err records the first error of the schema itself.
+	err error
+	// resolving are the $refs being resolved, keyed by the value path, to detect the circular references, which
+	// would otherwise recurse infinitely. A recursive schema is fine as long as each recursion descends into the value.
+	resolving map[string]bool
+}
+
+func (sv *schemaValidator) schemaError(format string, a ...interface{}) []schemaViolation {
+	if sv.err == nil {
+		sv.err = fmt.Errorf(format, a...)
+	}
+	return nil
+}
+
+func (sv *schemaValidator) validate(p path.Path, schema, v interface{}) []schemaViolation {
+	switch schema := schema.(type) {
+	case bool:
+		if !schema {
+			return []schemaViolation{{p, "no value is allowed"}}
+		}
+		return nil
+	case map[string]interface{}:
+		return sv.validateObjectSchema(p, schema, v)
+	default:
+		return sv.schemaError("schema must be an object or a boolean, got %T", schema)
+	}
+}
+
+func (sv *schemaValidator) validateObjectSchema(p path.Path, schema map[string]interface{}, v interface{}) []schemaViolation {
+	var out []schemaViolation
+	fail := func(format string, a ...interface{}) {
+		out = append(out, schemaViolation{p, fmt.Sprintf(format, a...)})
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := sv.resolveRef(ref)
+		if err != nil {
+			return sv.schemaError("%v", err)
+		}
+		key := p.String() + "\x00" + ref
+		if sv.resolving[key] {
+			return sv.schemaError("circular $ref %q", ref)
+		}
+		if sv.resolving == nil {
+			sv.resolving = map[string]bool{}
+		}
+		sv.resolving[key] = true
+		out = append(out, sv.validate(p, target, v)...)
+		delete(sv.resolving, key)
+	}
+
+	if typ, ok := schema["type"]; ok {
+		var allowed []string
+		switch typ := typ.(type) {
+		case string:
+			allowed = []string{typ}
+		case []interface{}:
+			for _, t := range typ {
+				if t, ok := t.(string); ok {
+					allowed = append(allowed, t)
+				}
+			}
+		}
+		if !slices.ContainsFunc(allowed, func(t string) bool { return jsonTypeMatch(t, v) }) {
+			fail("expected type %s, got %s", strings.Join(allowed, " or "), jsonTypeOf(v))
+			// The other keywords are meaningless for a mismatched type.
+			return out
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		if !slices.ContainsFunc(enum, func(e interface{}) bool { return jsonEqual(e, v) }) {
+			fail("value must be one of the enum values")
+		}
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, v) {
+		fail("value must be equal to the const value")
+	}
+
+	if subs, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range subs {
+			out = append(out, sv.validate(p, sub, v)...)
+		}
+	}
+	if subs, ok := schema["anyOf"].([]interface{}); ok {
+		if !slices.ContainsFunc(subs, func(sub interface{}) bool { return len(sv.validate(p, sub, v)) == 0 }) {
+			fail("value must be valid against at least one of the anyOf schemas")
+		}
+	}
+	if subs, ok := schema["oneOf"].([]interface{}); ok {
+		var n int
+		for _, sub := range subs {
+			if len(sv.validate(p, sub, v)) == 0 {
+				n++
+			}
+		}
+		if n != 1 {
+			fail("value must be valid against exactly one of the oneOf schemas, but is valid against %d", n)
+		}
+	}
+	if sub, ok := schema["not"]; ok && len(sv.validate(p, sub, v)) == 0 {
+		fail("value must not be valid against the not schema")
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out = append(out, sv.validateObject(p, schema, v)...)
+	case []interface{}:
+		out = append(out, sv.validateArray(p, schema, v)...)
+	case string:
+		n := utf8.RuneCountInString(v)
+		if limit, ok := schemaNumber(schema, "minLength"); ok && big.NewRat(int64(n), 1).Cmp(limit) < 0 {
+			fail("string length must be at least %s", limit.RatString())
+		}
+		if limit, ok := schemaNumber(schema, "maxLength"); ok && big.NewRat(int64(n), 1).Cmp(limit) > 0 {
+			fail("string length must be at most %s", limit.RatString())
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return sv.schemaError("invalid pattern %q: %v", pattern, err)
+			}
+			if !re.MatchString(v) {
+				fail("string must match the pattern %q", pattern)
+			}
+		}
+	case json.Number:
+		n, ok := new(big.Rat).SetString(v.String())
+		if !ok {
+			fail("invalid number %s", v)
+			break
+		}
+		if limit, ok := schemaNumber(schema, "minimum"); ok && n.Cmp(limit) < 0 {
+			fail("value must be >= %s", limit.FloatString(6))
+		}
+		if limit, ok := schemaNumber(schema, "maximum"); ok && n.Cmp(limit) > 0 {
+			fail("value must be <= %s", limit.FloatString(6))
+		}
+		if limit, ok := schemaNumber(schema, "exclusiveMinimum"); ok && n.Cmp(limit) <= 0 {
+			fail("value must be > %s", limit.FloatString(6))
+		}
+		if limit, ok := schemaNumber(schema, "exclusiveMaximum"); ok && n.Cmp(limit) >= 0 {
+			fail("value must be < %s", limit.FloatString(6))
+		}
+		if m, ok := schemaNumber(schema, "multipleOf"); ok && m.Sign() > 0 {
+			if !new(big.Rat).Quo(n, m).IsInt() {
+				fail("value must be a multiple of %s", m.RatString())
+			}
+		}
+	}
+	return out
+}
+
+func (sv *schemaValidator) validateObject(p path.Path, schema map[string]interface{}, v map[string]interface{}) []schemaViolation {
+	var out []schemaViolation
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, r := range required {
+			if r, ok := r.(string); ok {
+				if _, ok := v[r]; !ok {
+					out = append(out, schemaViolation{p.AtName(r), "attribute is required"})
+				}
+			}
+		}
+	}
+	if limit, ok := schemaNumber(schema, "minProperties"); ok && big.NewRat(int64(len(v)), 1).Cmp(limit) < 0 {
+		out = append(out, schemaViolation{p, fmt.Sprintf("object must have at least %s attributes", limit.RatString())})
+	}
+	if limit, ok := schemaNumber(schema, "maxProperties"); ok && big.NewRat(int64(len(v)), 1).Cmp(limit) > 0 {
+		out = append(out, schemaViolation{p, fmt.Sprintf("object must have at most %s attributes", limit.RatString())})
+	}
+
+	props, _ := schema["properties"].(map[string]interface{})
+	additional, hasAdditional := schema["additionalProperties"]
+	keys := slices.Sorted(func(yield func(string) bool) {
+		for k := range v {
+			if !yield(k) {
+				return
+			}
+		}
+	})
+	for _, k := range keys {
+		if sub, ok := props[k]; ok {
+			out = append(out, sv.validate(p.AtName(k), sub, v[k])...)
+			continue
+		}
+		if !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok && !allowed {
+			out = append(out, schemaViolation{p.AtName(k), "additional attribute is not allowed"})
+			continue
+		}
+		out = append(out, sv.validate(p.AtName(k), additional, v[k])...)
+	}
+	return out
+}
+
+func (sv *schemaValidator) validateArray(p path.Path, schema map[string]interface{}, v []interface{}) []schemaViolation {
+	var out []schemaViolation
+	if limit, ok := schemaNumber(schema, "minItems"); ok && big.NewRat(int64(len(v)), 1).Cmp(limit) < 0 {
+		out = append(out, schemaViolation{p, fmt.Sprintf("array must have at least %s items", limit.RatString())})
+	}
+	if limit, ok := schemaNumber(schema, "maxItems"); ok && big.NewRat(int64(len(v)), 1).Cmp(limit) > 0 {
+		out = append(out, schemaViolation{p, fmt.Sprintf("array must have at most %s items", limit.RatString())})
+	}
+	if unique, ok := schema["uniqueItems"].(bool); ok && unique {
+	outer:
+		for i := range v {
+			for j := 0; j < i; j++ {
+				if jsonEqual(v[i], v[j]) {
+					out = append(out, schemaViolation{p.AtListIndex(i), fmt.Sprintf("array item duplicates the item at index %d", j)})
+					break outer
+				}
+			}
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		for i, e := range v {
+			out = append(out, sv.validate(p.AtListIndex(i), items, e)...)
+		}
+	}
+	return out
+}
+
+func (sv *schemaValidator) resolveRef(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references are supported", ref)
+	}
+	cur := sv.root
+	if ref == "#" {
+		return cur, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	for _, tk := range strings.Split(ref[2:], "/") {
+		tk = strings.NewReplacer("~1", "/", "~0", "~").Replace(tk)
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("$ref %q not found", ref)
+		}
+		if cur, ok = m[tk]; !ok {
+			return nil, fmt.Errorf("$ref %q not found", ref)
+		}
+	}
+	return cur, nil
+}
+
+func schemaNumber(schema map[string]interface{}, keyword string) (*big.Rat, bool) {
+	n, ok := schema[keyword].(json.Number)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Rat).SetString(n.String())
+}
+
+func jsonTypeOf(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if r, ok := new(big.Rat).SetString(v.String()); ok && r.IsInt() {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func jsonTypeMatch(typ string, v interface{}) bool {
+	actual := jsonTypeOf(v)
+	return typ == actual || (typ == "number" && actual == "integer")
+}
+
+func jsonEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		ar, aok := new(big.Rat).SetString(a.String())
+		br, bok := new(big.Rat).SetString(b.String())
+		return aok && bok && ar.Cmp(br) == 0
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, av := range a {
+			bv, ok := b[k]
+			if !ok || !jsonEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
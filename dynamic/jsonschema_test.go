@@ -0,0 +1,115 @@
+package dynamic
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateJSONSchema(t *testing.T) {
+	schema := `{
+	"type": "object",
+	"required": ["name", "size"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 3, "pattern": "^[a-z]+$"},
+		"size": {"type": "integer", "minimum": 1, "maximum": 10},
+		"kind": {"enum": ["small", "large"]},
+		"tags": {"type": "array", "uniqueItems": true, "items": {"$ref": "#/$defs/tag"}}
+	},
+	"$defs": {
+		"tag": {"type": "string", "maxLength": 3}
+	}
+}`
+
+	cases := []struct {
+		name   string
+		input  string
+		paths  []path.Path
+		schema string
+	}{
+		{
+			name:  "valid",
+			input: `{"name": "foo", "size": 3, "kind": "small", "tags": ["a", "b"]}`,
+		},
+		{
+			name:  "all violations are reported",
+			input: `{"name": "X", "size": 11, "kind": "medium", "tags": ["a", "long", "a"], "extra": 1}`,
+			paths: []path.Path{
+				path.Empty().AtName("extra"),
+				path.Empty().AtName("kind"),
+				// minLength and pattern
+				path.Empty().AtName("name"),
+				path.Empty().AtName("name"),
+				path.Empty().AtName("size"),
+				path.Empty().AtName("tags").AtListIndex(2),
+				path.Empty().AtName("tags").AtListIndex(1),
+			},
+		},
+		{
+			name:  "missing required and wrong type",
+			input: `{"name": 1}`,
+			paths: []path.Path{
+				path.Empty().AtName("size"),
+				path.Empty().AtName("name"),
+			},
+		},
+		{
+			name:   "invalid schema",
+			input:  `{}`,
+			schema: `{"type": "object",`,
+			paths:  []path.Path{path.Empty()},
+		},
+		{
+			name:   "self referencing schema",
+			input:  `{}`,
+			schema: `{"$ref": "#"}`,
+			paths:  []path.Path{path.Empty()},
+		},
+		{
+			name:   "mutually referencing definitions",
+			input:  `{"a": 1}`,
+			schema: `{"properties": {"a": {"$ref": "#/$defs/x"}}, "$defs": {"x": {"$ref": "#/$defs/y"}, "y": {"allOf": [{"$ref": "#/$defs/x"}]}}}`,
+			paths:  []path.Path{path.Empty()},
+		},
+		{
+			name:   "recursive schema descending into the value",
+			input:  `{"name": "a", "children": [{"name": "b", "children": [{"name": 1}]}]}`,
+			schema: `{"type": "object", "properties": {"name": {"type": "string"}, "children": {"type": "array", "items": {"$ref": "#"}}}}`,
+			paths:  []path.Path{path.Root("children").AtListIndex(0).AtName("children").AtListIndex(0).AtName("name")},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := FromJSONImplied([]byte(tt.input))
+			require.NoError(t, err)
+			s := schema
+			if tt.schema != "" {
+				s = tt.schema
+			}
+			diags := ValidateJSONSchema(d, []byte(s))
+			var paths []path.Path
+			for _, d := range diags {
+				if d, ok := d.(interface{ Path() path.Path }); ok {
+					paths = append(paths, d.Path())
+					continue
+				}
+				paths = append(paths, path.Empty())
+			}
+			require.Equal(t, tt.paths, paths, diags)
+		})
+	}
+}
+
+func TestValidateJSONSchemaAtPath(t *testing.T) {
+	d := types.DynamicValue(types.StringValue("foo"))
+	diags := ValidateJSONSchemaAtPath(path.Root("body"), d, []byte(`{"type": "number"}`))
+	require.Len(t, diags, 1)
+	require.Equal(t, path.Root("body"), diags[0].(interface{ Path() path.Path }).Path())
+	require.Contains(t, diags[0].Detail(), "expected type number, got string")
+
+	require.False(t, ValidateJSONSchema(types.DynamicNull(), []byte(`false`)).HasError())
+}
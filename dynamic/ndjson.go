@@ -0,0 +1,47 @@
+package dynamic
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// ToNDJSON writes the elements of the top level list, set or tuple as newline delimited JSON to w, i.e. each
+// element is written as a separate line of JSON (the same as ToJSON), terminated by "\n". This is useful for the
+// bulk APIs. It errors if the dynamic value is not a known list, set or tuple.
+func ToNDJSON(d types.Dynamic, w io.Writer) error {
+	if d.IsNull() || d.IsUnknown() {
+		return fmt.Errorf("NDJSON requires a known, non-null value")
+	}
+	v := d.UnderlyingValue()
+	if v.IsNull() || v.IsUnknown() {
+		return fmt.Errorf("NDJSON requires a known, non-null value")
+	}
+	var elems []attr.Value
+	switch v := v.(type) {
+	case types.List:
+		elems = v.Elements()
+	case types.Set:
+		elems = v.Elements()
+	case types.Tuple:
+		elems = v.Elements()
+	default:
+		return fmt.Errorf("NDJSON requires a list, set or tuple, got %T", v)
+	}
+
+	e := newJSONEncoder(Options{})
+	defer e.release()
+	for _, elem := range elems {
+		if err := e.encode(elem, nil); err != nil {
+			return err
+		}
+		e.buf = append(e.buf, '\n')
+		if _, err := w.Write(e.buf); err != nil {
+			return err
+		}
+		e.buf = e.buf[:0]
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package dynamic
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToNDJSON(t *testing.T) {
+	tuple, err := FromJSONImplied([]byte(`[{"a": 1, "b": [true]}, {"a": 1.5}, null, "x"]`))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		input  types.Dynamic
+		output string
+		err    bool
+	}{
+		{
+			name:   "tuple",
+			input:  tuple,
+			output: "{\"a\":1,\"b\":[true]}\n{\"a\":1.5}\nnull\n\"x\"\n",
+		},
+		{
+			name:   "list",
+			input:  types.DynamicValue(types.ListValueMust(types.Int64Type, []attr.Value{types.Int64Value(1), types.Int64Value(9007199254740993)})),
+			output: "1\n9007199254740993\n",
+		},
+		{
+			name:   "empty list",
+			input:  types.DynamicValue(types.ListValueMust(types.Int64Type, nil)),
+			output: "",
+		},
+		{
+			name:  "object",
+			input: types.DynamicValue(types.ObjectValueMust(map[string]attr.Type{}, map[string]attr.Value{})),
+			err:   true,
+		},
+		{
+			name:  "null",
+			input: types.DynamicNull(),
+			err:   true,
+		},
+		{
+			name:  "null list",
+			input: types.DynamicValue(types.ListNull(types.StringType)),
+			err:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := ToNDJSON(tt.input, &buf)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.output, buf.String())
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package dynamic
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+)
+
+// Normalize converts the dynamic value to the canonical JSON (see jsonset.Normalize), so that the same logical
+// value is always converted to the same bytes, e.g. for hashing, regardless of how the value is built: the object
+// keys are sorted, and the numbers are in the canonical form regardless of their types (e.g. the int64 1 and the
+// float64 1.0 are both "1"). A null or unknown value results into nil.
+func Normalize(d types.Dynamic) ([]byte, error) {
+	b, err := ToJSON(d)
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return jsonset.Normalize(b)
+}
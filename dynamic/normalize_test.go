@@ -0,0 +1,44 @@
+package dynamic
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	a := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{"z": types.Int64Type, "a": types.StringType, "m": types.MapType{ElemType: types.Float64Type}},
+		map[string]attr.Value{
+			"z": types.Int64Value(1),
+			"a": types.StringValue("<x>"),
+			"m": types.MapValueMust(types.Float64Type, map[string]attr.Value{"k2": types.Float64Value(1e21), "k1": types.Float64Value(0.5)}),
+		},
+	))
+	b := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{"m": types.ObjectType{AttrTypes: map[string]attr.Type{"k1": types.NumberType, "k2": types.NumberType}}, "a": types.StringType, "z": types.NumberType},
+		map[string]attr.Value{
+			"m": types.ObjectValueMust(
+				map[string]attr.Type{"k1": types.NumberType, "k2": types.NumberType},
+				map[string]attr.Value{"k1": types.NumberValue(big.NewFloat(0.5)), "k2": types.NumberValue(big.NewFloat(1e21))},
+			),
+			"a": types.StringValue("<x>"),
+			"z": types.NumberValue(big.NewFloat(1.0)),
+		},
+	))
+
+	na, err := Normalize(a)
+	require.NoError(t, err)
+	nb, err := Normalize(b)
+	require.NoError(t, err)
+	require.Equal(t, `{"a":"<x>","m":{"k1":0.5,"k2":1e+21},"z":1}`, string(na))
+	require.Equal(t, sha256.Sum256(na), sha256.Sum256(nb))
+
+	n, err := Normalize(types.DynamicNull())
+	require.NoError(t, err)
+	require.Nil(t, n)
+}
@@ -0,0 +1,138 @@
+package dynamic
+
+// StringNullPolicy controls how null and empty strings are converted to JSON.
+type StringNullPolicy int
+
+const (
+	// StringNullAsIs keeps the null string as null, and the empty string as "".
+	StringNullAsIs StringNullPolicy = iota
+	// StringNullAsEmpty converts the null string to "".
+	StringNullAsEmpty
+	// StringEmptyAsNull converts the empty string to null.
+	StringEmptyAsNull
+)
+
+// DefaultMaxDepth is the default nesting depth limit of ToJSON, which matches the limit of encoding/json.
+const DefaultMaxDepth = 10000
+
+// Options tunes the conversions between JSON and the dynamic types.
+// The zero value matches the behavior of the option-less functions.
+type Options struct {
+	// InternStrings deduplicates identical string values when building the
+	// dynamic value from JSON, so that repeated values share one backing string.
+	InternStrings bool
+
+	// DisallowUnknownKeys makes FromJSONWithType error if the JSON has object keys that are not defined by the
+	// target object types, similar to json.Decoder.DisallowUnknownFields. All the unknown keys are listed in the
+	// error with their paths. The keys beneath a dynamic type are not checked.
+	DisallowUnknownKeys bool
+
+	// DisallowUnknownValues makes ToJSON error on the unknown values, rather than returning nil for an unknown value,
+	// and emitting the nested unknown values as null, e.g. for the flows that must not send a partially known value.
+	// The error wraps ErrUnknownValue (which can be tested via errors.Is), naming the attribute path of the first
+	// unknown value. The subtrees dropped by DropUnknownSubtrees are not regarded as errors.
+	DisallowUnknownValues bool
+
+	// OmitDefaults omits the object attributes (and map elements) whose value equals the default value
+	// declared in the schema passed to ToJSONWithSchema. The values are compared semantically,
+	// e.g. numbers are compared by value regardless of their types.
+	// Attributes without a declared default, or absent from the schema, are always emitted.
+	// A null attribute is only omitted if its default is also null.
+	OmitDefaults bool
+
+	// OmitNulls omits the object attributes (and map elements) whose value is emitted as null, recursively, e.g. for
+	// the APIs rejecting the explicit null fields. The StringNullPolicy is applied before, so that the empty strings
+	// turned into null by StringEmptyAsNull are omitted as well. The unknown values are not omitted (see
+	// DropUnknownSubtrees), and the null array elements are kept to preserve the positions. An object that becomes
+	// empty after the omission is still emitted, as {}.
+	OmitNulls bool
+
+	// StringNullPolicy controls how the null and empty string typed values are converted to JSON.
+	// It only applies to the values that are typed as string, e.g. a null dynamic value is always null.
+	// The policy is applied when the value is emitted, i.e. the OmitDefaults compares the original value.
+	StringNullPolicy StringNullPolicy
+
+	// StringNullPolicyPaths limits the StringNullPolicy to the string attributes at these attribute paths.
+	// If empty, the policy applies to all the string typed values.
+	StringNullPolicyPaths []string
+
+	// FieldMask limits the output to the attributes at these attribute paths (including their whole subtrees),
+	// together with their ancestors, mirroring the semantics of Google's FieldMask (e.g. for the PATCH requests
+	// that require a body aligned with the update mask). The attributes outside the mask are omitted.
+	// If empty, all the attributes are emitted.
+	FieldMask []string
+
+	// StrictFieldMask makes ToJSON error if any path in the FieldMask doesn't exist in the value.
+	StrictFieldMask bool
+
+	// FieldMaskAttribute, if not empty, adds a top level attribute of this name to the output, whose value is
+	// the comma separated FieldMask (e.g. "updateMask": "a.b,c"). The value must be an object or a map,
+	// and mustn't have an attribute of the same name.
+	FieldMaskAttribute string
+
+	// SortArrayPaths are the attribute paths of the arrays (list, set and tuple) that are semantically sets, whose
+	// elements are sorted before emitted, to make the output deterministic (e.g. for hashing).
+	// Scalar elements are ordered by their JSON values: null first, then false, true, numbers (by value), and strings
+	// (by the byte-wise order). An array having any object or array element is left alone, unless SortObjectArrays
+	// is set.
+	SortArrayPaths []string
+
+	// SortObjectArrays makes the arrays at SortArrayPaths having object or array elements sorted as well,
+	// by the byte-wise order of the emitted JSON of the elements (whose object keys are sorted).
+	SortObjectArrays bool
+
+	// MaxDepth limits the nesting depth of the containers (i.e. list, set, tuple, map and object) that ToJSON walks,
+	// to guard against the pathological values (e.g. built by a buggy custom type). Exceeding the limit results into
+	// an error wrapping ErrMaxDepthExceeded. Zero means DefaultMaxDepth.
+	MaxDepth int
+
+	// MaxArrayLen limits the element count of every array (i.e. list, set and tuple) that ToJSON emits, to guard
+	// against serializing a huge array of a user influenced value. Exceeding the limit results into an error wrapping
+	// ErrMaxArrayLenExceeded, naming the path of the array. Zero means unlimited.
+	MaxArrayLen int
+
+	// EnumMaps translates the string values during the conversions, keyed by the attribute paths, e.g.
+	// {"status": {"active": "ACTIVE"}} makes ToJSON emit "ACTIVE" for the "status" attribute of "active".
+	// FromJSONOpts and FromJSONWithType translate the values back via the inverted maps, which must be one-to-one.
+	// The unmapped values pass through unchanged, unless StrictEnumMaps is set.
+	EnumMaps map[string]map[string]string
+
+	// StrictEnumMaps makes the conversions error on the unmapped string values at the EnumMaps paths.
+	StrictEnumMaps bool
+
+	// DropUnknownSubtrees makes ToJSON omit every object attribute (and map element) whose value is not fully known
+	// (see IsFullyKnown), i.e. the whole subtree is dropped once any of its descendants is unknown, instead of
+	// emitting the unknown values as null. The output then only represents the settled part of the value, e.g.
+	// during plan. As the array elements are not omitted individually, an array with any unknown element is
+	// dropped as a whole by its enclosing attribute. If the top level value itself is a list, set or tuple that is
+	// not fully known, there is nothing settled to emit, and nil is returned as for an unknown value.
+	DropUnknownSubtrees bool
+
+	// PreferMap makes FromJSONOpts convert every JSON object whose values are all of the same type into a map,
+	// rather than an object, to match the map typed schema attributes. The heterogeneous objects (including those
+	// mixing null and non-null values, as null is converted as a dynamic null) and the empty objects fall back to
+	// objects.
+	PreferMap bool
+
+	// MapPaths limits the map conversion of PreferMap to the objects at these attribute paths, which applies even
+	// if PreferMap is not set. The same fallback to objects applies.
+	MapPaths []string
+
+	// KeyCollator is the "less" function ordering the object (and map) keys emitted by ToJSON, which applies
+	// recursively, e.g. KeyPriority("id") emits "id" first. The keys that are not ordered by it (i.e. neither is
+	// less than the other) are emitted in the sorted order, so that the output stays deterministic as long as the
+	// KeyCollator is (it must be a strict weak ordering). As the arrays of SortObjectArrays are sorted by their
+	// emitted JSON, their order is also affected by the KeyCollator. The default emits the keys in the sorted order.
+	KeyCollator func(a, b string) bool
+
+	// TimeZone controls the time zone of the TimestampType values emitted by ToJSON. The default keeps the
+	// timestamps as they are.
+	TimeZone TimeZone
+
+	// Indent, if not empty, makes ToJSON emit the indented JSON, with every nesting level indented by one more Indent
+	// (e.g. "  "), and one object member or array element per line, e.g. for the golden files and the human readable
+	// diagnostics. The indentation is applied on the otherwise compact output, so that it is as deterministic as the
+	// compact one, and doesn't affect the key order (see KeyCollator) or the array sorting (see SortArrayPaths).
+	// The default emits the compact JSON, which is meant for hashing and byte-wise comparisons.
+	Indent string
+}
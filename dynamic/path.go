@@ -0,0 +1,39 @@
+package dynamic
+
+import "strings"
+
+// attrPattern is a parsed attribute path in the Options.
+//
+// Attribute paths are the dot separated object attribute names (or map keys), e.g. "properties.tags".
+// Elements of list, set and tuple are addressed through their parent, i.e. "rules.name" refers to the "name"
+// of every element of "rules". A "*" segment matches any single attribute name.
+type attrPattern []string
+
+func parseAttrPatterns(paths []string) []attrPattern {
+	var patterns []attrPattern
+	for _, p := range paths {
+		patterns = append(patterns, strings.Split(p, "."))
+	}
+	return patterns
+}
+
+func (p attrPattern) match(path []string) bool {
+	if len(p) != len(path) {
+		return false
+	}
+	for i := range p {
+		if p[i] != "*" && p[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAnyAttr(patterns []attrPattern, path []string) bool {
+	for _, p := range patterns {
+		if p.match(path) {
+			return true
+		}
+	}
+	return false
+}
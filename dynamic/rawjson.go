@@ -0,0 +1,100 @@
+package dynamic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable  = RawJSONStringType{}
+	_ basetypes.StringValuable = RawJSONString{}
+)
+
+// RawJSONStringType is a custom string type for the strings holding already serialized JSON.
+// Its JSON representation (via ToJSON) is the inner JSON spliced as is (compacted), rather than a quoted string.
+// The inner JSON is validated when it is serialized. Conversely, FromJSON takes the JSON value as the string.
+type RawJSONStringType struct {
+	basetypes.StringType
+}
+
+func (t RawJSONStringType) Equal(o attr.Type) bool {
+	other, ok := o.(RawJSONStringType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t RawJSONStringType) String() string {
+	return "dynamic.RawJSONStringType"
+}
+
+func (t RawJSONStringType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return RawJSONString{StringValue: in}, nil
+}
+
+func (t RawJSONStringType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return RawJSONString{StringValue: stringValue}, nil
+}
+
+func (t RawJSONStringType) ValueType(_ context.Context) attr.Value {
+	return RawJSONString{}
+}
+
+// RawJSONString is the value of the RawJSONStringType.
+type RawJSONString struct {
+	basetypes.StringValue
+}
+
+// NewRawJSONStringValue returns a known RawJSONString. The JSON is not validated until it is serialized.
+func NewRawJSONStringValue(s string) RawJSONString {
+	return RawJSONString{StringValue: basetypes.NewStringValue(s)}
+}
+
+// NewRawJSONStringNull returns a null RawJSONString.
+func NewRawJSONStringNull() RawJSONString {
+	return RawJSONString{StringValue: basetypes.NewStringNull()}
+}
+
+// NewRawJSONStringUnknown returns an unknown RawJSONString.
+func NewRawJSONStringUnknown() RawJSONString {
+	return RawJSONString{StringValue: basetypes.NewStringUnknown()}
+}
+
+func (v RawJSONString) Equal(o attr.Value) bool {
+	other, ok := o.(RawJSONString)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v RawJSONString) Type(_ context.Context) attr.Type {
+	return RawJSONStringType{}
+}
+
+// ValueRawJSON returns the compacted inner JSON of the known value.
+func (v RawJSONString) ValueRawJSON() (json.RawMessage, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(v.ValueString())); err != nil {
+		diags.AddError("Invalid raw JSON string", err.Error())
+		return nil, diags
+	}
+	return buf.Bytes(), diags
+}
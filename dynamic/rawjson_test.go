@@ -0,0 +1,65 @@
+package dynamic
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRawJSONString(t *testing.T) {
+	attrTypes := map[string]attr.Type{
+		"name":   types.StringType,
+		"config": RawJSONStringType{},
+	}
+	obj := func(config RawJSONString) types.Dynamic {
+		return types.DynamicValue(types.ObjectValueMust(attrTypes, map[string]attr.Value{
+			"name":   types.StringValue("foo"),
+			"config": config,
+		}))
+	}
+
+	cases := []struct {
+		name   string
+		input  types.Dynamic
+		expect string
+		err    bool
+	}{
+		{
+			name:   "object is spliced",
+			input:  obj(NewRawJSONStringValue(`{ "a": [1, 2], "b": "<x>" }`)),
+			expect: `{"config":{"a":[1,2],"b":"\u003cx\u003e"},"name":"foo"}`,
+		},
+		{
+			name:   "primitive is spliced",
+			input:  obj(NewRawJSONStringValue(` true `)),
+			expect: `{"config":true,"name":"foo"}`,
+		},
+		{
+			name:   "null",
+			input:  obj(NewRawJSONStringNull()),
+			expect: `{"config":null,"name":"foo"}`,
+		},
+		{
+			name:  "invalid",
+			input: obj(NewRawJSONStringValue(`{"a":`)),
+			err:   true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ToJSON(tt.input)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, string(b))
+		})
+	}
+
+	d, err := FromJSON([]byte(`{"name": "foo", "config": {"a": [1, 2]}}`), types.ObjectType{AttrTypes: attrTypes})
+	require.NoError(t, err)
+	require.True(t, d.Equal(obj(NewRawJSONStringValue(`{"a":[1,2]}`))))
+}
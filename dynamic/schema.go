@@ -0,0 +1,29 @@
+package dynamic
+
+import "github.com/hashicorp/terraform-plugin-framework/attr"
+
+// Schema describes the schema information of a dynamic value, which is not carried by the value itself.
+type Schema struct {
+	// Default is the default value of the attribute, if any.
+	Default attr.Value
+
+	// Attributes describes the attributes of an object, or the elements of a map keyed by the map key.
+	Attributes map[string]*Schema
+
+	// Element describes the elements of a list, set or tuple.
+	Element *Schema
+}
+
+func (s *Schema) attribute(name string) *Schema {
+	if s == nil {
+		return nil
+	}
+	return s.Attributes[name]
+}
+
+func (s *Schema) element() *Schema {
+	if s == nil {
+		return nil
+	}
+	return s.Element
+}
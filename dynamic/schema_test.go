@@ -0,0 +1,64 @@
+package dynamic
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToJSONWithSchemaOmitDefaults(t *testing.T) {
+	input := types.DynamicValue(
+		types.ObjectValueMust(
+			map[string]attr.Type{
+				"sku":      types.StringType,
+				"replicas": types.NumberType,
+				"enabled":  types.BoolType,
+				"nested": types.ObjectType{
+					AttrTypes: map[string]attr.Type{
+						"tier": types.StringType,
+						"size": types.Int64Type,
+					},
+				},
+			},
+			map[string]attr.Value{
+				"sku":      types.StringValue("Standard"),
+				"replicas": types.NumberValue(big.NewFloat(1)),
+				"enabled":  types.BoolValue(true),
+				"nested": types.ObjectValueMust(
+					map[string]attr.Type{
+						"tier": types.StringType,
+						"size": types.Int64Type,
+					},
+					map[string]attr.Value{
+						"tier": types.StringValue("Hot"),
+						"size": types.Int64Value(10),
+					},
+				),
+			},
+		),
+	)
+
+	schema := &Schema{
+		Attributes: map[string]*Schema{
+			"sku":      {Default: types.StringValue("Basic")},
+			"replicas": {Default: types.Int64Value(1)},
+			"enabled":  {},
+			"nested": {
+				Attributes: map[string]*Schema{
+					"tier": {Default: types.StringValue("Hot")},
+				},
+			},
+		},
+	}
+
+	b, err := ToJSONWithSchema(input, schema, Options{OmitDefaults: true})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"sku": "Standard", "enabled": true, "nested": {"size": 10}}`, string(b))
+
+	b, err = ToJSONWithSchema(input, schema, Options{})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"sku": "Standard", "replicas": 1, "enabled": true, "nested": {"tier": "Hot", "size": 10}}`, string(b))
+}
@@ -0,0 +1,108 @@
+package dynamic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	_ basetypes.StringTypable  = TimestampType{}
+	_ basetypes.StringValuable = Timestamp{}
+)
+
+// TimeZone controls the time zone of the timestamps emitted by ToJSON.
+type TimeZone int
+
+const (
+	// TimeZoneOriginal emits the timestamps as they are, keeping their original offsets.
+	TimeZoneOriginal TimeZone = iota
+	// TimeZoneUTC converts the timestamps to UTC, emitted with the "Z" suffix.
+	TimeZoneUTC
+)
+
+// TimestampType is a custom string type for the RFC 3339 timestamps (e.g. "2024-01-02T03:04:05Z", or
+// "2024-01-02T11:04:05+08:00"). Its JSON representation is the same string, whose time zone is controlled by
+// Options.TimeZone. Both the UTC and the offset forms are accepted by FromJSON.
+type TimestampType struct {
+	basetypes.StringType
+}
+
+func (t TimestampType) Equal(o attr.Type) bool {
+	other, ok := o.(TimestampType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t TimestampType) String() string {
+	return "dynamic.TimestampType"
+}
+
+func (t TimestampType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return Timestamp{StringValue: in}, nil
+}
+
+func (t TimestampType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	return Timestamp{StringValue: stringValue}, nil
+}
+
+func (t TimestampType) ValueType(_ context.Context) attr.Value {
+	return Timestamp{}
+}
+
+// Timestamp is the value of the TimestampType.
+type Timestamp struct {
+	basetypes.StringValue
+}
+
+// NewTimestampValue returns a known Timestamp, formatted in RFC 3339 with its own offset.
+func NewTimestampValue(t time.Time) Timestamp {
+	return Timestamp{StringValue: basetypes.NewStringValue(t.Format(time.RFC3339Nano))}
+}
+
+// NewTimestampNull returns a null Timestamp.
+func NewTimestampNull() Timestamp {
+	return Timestamp{StringValue: basetypes.NewStringNull()}
+}
+
+// NewTimestampUnknown returns an unknown Timestamp.
+func NewTimestampUnknown() Timestamp {
+	return Timestamp{StringValue: basetypes.NewStringUnknown()}
+}
+
+func (v Timestamp) Equal(o attr.Value) bool {
+	other, ok := o.(Timestamp)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v Timestamp) Type(_ context.Context) attr.Type {
+	return TimestampType{}
+}
+
+// ValueTime parses the RFC 3339 timestamp of the known value.
+func (v Timestamp) ValueTime() (time.Time, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	t, err := time.Parse(time.RFC3339Nano, v.ValueString())
+	if err != nil {
+		diags.AddError("Invalid timestamp", err.Error())
+	}
+	return t, diags
+}
@@ -0,0 +1,78 @@
+package dynamic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestamp(t *testing.T) {
+	attrTypes := map[string]attr.Type{
+		"at": TimestampType{},
+	}
+	obj := func(at Timestamp) types.Dynamic {
+		return types.DynamicValue(types.ObjectValueMust(attrTypes, map[string]attr.Value{
+			"at": at,
+		}))
+	}
+	offset := time.Date(2024, 1, 2, 11, 4, 5, 500000000, time.FixedZone("", 8*3600))
+
+	cases := []struct {
+		name   string
+		input  types.Dynamic
+		tz     TimeZone
+		expect string
+		err    bool
+	}{
+		{
+			name:   "original offset",
+			input:  obj(NewTimestampValue(offset)),
+			expect: `{"at":"2024-01-02T11:04:05.5+08:00"}`,
+		},
+		{
+			name:   "converted to UTC",
+			input:  obj(NewTimestampValue(offset)),
+			tz:     TimeZoneUTC,
+			expect: `{"at":"2024-01-02T03:04:05.5Z"}`,
+		},
+		{
+			name:   "UTC kept",
+			input:  obj(Timestamp{StringValue: types.StringValue("2024-01-02T03:04:05Z")}),
+			tz:     TimeZoneUTC,
+			expect: `{"at":"2024-01-02T03:04:05Z"}`,
+		},
+		{
+			name:   "null",
+			input:  obj(NewTimestampNull()),
+			tz:     TimeZoneUTC,
+			expect: `{"at":null}`,
+		},
+		{
+			name:  "invalid",
+			input: obj(Timestamp{StringValue: types.StringValue("yesterday")}),
+			err:   true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ToJSONOpts(tt.input, Options{TimeZone: tt.tz})
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, string(b))
+		})
+	}
+
+	for _, in := range []string{"2024-01-02T03:04:05Z", "2024-01-02T11:04:05+08:00"} {
+		d, err := FromJSON([]byte(`{"at": "`+in+`"}`), types.ObjectType{AttrTypes: attrTypes})
+		require.NoError(t, err)
+		require.True(t, d.Equal(obj(Timestamp{StringValue: types.StringValue(in)})))
+	}
+	_, err := FromJSON([]byte(`{"at": "2024-01-02"}`), types.ObjectType{AttrTypes: attrTypes})
+	require.Error(t, err)
+}
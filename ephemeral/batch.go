@@ -0,0 +1,112 @@
+package ephemeral
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// Batch is a PrivateData that accumulates the writes to the underlying PrivateData in memory, and flushes them
+// together on Commit, e.g. to reduce the round-trips when several keys (e.g. the chunks) are written at once:
+//
+//	b := ephemeral.NewBatch(d)
+//	diags.Append(ephemeral.SetWithOptions(ctx, b, body, ephemeral.Options{ChunkSize: 1024})...)
+//	diags.Append(ephemeral.Register(ctx, b, "ephemeral_body")...)
+//	diags.Append(b.Commit(ctx)...)
+//
+// Reads see the pending writes. A Batch is not safe for concurrent use.
+type Batch struct {
+	d PrivateData
+
+	pending map[string][]byte
+	// order is the order of the keys being written first.
+	order []string
+	// originals are the values of the keys read from the underlying PrivateData, which Commit restores on failure.
+	originals map[string][]byte
+}
+
+var _ PrivateData = &Batch{}
+
+// NewBatch returns a Batch writing to d.
+func NewBatch(d PrivateData) *Batch {
+	return &Batch{d: d, pending: map[string][]byte{}, originals: map[string][]byte{}}
+}
+
+func (b *Batch) GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics) {
+	if v, ok := b.pending[key]; ok {
+		return slices.Clone(v), nil
+	}
+	v, diags := b.d.GetKey(ctx, key)
+	if !diags.HasError() {
+		b.originals[key] = slices.Clone(v)
+	}
+	return v, diags
+}
+
+// SetKey records the write, which is only flushed to the underlying PrivateData on Commit.
+// Setting a key to a nil or zero-length value removes the key on Commit.
+func (b *Batch) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	if _, ok := b.pending[key]; !ok {
+		b.order = append(b.order, key)
+	}
+	if len(value) == 0 {
+		value = nil
+	}
+	b.pending[key] = slices.Clone(value)
+	return nil
+}
+
+// Commit flushes the pending writes to the underlying PrivateData, in the order of the keys being written first.
+// It is all-or-nothing as far as the underlying PrivateData allows: if any write fails, the keys written so far
+// are restored to their original values (on a best effort basis), and the error diagnostics are returned.
+// The batch is emptied afterwards, regardless of the result.
+//
+// To be able to restore them, the original values of the keys are read before being written, unless they have
+// already been read through the Batch (e.g. by Set, which reads the existing record), or it is the last key to write,
+// which is never restored.
+func (b *Batch) Commit(ctx context.Context) diag.Diagnostics {
+	defer b.Rollback()
+
+	type original struct {
+		key   string
+		value []byte
+	}
+	var (
+		written []original
+		diags   diag.Diagnostics
+	)
+	for i, key := range b.order {
+		orig, ok := b.originals[key]
+		if !ok && i < len(b.order)-1 {
+			var odiags diag.Diagnostics
+			orig, odiags = b.d.GetKey(ctx, key)
+			diags.Append(odiags...)
+		}
+		if !diags.HasError() {
+			diags.Append(b.d.SetKey(ctx, key, b.pending[key])...)
+		}
+		if diags.HasError() {
+			for _, w := range slices.Backward(written) {
+				if rdiags := b.d.SetKey(ctx, w.key, w.value); rdiags.HasError() {
+					diags.AddError(
+						`Failed to roll back the batch`,
+						fmt.Sprintf(`Restoring the private state key %q failed, the private state might be partially written`, w.key),
+					)
+					diags.Append(rdiags...)
+				}
+			}
+			return diags
+		}
+		written = append(written, original{key: key, value: orig})
+	}
+	return diags
+}
+
+// Rollback discards the pending writes.
+func (b *Batch) Rollback() {
+	clear(b.pending)
+	clear(b.originals)
+	b.order = nil
+}
@@ -0,0 +1,93 @@
+package ephemeral_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+// failingPrivateData fails the writes to the keys in fail, and counts the reads and writes.
+type failingPrivateData struct {
+	*ephemeral.MemoryPrivateData
+	fail   map[string]bool
+	reads  int
+	writes int
+}
+
+func (d *failingPrivateData) GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics) {
+	d.reads++
+	return d.MemoryPrivateData.GetKey(ctx, key)
+}
+
+func (d *failingPrivateData) SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics {
+	if d.fail[key] {
+		var diags diag.Diagnostics
+		diags.AddError("write failed", key)
+		return diags
+	}
+	d.writes++
+	return d.MemoryPrivateData.SetKey(ctx, key, value)
+}
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+	body := mustToJSON(t, objectBody(map[string]string{"password": "foo"}))
+
+	d := &failingPrivateData{MemoryPrivateData: ephemeral.NewMemoryPrivateData()}
+	b := ephemeral.NewBatch(d)
+	require.False(t, ephemeral.SetWithOptions(ctx, b, body, ephemeral.Options{ChunkSize: 8}).HasError())
+	require.Zero(t, d.writes)
+
+	// Reads see the pending writes.
+	changed, diags := ephemeral.Diff(ctx, b, objectBody(map[string]string{"password": "foo"}))
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	exists, diags := ephemeral.Exists(ctx, d)
+	require.False(t, diags.HasError())
+	require.False(t, exists)
+
+	require.False(t, b.Commit(ctx).HasError())
+	nb, diags := ephemeral.GetNullBody(ctx, d)
+	require.False(t, diags.HasError())
+	require.JSONEq(t, `{"password": null}`, string(nb))
+
+	// Rollback discards the pending writes.
+	writes := d.writes
+	require.False(t, ephemeral.Set(ctx, b, nil).HasError())
+	b.Rollback()
+	require.False(t, b.Commit(ctx).HasError())
+	require.Equal(t, writes, d.writes)
+	exists, diags = ephemeral.Exists(ctx, d)
+	require.False(t, diags.HasError())
+	require.True(t, exists)
+}
+
+func TestBatchCommitFailure(t *testing.T) {
+	ctx := context.Background()
+	d := &failingPrivateData{MemoryPrivateData: ephemeral.NewMemoryPrivateData(), fail: map[string]bool{"c": true}}
+	require.False(t, d.SetKey(ctx, "a", []byte(`"orig"`)).HasError())
+
+	b := ephemeral.NewBatch(d)
+	v, diags := b.GetKey(ctx, "a")
+	require.False(t, diags.HasError())
+	require.Equal(t, `"orig"`, string(v))
+	require.False(t, b.SetKey(ctx, "a", []byte(`"new"`)).HasError())
+	require.False(t, b.SetKey(ctx, "b", []byte(`"new"`)).HasError())
+	require.False(t, b.SetKey(ctx, "c", []byte(`"new"`)).HasError())
+
+	// Only the original of "b" is read, as "a" has been read through the batch, and "c" is the last key.
+	reads := d.reads
+	require.True(t, b.Commit(ctx).HasError())
+	require.Equal(t, reads+1, d.reads)
+
+	// The written keys are restored.
+	v, diags = d.GetKey(ctx, "a")
+	require.False(t, diags.HasError())
+	require.Equal(t, `"orig"`, string(v))
+	v, diags = d.GetKey(ctx, "b")
+	require.False(t, diags.HasError())
+	require.Nil(t, v)
+}
@@ -0,0 +1,50 @@
+package ephemeral
+
+import (
+	"context"
+	"sync"
+)
+
+type diffCacheKey struct{}
+
+// diffCache caches the hashes of the ephemeral bodies, keyed by the body content.
+type diffCache struct {
+	mu     sync.Mutex
+	hashes map[diffCacheEntry][]byte
+}
+
+type diffCacheEntry struct {
+	algorithm string
+	salt      string
+	body      string
+	normalize bool
+}
+
+// WithDiffCache returns a context that enables caching the hash calculated by Diff, within the scope of the
+// context (e.g. a single Terraform operation). A following Diff with the identical ephemeral body reuses the
+// cached hash, instead of hashing the body again.
+// The cache is keyed on the body content, so different bodies never share a hash.
+func WithDiffCache(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(diffCacheKey{}).(*diffCache); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, diffCacheKey{}, &diffCache{hashes: map[diffCacheEntry][]byte{}})
+}
+
+// cachedHashOf is similar to hashOf, while it reuses the hash cached in the context, if enabled.
+func cachedHashOf(ctx context.Context, h hasher, salt, ebody []byte, normalize bool) []byte {
+	c, ok := ctx.Value(diffCacheKey{}).(*diffCache)
+	if !ok {
+		return hashOf(h, salt, ebody, normalize)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k := diffCacheEntry{algorithm: h.name, salt: string(salt), body: string(ebody), normalize: normalize}
+	if hash, ok := c.hashes[k]; ok {
+		return hash
+	}
+	hash := hashOf(h, salt, ebody, normalize)
+	c.hashes[k] = hash
+	return hash
+}
@@ -0,0 +1,78 @@
+package ephemeral
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// DiffResult is the result of DiffDetailed.
+type DiffResult struct {
+	// Changed is the same as what Diff returns.
+	Changed bool
+
+	// PrevHash is the hex encoded hash recorded in the private state, which is empty if no record exists.
+	PrevHash string
+
+	// CurHash is the hex encoded hash of the incoming ephemeral body, which is empty if the ephemeral body
+	// is null or unknown.
+	CurHash string
+}
+
+// DiffDetailed is similar to Diff, while it also returns the hashes of both the recorded and the incoming
+// ephemeral body, which can be logged (e.g. in the audit logs) to correlate the changes across runs.
+// Only the fingerprints are returned, the ephemeral body itself is never included.
+func DiffDetailed(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (DiffResult, diag.Diagnostics) {
+	return defaultStore.DiffDetailed(ctx, d, ephemeralBody)
+}
+
+// DiffDetailed is similar to Diff, while it also returns the hashes. See the package level DiffDetailed for details.
+func (s *Store) DiffDetailed(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (DiffResult, diag.Diagnostics) {
+	changed, diags := s.Diff(ctx, d, ephemeralBody)
+	if diags.HasError() {
+		return DiffResult{}, diags
+	}
+	res := DiffResult{Changed: changed}
+
+	rec, odiags := getRecord(ctx, d, s.key)
+	diags.Append(odiags...)
+	if diags.HasError() {
+		return DiffResult{}, diags
+	}
+	var contentType string
+	normalize := true
+	h := s.hasher
+	var salt []byte
+	if rec != nil {
+		salt = rec.HashSalt
+		res.PrevHash = hex.EncodeToString(rec.Hash)
+		contentType = rec.ContentType
+		normalize = rec.normalizesHash()
+	}
+
+	if ephemeralBody.IsNull() || ephemeralBody.IsUnknown() {
+		return res, diags
+	}
+	if rec != nil {
+		var err error
+		if h, err = s.recordHasher(rec); err != nil {
+			diags.AddError(
+				`Unsupported ephemeral body private data`,
+				err.Error(),
+			)
+			return DiffResult{}, diags
+		}
+	}
+	ebody, err := marshalBody(ephemeralBody, contentType)
+	if err != nil {
+		diags.AddError(
+			`Error to marshal the ephemeral body`,
+			err.Error(),
+		)
+		return DiffResult{}, diags
+	}
+	res.CurHash = hex.EncodeToString(cachedHashOf(ctx, h, salt, ebody, normalize))
+	return res, diags
+}
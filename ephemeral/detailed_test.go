@@ -0,0 +1,64 @@
+package ephemeral_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffDetailed(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	foo := objectBody(map[string]string{"password": "foo"})
+	bar := objectBody(map[string]string{"password": "bar"})
+	hashOf := func(body types.Dynamic) string {
+		sum := sha256.Sum256(mustToJSON(t, body))
+		return hex.EncodeToString(sum[:])
+	}
+
+	res, diags := ephemeral.DiffDetailed(ctx, d, foo)
+	require.False(t, diags.HasError())
+	require.Equal(t, ephemeral.DiffResult{Changed: true, CurHash: hashOf(foo)}, res)
+
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, foo)).HasError())
+
+	cases := []struct {
+		name   string
+		body   types.Dynamic
+		result ephemeral.DiffResult
+	}{
+		{
+			name:   "unchanged",
+			body:   foo,
+			result: ephemeral.DiffResult{Changed: false, PrevHash: hashOf(foo), CurHash: hashOf(foo)},
+		},
+		{
+			name:   "changed",
+			body:   bar,
+			result: ephemeral.DiffResult{Changed: true, PrevHash: hashOf(foo), CurHash: hashOf(bar)},
+		},
+		{
+			name:   "null",
+			body:   types.DynamicNull(),
+			result: ephemeral.DiffResult{Changed: true, PrevHash: hashOf(foo)},
+		},
+		{
+			name:   "unknown",
+			body:   types.DynamicUnknown(),
+			result: ephemeral.DiffResult{Changed: true, PrevHash: hashOf(foo)},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, diags := ephemeral.DiffDetailed(ctx, d, tt.body)
+			require.False(t, diags.HasError())
+			require.Equal(t, tt.result, res)
+		})
+	}
+}
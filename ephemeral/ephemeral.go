@@ -1,10 +1,14 @@
 package ephemeral
 
 import (
+	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/json"
+	"crypto/rand"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -16,13 +20,27 @@ const (
 	pkEphemeralBody = "ephemeral_body"
 )
 
+const (
+	// ContentTypeJSON is the default content type of the ephemeral body.
+	ContentTypeJSON = "application/json"
+)
+
+func isJSONContentType(contentType string) bool {
+	return contentType == "" || contentType == ContentTypeJSON || strings.HasSuffix(contentType, "+json")
+}
+
 type PrivateData interface {
 	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
 	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
 }
 
 func Exists(ctx context.Context, d PrivateData) (bool, diag.Diagnostics) {
-	b, diags := d.GetKey(ctx, pkEphemeralBody)
+	return defaultStore.Exists(ctx, d)
+}
+
+// Exists tells whether the ephemeral body record exists in the private state.
+func (s *Store) Exists(ctx context.Context, d PrivateData) (bool, diag.Diagnostics) {
+	b, diags := d.GetKey(ctx, s.key)
 	if diags.HasError() {
 		return false, diags
 	}
@@ -30,23 +48,128 @@ func Exists(ctx context.Context, d PrivateData) (bool, diag.Diagnostics) {
 }
 
 // Set sets the hash of the ephemeral body to the private state.
-// If `ebody` is nil, it removes the hash from the private state.
+// If `ebody` is nil or empty, it removes the hash from the private state, see Clear.
+// The JSON ephemeral body is hashed on its canonical form (the same as dynamic.Normalize), so that the logically
+// identical bodies differing in the key order, the whitespace or the number representation have the same hash.
 func Set(ctx context.Context, d PrivateData, ebody []byte) (diags diag.Diagnostics) {
-	if ebody == nil {
-		d.SetKey(ctx, pkEphemeralBody, nil)
-		return
+	return SetWithOptions(ctx, d, ebody, Options{})
+}
+
+// Clear removes the ephemeral body record (including its chunks) from the private state, e.g. in the Delete handler.
+// Clearing a nonexistent record is not an error.
+func Clear(ctx context.Context, d PrivateData) diag.Diagnostics {
+	return defaultStore.Clear(ctx, d)
+}
+
+// Clear removes the ephemeral body record from the private state. See the package level Clear for details.
+func (s *Store) Clear(ctx context.Context, d PrivateData) diag.Diagnostics {
+	return s.Set(ctx, d, nil)
+}
+
+// SetWithContentType is similar to Set, while it also records the content type of the ephemeral body.
+// See Options.ContentType for details.
+func SetWithContentType(ctx context.Context, d PrivateData, ebody []byte, contentType string) (diags diag.Diagnostics) {
+	return SetWithOptions(ctx, d, ebody, Options{ContentType: contentType})
+}
+
+// Options tunes the behavior of SetWithOptions.
+type Options struct {
+	// ContentType is the content type of the ephemeral body. An empty content type is regarded as ContentTypeJSON.
+	// For a non-JSON content type (e.g. a YAML document held by a dynamic string), the hash is calculated on the
+	// raw bytes of the body, and no nullified body is recorded. Diff then hashes the raw string value of the
+	// dynamic string accordingly.
+	ContentType string
+
+	// ChunkSize is the maximum size of the nullified body stored in a single private state key.
+	// A larger nullified body is split into chunks stored at the keys "ephemeral_body.0", "ephemeral_body.1", etc.,
+	// while the record stores the chunk count. GetNullBody reassembles the chunks transparently.
+	// Zero means no chunking.
+	ChunkSize int
+
+	// VerifyWrite re-reads every private state key after it is written, and reports an error diagnostic if the
+	// value read back doesn't match the value written. This catches the backends that fail to persist silently,
+	// at the cost of an extra read per key.
+	VerifyWrite bool
+
+	// TrackPaths records the salted hash of every leaf value of the JSON ephemeral body, keyed by its path, so that
+	// DiffPaths can tell which paths changed. The leaves are the non-object values, i.e. an array is a single leaf.
+	// Note that the hash of a low entropy leaf (e.g. a bool) can be guessed by whom can read the private state,
+	// so only enable it for the ephemeral bodies whose leaves are worth to be patched individually.
+	TrackPaths bool
+
+	// SaltHash salts the hash of the ephemeral body with a random salt stored in the record, so that the hash of a low
+	// entropy ephemeral body (e.g. a short password) can't be looked up in a precomputed dictionary by whom can read
+	// the private state. Diff detects the salt from the record, i.e. the records written with and without the salt
+	// are compared transparently. Note that a salted hash can't be matched by MatchesHash, and that the salt only
+	// defeats the precomputed dictionaries, rather than a brute-force against a single record.
+	SaltHash bool
+}
+
+// SetWithOptions is similar to Set, with the behavior tuned by opts.
+func SetWithOptions(ctx context.Context, d PrivateData, ebody []byte, opts Options) (diags diag.Diagnostics) {
+	return defaultStore.SetWithOptions(ctx, d, ebody, opts)
+}
+
+// Set sets the hash of the ephemeral body to the private state. See the package level Set for details.
+func (s *Store) Set(ctx context.Context, d PrivateData, ebody []byte) diag.Diagnostics {
+	return s.SetWithOptions(ctx, d, ebody, Options{})
+}
+
+// SetWithOptions is similar to Set, with the behavior tuned by opts.
+func (s *Store) SetWithOptions(ctx context.Context, d PrivateData, ebody []byte, opts Options) (diags diag.Diagnostics) {
+	if opts.VerifyWrite {
+		d = verifyingPrivateData{d}
 	}
 
-	// Calculate the hash of the ephemeral body
-	h := sha256.New()
-	if _, err := h.Write(ebody); err != nil {
-		diags.AddError(
-			`Error to hash the ephemeral body`,
-			err.Error(),
-		)
-		return
+	// Chunks written previously are removed, unless they are overwritten below.
+	staleChunks := storedChunks(ctx, d, s.key)
+
+	// An empty ebody (e.g. []byte{} rather than nil) is not a valid body of any content type, regard it as a removal.
+	if len(ebody) == 0 {
+		diags.Append(d.SetKey(ctx, s.key, nil)...)
+		if diags.HasError() {
+			return diags
+		}
+		return removeChunks(ctx, d, s.key, 0, staleChunks)
+	}
+
+	now := s.now().UTC()
+	rec := record{
+		Version:   recordVersion,
+		WrittenAt: &now,
+	}
+	if opts.SaltHash {
+		rec.HashSalt = make([]byte, 16)
+		rand.Read(rec.HashSalt)
+	}
+	rec.Hash = hashOf(s.hasher, rec.HashSalt, ebody, isJSONContentType(opts.ContentType))
+	if s.hasher.name != DefaultHashAlgorithm {
+		rec.Algorithm = s.hasher.name
+	}
+
+	if !isJSONContentType(opts.ContentType) {
+		rec.ContentType = opts.ContentType
+		rec.MAC = s.mac(rec, nil)
+		diags.Append(setRecord(ctx, d, s.key, rec)...)
+		if diags.HasError() {
+			return diags
+		}
+		return append(diags, removeChunks(ctx, d, s.key, 0, staleChunks)...)
+	}
+
+	if opts.TrackPaths {
+		rec.Salt = make([]byte, 16)
+		rand.Read(rec.Salt)
+		var err error
+		rec.Paths, err = leafHashes(s.hasher, ebody, rec.Salt)
+		if err != nil {
+			diags.AddError(
+				`Error to hash the paths of the ephemeral body`,
+				err.Error(),
+			)
+			return
+		}
 	}
-	hash := h.Sum(nil)
 
 	// Nullify ephemeral body
 	nb, err := jsonset.NullifyObject(ebody)
@@ -58,63 +181,163 @@ func Set(ctx context.Context, d PrivateData, ebody []byte) (diags diag.Diagnosti
 		return
 	}
 
-	b, err := json.Marshal(map[string]interface{}{
-		// []byte will be marshaled to base64 encoded string
-		"hash": hash,
-		"null": nb,
-	})
-	if err != nil {
-		diags.AddError(
-			`Error to marshal the ephemeral body private data`,
-			err.Error(),
-		)
-		return
+	if s.encrypted() {
+		nb, rec.Nonce, err = s.seal(nb)
+		if err != nil {
+			diags.AddError(
+				`Error to encrypt the nullified ephemeral body`,
+				err.Error(),
+			)
+			return
+		}
+	}
+
+	if opts.ChunkSize <= 0 || len(nb) <= opts.ChunkSize {
+		rec.Null = nb
+	} else {
+		chunks := slices.Collect(slices.Chunk(nb, opts.ChunkSize))
+		for i, chunk := range chunks {
+			diags.Append(setChunk(ctx, d, s.key, i, chunk)...)
+			if diags.HasError() {
+				return diags
+			}
+		}
+		rec.Chunks = len(chunks)
 	}
+	rec.MAC = s.mac(rec, nb)
 
-	return d.SetKey(ctx, pkEphemeralBody, b)
+	diags.Append(setRecord(ctx, d, s.key, rec)...)
+	if diags.HasError() {
+		return diags
+	}
+	return append(diags, removeChunks(ctx, d, s.key, rec.Chunks, staleChunks)...)
+}
+
+// DiffOpts tunes the behavior of DiffWithOptions.
+type DiffOpts struct {
+	// Grace is the window since the record is written, within which a changed ephemeral body is regarded as
+	// unchanged. This debounces the ephemeral bodies that legitimately differ on consecutive runs.
+	// Only the changes of the ephemeral body's content are debounced, setting or removing the whole
+	// ephemeral body is always regarded as a change. Records written without a timestamp, or with a timestamp in
+	// the future, are never debounced.
+	Grace time.Duration
+
+	// Now returns the current time, defaults to the clock of the Store (see Store.WithClock).
+	Now func() time.Time
+
+	// RecoverFromCorruption makes a corrupt record (e.g. truncated by an interrupted Set) regarded as absent,
+	// with a warning rather than an error, so that the resource self-heals by re-establishing the record on the
+	// next apply, instead of failing every operation.
+	RecoverFromCorruption bool
+}
+
+func (opts DiffOpts) now() time.Time {
+	if opts.Now != nil {
+		return opts.Now()
+	}
+	return time.Now()
 }
 
 // Diff tells whether the ephemeral body is different than the hash stored in the private state.
 // In case private state doesn't have the record, regard the record as "nil" (i.e. will return true if ebody is non-nil).
 // In case private state has the record (guaranteed to be non-nil), while ebody is nil, it also returns true.
 func Diff(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (bool, diag.Diagnostics) {
+	return defaultStore.Diff(ctx, d, ephemeralBody)
+}
+
+// DiffWithOptions is similar to Diff, with the behavior tuned by opts.
+func DiffWithOptions(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic, opts DiffOpts) (bool, diag.Diagnostics) {
+	return defaultStore.DiffWithOptions(ctx, d, ephemeralBody, opts)
+}
+
+// Diff tells whether the ephemeral body is different than the hash stored in the private state.
+// See the package level Diff for details.
+func (s *Store) Diff(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (bool, diag.Diagnostics) {
+	return s.DiffWithOptions(ctx, d, ephemeralBody, DiffOpts{})
+}
+
+// DiffWithOptions is similar to Diff, with the behavior tuned by opts.
+func (s *Store) DiffWithOptions(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic, opts DiffOpts) (bool, diag.Diagnostics) {
 	if ephemeralBody.IsUnknown() {
 		return true, nil
 	}
+	marshal := func(contentType string) ([]byte, error) {
+		return marshalBody(ephemeralBody, contentType)
+	}
+	return s.diff(ctx, d, ephemeralBody.IsNull(), marshal, opts)
+}
+
+// DiffMarshaled is similar to Diff, while it takes the ephemeral body that is already marshaled by the caller
+// (e.g. by a plan modifier that validates the same bytes), to avoid marshaling it again. wasNull tells whether
+// the original ephemeral body is null, in which case eb is ignored. An unknown ephemeral body is always regarded
+// as changed, which needn't call this function.
+//
+// For the hash to be consistent with Set, eb must be produced by dynamic.ToJSON, or for a non-JSON content type
+// (see Options.ContentType), be the raw string value of the dynamic string.
+func DiffMarshaled(ctx context.Context, d PrivateData, eb []byte, wasNull bool) (bool, diag.Diagnostics) {
+	return defaultStore.DiffMarshaled(ctx, d, eb, wasNull)
+}
+
+// DiffMarshaled is similar to Diff, while it takes the marshaled ephemeral body.
+// See the package level DiffMarshaled for details.
+func (s *Store) DiffMarshaled(ctx context.Context, d PrivateData, eb []byte, wasNull bool) (bool, diag.Diagnostics) {
+	marshal := func(string) ([]byte, error) {
+		return eb, nil
+	}
+	return s.diff(ctx, d, wasNull, marshal, DiffOpts{})
+}
 
-	b, diags := d.GetKey(ctx, pkEphemeralBody)
+// diff tells whether the ephemeral body is different than the hash stored in the private state.
+// The known ephemeral body is marshaled by marshal on demand, according to the content type recorded.
+func (s *Store) diff(ctx context.Context, d PrivateData, isNull bool, marshal func(contentType string) ([]byte, error), opts DiffOpts) (bool, diag.Diagnostics) {
+	if opts.Now == nil {
+		opts.Now = s.now
+	}
+	rec, diags := getRecordRecovering(ctx, d, s.key, opts.RecoverFromCorruption)
 	if diags.HasError() {
 		return false, diags
 	}
-	if b == nil {
+	if rec == nil {
 		// In case private state doesn't store the key yet, it only diffs when the ebody is not nil.
-		return !ephemeralBody.IsNull(), diags
+		return !isNull, diags
 	}
 
-	if ephemeralBody.IsNull() {
+	authentic, odiags := s.verifyMAC(ctx, d, rec)
+	diags.Append(odiags...)
+	if diags.HasError() {
+		return false, diags
+	}
+	if !authentic {
 		return true, diags
 	}
 
-	// Calc the hash in the private data
-	var mm map[string]interface{}
-	if err := json.Unmarshal(b, &mm); err != nil {
+	if isNull {
+		return true, diags
+	}
+
+	if s.expired(rec, opts.now()) {
+		return true, diags
+	}
+
+	if rec.Hash == nil {
 		diags.AddError(
-			`Error to unmarshal the ephemeral body private data`,
-			err.Error(),
+			`Invalid ephemeral body private data`,
+			`Key "hash" not found`,
 		)
 		return false, diags
 	}
-	privateHashEnc, ok := mm["hash"]
-	if !ok {
+
+	h, err := s.recordHasher(rec)
+	if err != nil {
 		diags.AddError(
-			`Invalid ephemeral body private data`,
-			`Key "hash" not found`,
+			`Unsupported ephemeral body private data`,
+			err.Error(),
 		)
 		return false, diags
 	}
 
 	// Calc the hash of the ebody
-	ebody, err := dynamic.ToJSON(ephemeralBody)
+	ebody, err := marshal(rec.ContentType)
 	if err != nil {
 		diags.AddError(
 			`Error to marshal the ephemeral body`,
@@ -122,59 +345,244 @@ func Diff(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (bool
 		)
 		return false, diags
 	}
-	h := sha256.New()
-	if _, err := h.Write(ebody); err != nil {
+
+	if bytes.Equal(cachedHashOf(ctx, h, rec.HashSalt, ebody, rec.normalizesHash()), rec.Hash) {
+		return false, diags
+	}
+	if opts.Grace > 0 && rec.WrittenAt != nil {
+		// A record written in the future (e.g. clock skew, or edited) is regarded as out of the window.
+		if elapsed := opts.now().Sub(*rec.WrittenAt); elapsed >= 0 && elapsed < opts.Grace {
+			return false, diags
+		}
+	}
+	return true, diags
+}
+
+// marshalBody marshals the known, non-null ephemeral body to bytes for hashing, according to the content type.
+func marshalBody(ephemeralBody types.Dynamic, contentType string) ([]byte, error) {
+	if !isJSONContentType(contentType) {
+		if s, ok := ephemeralBody.UnderlyingValue().(types.String); ok {
+			return []byte(s.ValueString()), nil
+		}
+	}
+	return dynamic.ToJSON(ephemeralBody)
+}
+
+// ChangeKind is a coarse classification of the change of the ephemeral body.
+type ChangeKind int
+
+const (
+	// NoChange means the ephemeral body is the same as the one recorded in the private state.
+	NoChange ChangeKind = iota
+	// ValueChange means only the values of the ephemeral body changed, while its structure remains the same.
+	ValueChange
+	// StructuralChange means the structure of the ephemeral body changed, e.g. keys are added or removed.
+	StructuralChange
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case NoChange:
+		return "NoChange"
+	case ValueChange:
+		return "ValueChange"
+	case StructuralChange:
+		return "StructuralChange"
+	default:
+		return "Unknown"
+	}
+}
+
+// DiffKind is similar to Diff, while it also classifies the change by comparing the nullified ephemeral
+// body stored in the private state against the nullified incoming ephemeral body.
+// A warning is added to the diagnostics in case of a StructuralChange.
+// Setting or removing the whole ephemeral body is regarded as a StructuralChange.
+func DiffKind(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (ChangeKind, diag.Diagnostics) {
+	return defaultStore.DiffKind(ctx, d, ephemeralBody)
+}
+
+// DiffKind is similar to Diff, while it also classifies the change. See the package level DiffKind for details.
+func (s *Store) DiffKind(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (ChangeKind, diag.Diagnostics) {
+	changed, diags := s.Diff(ctx, d, ephemeralBody)
+	if diags.HasError() || !changed {
+		return NoChange, diags
+	}
+
+	if ephemeralBody.IsUnknown() {
+		// The ephemeral body is not known yet, we can't tell how it changes.
+		return ValueChange, diags
+	}
+
+	rec, odiags := getRecord(ctx, d, s.key)
+	diags.Append(odiags...)
+	if diags.HasError() {
+		return NoChange, diags
+	}
+	if rec != nil && !isJSONContentType(rec.ContentType) && !ephemeralBody.IsNull() {
+		// Non-JSON ephemeral body has no structure recorded.
+		return ValueChange, diags
+	}
+
+	var nb []byte
+	if rec != nil {
+		nb, odiags = s.nullBodyOf(ctx, d, rec)
+		diags.Append(odiags...)
+		if diags.HasError() {
+			return NoChange, diags
+		}
+	}
+	structural, err := structuralChanged(nb, ephemeralBody)
+	if err != nil {
 		diags.AddError(
-			`Error to hash ephemeral body`,
+			`Error to compare the structure of the ephemeral body`,
 			err.Error(),
 		)
-		return false, diags
+		return NoChange, diags
+	}
+	if !structural {
+		return ValueChange, diags
 	}
-	hash := h.Sum(nil)
-	hashEnc := base64.StdEncoding.EncodeToString(hash)
 
-	return hashEnc != privateHashEnc.(string), diags
+	diags.AddWarning(
+		`The structure of the ephemeral body changed`,
+		`Keys of the ephemeral body are added or removed, compared to the one previously applied.`,
+	)
+	return StructuralChange, diags
+}
+
+func structuralChanged(nullBody []byte, ephemeralBody types.Dynamic) (bool, error) {
+	if nullBody == nil || ephemeralBody.IsNull() {
+		return true, nil
+	}
+	ebody, err := dynamic.ToJSON(ephemeralBody)
+	if err != nil {
+		return false, err
+	}
+	nb, err := jsonset.NullifyObject(ebody)
+	if err != nil {
+		return false, err
+	}
+	equal, err := jsonset.Equal(nullBody, nb)
+	if err != nil {
+		return false, err
+	}
+	return !equal, nil
 }
 
 // GetNullBody gets the nullified ephemeral body from the private data.
-// If it doesn't exist, nil is returned.
+// If it doesn't exist (including the case of a non-JSON ephemeral body), nil is returned.
 func GetNullBody(ctx context.Context, d PrivateData) ([]byte, diag.Diagnostics) {
-	b, diags := d.GetKey(ctx, pkEphemeralBody)
+	return defaultStore.GetNullBody(ctx, d)
+}
+
+// GetNullBody gets the (decrypted) nullified ephemeral body from the private data.
+// If it doesn't exist (including the case of a non-JSON ephemeral body), nil is returned.
+func (s *Store) GetNullBody(ctx context.Context, d PrivateData) ([]byte, diag.Diagnostics) {
+	rec, diags := getRecord(ctx, d, s.key)
 	if diags.HasError() {
 		return nil, diags
 	}
-	if b == nil {
+	if rec == nil {
 		return nil, nil
 	}
+	return s.nullBodyOf(ctx, d, rec)
+}
+
+// MergeNullBody deep merges the nullified ephemeral body (see GetNullBody) into the JSON object body, e.g. in Read
+// to combine the API response with the ephemeral body paths, to compute the body of the state. The body is returned
+// as is if no nullified body exists (e.g. no record, or of a non-JSON ephemeral body).
+//
+// The body is expected to be disjointed with the nullified body. Otherwise (e.g. the API echoes the secrets back),
+// the nullified body takes precedence at the conflicting paths, so that no secret is leaked into the state, with
+// a warning listing those paths. It errors if the body is not a JSON object.
+func MergeNullBody(ctx context.Context, d PrivateData, body []byte) ([]byte, diag.Diagnostics) {
+	return defaultStore.MergeNullBody(ctx, d, body)
+}
 
-	var mm map[string]interface{}
-	if err := json.Unmarshal(b, &mm); err != nil {
+// MergeNullBody deep merges the nullified ephemeral body into the body. See the package level MergeNullBody for
+// details.
+func (s *Store) MergeNullBody(ctx context.Context, d PrivateData, body []byte) ([]byte, diag.Diagnostics) {
+	nb, diags := s.GetNullBody(ctx, d)
+	if diags.HasError() {
+		return nil, diags
+	}
+	if kind, err := jsonset.TypeOf(nb, ""); err != nil || kind != jsonset.KindObject {
+		return body, diags
+	}
+
+	conflicts, err := jsonset.Conflicts(body, nb, jsonset.Options{})
+	if err != nil {
 		diags.AddError(
-			`Error to unmarshal the ephemeral body private data`,
+			`Error to merge the nullified ephemeral body`,
 			err.Error(),
 		)
 		return nil, diags
 	}
-	bEnc, ok := mm["null"]
-	if !ok {
-		return nil, nil
-	}
-	b, err := base64.StdEncoding.DecodeString(bEnc.(string))
+	merged, err := jsonset.Merge(body, nb)
 	if err != nil {
 		diags.AddError(
-			`Error base64 decoding the nullified the ephemeral body in the private data`,
+			`Error to merge the nullified ephemeral body`,
 			err.Error(),
 		)
 		return nil, diags
 	}
-	return b, nil
+	if len(conflicts) != 0 {
+		paths := make([]string, 0, len(conflicts))
+		for _, c := range conflicts {
+			paths = append(paths, dottedPath(c))
+		}
+		diags.AddWarning(
+			`The body overlaps with the ephemeral body`,
+			fmt.Sprintf("The ephemeral body paths are kept null in the body: %s", strings.Join(paths, ", ")),
+		)
+	}
+	return merged, diags
 }
 
 // ValidateEphemeralBody validates a known, non-null ephemeral body doesn't joint with the body.
 // It returns the json representation of the ephemeral body as well (if known, non-null).
 func ValidateEphemeralBody(body []byte, ephemeralBody types.Dynamic) ([]byte, diag.Diagnostics) {
+	eb, _, diags := ValidateEphemeralBodyDetailed(body, ephemeralBody)
+	return eb, diags
+}
+
+// ValidateEphemeralBodyDetailed is similar to ValidateEphemeralBody, while it also returns the paths (in the dot
+// notation) where the body and the ephemeral body joint, for the programmatic use. The same paths are listed in
+// the error diagnostic detail.
+func ValidateEphemeralBodyDetailed(body []byte, ephemeralBody types.Dynamic) ([]byte, []string, diag.Diagnostics) {
+	return ValidateEphemeralBodyWithOptions(body, ephemeralBody, ValidateOptions{})
+}
+
+// ValidateOptions controls the diagnostics reported by ValidateEphemeralBodyWithOptions.
+type ValidateOptions struct {
+	// BodyAttribute and EphemeralBodyAttribute are the attribute names of the body and the ephemeral body.
+	// If either is set, the diagnostics are reported in the "Invalid configuration" style, naming the attributes.
+	// Otherwise, the plain style is used. The unset one defaults to "body" or "ephemeral_body" respectively.
+	BodyAttribute          string
+	EphemeralBodyAttribute string
+}
+
+func (opts ValidateOptions) disjointError(paths []string) (string, string) {
+	if opts.BodyAttribute == "" && opts.EphemeralBodyAttribute == "" {
+		return "the body and the ephemeral body are not disjointed",
+			fmt.Sprintf("Both define the following paths: %s", strings.Join(paths, ", "))
+	}
+	battr, ebattr := opts.BodyAttribute, opts.EphemeralBodyAttribute
+	if battr == "" {
+		battr = "body"
+	}
+	if ebattr == "" {
+		ebattr = "ephemeral_body"
+	}
+	return "Invalid configuration",
+		fmt.Sprintf("The %q and the %q are not disjointed, both define the following paths: %s", battr, ebattr, strings.Join(paths, ", "))
+}
+
+// ValidateEphemeralBodyWithOptions is similar to ValidateEphemeralBodyDetailed, while the style of the diagnostics
+// is controlled by the opts. The returned ephemeral body and paths are the same regardless of the opts.
+func ValidateEphemeralBodyWithOptions(body []byte, ephemeralBody types.Dynamic, opts ValidateOptions) ([]byte, []string, diag.Diagnostics) {
 	if ephemeralBody.IsUnknown() || ephemeralBody.IsNull() {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	var diags diag.Diagnostics
@@ -185,22 +593,72 @@ func ValidateEphemeralBody(body []byte, ephemeralBody types.Dynamic) ([]byte, di
 			"failed to marshal ephemeral body",
 			err.Error(),
 		)
-		return nil, diags
+		return nil, nil, diags
 	}
-	disjointed, err := jsonset.Disjointed(body, eb)
+	conflicts, err := jsonset.DisjointedDetail(body, eb)
 	if err != nil {
 		diags.AddError(
 			"failed to check disjoint of the body and the ephemeral body",
 			err.Error(),
 		)
-		return nil, diags
+		return nil, nil, diags
 	}
-	if !disjointed {
-		diags.AddError(
-			"the body and the ephemeral body are not disjointed",
-			"",
-		)
+	if len(conflicts) != 0 {
+		paths := make([]string, 0, len(conflicts))
+		for _, c := range conflicts {
+			paths = append(paths, dottedPath(c))
+		}
+		diags.AddError(opts.disjointError(paths))
+		return nil, paths, diags
+	}
+	return eb, nil, nil
+}
+
+// ValidateEphemeralBodies is similar to ValidateEphemeralBody, while it validates multiple ephemeral bodies, keyed by
+// their attribute names (e.g. "ephemeral_auth"), which must be disjointed from the body, and pairwise disjointed
+// from each other. The null or unknown ephemeral bodies are skipped. It returns the marshaled JSON of every known,
+// non-null ephemeral body, keyed the same. All the conflicts are reported, each in the "Invalid configuration" style
+// (see ValidateOptions) naming the attributes of the conflicting pair, in which case nil is returned.
+func ValidateEphemeralBodies(body []byte, bodies map[string]types.Dynamic) (map[string][]byte, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	names := slices.Sorted(maps.Keys(bodies))
+	ebs := map[string][]byte{}
+	for _, name := range names {
+		eb, paths, odiags := ValidateEphemeralBodyWithOptions(body, bodies[name], ValidateOptions{EphemeralBodyAttribute: name})
+		diags.Append(odiags...)
+		if paths != nil {
+			// Conflicting with the body, while still checked against the other ephemeral bodies.
+			eb, _ = dynamic.ToJSON(bodies[name])
+		}
+		if eb != nil {
+			ebs[name] = eb
+		}
+	}
+	for i, lname := range names {
+		for _, rname := range names[i+1:] {
+			leb, reb := ebs[lname], ebs[rname]
+			if leb == nil || reb == nil {
+				continue
+			}
+			conflicts, err := jsonset.DisjointedDetail(leb, reb)
+			if err != nil {
+				diags.AddError(
+					"failed to check disjoint of the ephemeral bodies",
+					err.Error(),
+				)
+				continue
+			}
+			if len(conflicts) != 0 {
+				paths := make([]string, 0, len(conflicts))
+				for _, c := range conflicts {
+					paths = append(paths, dottedPath(c))
+				}
+				diags.AddError(ValidateOptions{BodyAttribute: lname, EphemeralBodyAttribute: rname}.disjointError(paths))
+			}
+		}
+	}
+	if diags.HasError() {
 		return nil, diags
 	}
-	return eb, nil
+	return ebs, diags
 }
@@ -0,0 +1,613 @@
+package ephemeral_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/dynamic"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func objectBody(attrs map[string]string) types.Dynamic {
+	attrTypes := map[string]attr.Type{}
+	attrVals := map[string]attr.Value{}
+	for k, v := range attrs {
+		attrTypes[k] = types.StringType
+		attrVals[k] = types.StringValue(v)
+	}
+	return types.DynamicValue(types.ObjectValueMust(attrTypes, attrVals))
+}
+
+func mustToJSON(t *testing.T, d types.Dynamic) []byte {
+	b, err := dynamic.ToJSON(d)
+	require.NoError(t, err)
+	return b
+}
+
+func TestSetDiff(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	body := objectBody(map[string]string{"password": "foo"})
+
+	changed, diags := ephemeral.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, body)).HasError())
+
+	changed, diags = ephemeral.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	changed, diags = ephemeral.Diff(ctx, d, objectBody(map[string]string{"password": "bar"}))
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	changed, diags = ephemeral.Diff(ctx, d, types.DynamicNull())
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	nb, diags := ephemeral.GetNullBody(ctx, d)
+	require.False(t, diags.HasError())
+	require.JSONEq(t, `{"password": null}`, string(nb))
+
+	require.False(t, ephemeral.Set(ctx, d, nil).HasError())
+	exists, diags := ephemeral.Exists(ctx, d)
+	require.False(t, diags.HasError())
+	require.False(t, exists)
+}
+
+func TestDiffNumberRepresentation(t *testing.T) {
+	ctx := context.Background()
+	numberBody := func(v string) types.Dynamic {
+		f, _, err := big.ParseFloat(v, 10, 512, big.ToNearestEven)
+		require.NoError(t, err)
+		return types.DynamicValue(types.ObjectValueMust(
+			map[string]attr.Type{"n": types.NumberType},
+			map[string]attr.Value{"n": types.NumberValue(f)},
+		))
+	}
+
+	d := ephemeral.NewMemoryPrivateData()
+	require.False(t, ephemeral.Set(ctx, d, []byte(`{"n": 5.0}`)).HasError())
+
+	changed, diags := ephemeral.Diff(ctx, d, numberBody("5"))
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	changed, diags = ephemeral.Diff(ctx, d, numberBody("5.1"))
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	changed, diags = ephemeral.DiffMarshaled(ctx, d, []byte(`{"n":5}`), false)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	changed, diags = ephemeral.DiffMarshaled(ctx, d, []byte(`{"n":5.1}`), false)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	// The version 1 record has the hash calculated on the ephemeral body as is.
+	sum := sha256.Sum256([]byte(`{"n":5}`))
+	v1, err := json.Marshal(map[string]interface{}{"version": 1, "hash": sum[:], "null": []byte(`{"n":null}`)})
+	require.NoError(t, err)
+	require.False(t, d.SetKey(ctx, "ephemeral_body", v1).HasError())
+	changed, diags = ephemeral.DiffMarshaled(ctx, d, []byte(`{"n":5}`), false)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	changed, diags = ephemeral.DiffMarshaled(ctx, d, []byte(`{"n":5.0}`), false)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+}
+
+func TestDiffKeyOrder(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	require.False(t, ephemeral.Set(ctx, d, []byte(`{ "user": "bar", "password": "foo" }`)).HasError())
+	changed, diags := ephemeral.Diff(ctx, d, objectBody(map[string]string{"password": "foo", "user": "bar"}))
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+}
+
+func TestSetWithContentType(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	body := types.DynamicValue(types.StringValue("password: foo\n"))
+	require.False(t, ephemeral.SetWithContentType(ctx, d, []byte("password: foo\n"), "application/yaml").HasError())
+
+	changed, diags := ephemeral.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	kind, diags := ephemeral.DiffKind(ctx, d, types.DynamicValue(types.StringValue("password: bar\n")))
+	require.False(t, diags.HasError())
+	require.Equal(t, ephemeral.ValueChange, kind)
+
+	nb, diags := ephemeral.GetNullBody(ctx, d)
+	require.False(t, diags.HasError())
+	require.Nil(t, nb)
+}
+
+func TestDiffKind(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	body := objectBody(map[string]string{"password": "foo"})
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, body)).HasError())
+
+	kind, diags := ephemeral.DiffKind(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.Equal(t, ephemeral.NoChange, kind)
+
+	kind, diags = ephemeral.DiffKind(ctx, d, objectBody(map[string]string{"password": "bar"}))
+	require.False(t, diags.HasError())
+	require.Equal(t, ephemeral.ValueChange, kind)
+
+	kind, diags = ephemeral.DiffKind(ctx, d, objectBody(map[string]string{"password": "foo", "token": "bar"}))
+	require.False(t, diags.HasError())
+	require.Equal(t, ephemeral.StructuralChange, kind)
+	require.Len(t, diags.Warnings(), 1)
+}
+
+func TestDiffWithDiffCache(t *testing.T) {
+	ctx := ephemeral.WithDiffCache(context.Background())
+	d := ephemeral.NewMemoryPrivateData()
+
+	body := objectBody(map[string]string{"password": "foo"})
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, body)).HasError())
+
+	for range 2 {
+		changed, diags := ephemeral.Diff(ctx, d, body)
+		require.False(t, diags.HasError())
+		require.False(t, changed)
+
+		changed, diags = ephemeral.Diff(ctx, d, objectBody(map[string]string{"password": "bar"}))
+		require.False(t, diags.HasError())
+		require.True(t, changed)
+	}
+}
+
+func TestDiffWithOptionsGrace(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, objectBody(map[string]string{"password": "foo"}))).HasError())
+	written := time.Now()
+
+	newBody := objectBody(map[string]string{"password": "bar"})
+	at := func(offset time.Duration) func() time.Time {
+		return func() time.Time { return written.Add(offset) }
+	}
+
+	changed, diags := ephemeral.DiffWithOptions(ctx, d, newBody, ephemeral.DiffOpts{Grace: 5 * time.Minute, Now: at(time.Minute)})
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	changed, diags = ephemeral.DiffWithOptions(ctx, d, newBody, ephemeral.DiffOpts{Grace: 5 * time.Minute, Now: at(10 * time.Minute)})
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	// Removing the body is never debounced
+	changed, diags = ephemeral.DiffWithOptions(ctx, d, types.DynamicNull(), ephemeral.DiffOpts{Grace: 5 * time.Minute, Now: at(time.Minute)})
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	// A record written in the future is not debounced
+	changed, diags = ephemeral.DiffWithOptions(ctx, d, newBody, ephemeral.DiffOpts{Grace: time.Minute, Now: at(-24 * time.Hour)})
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+}
+
+func TestDiffWithOptionsRecoverFromCorruption(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo"})
+
+	cases := []struct {
+		name   string
+		record string
+	}{
+		{name: "truncated", record: `{"hash":"q83vEjRWeJA`},
+		{name: "hash missing", record: `{"null":"e30="}`},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := ephemeral.NewMemoryPrivateData()
+			require.False(t, d.SetKey(ctx, "ephemeral_body", []byte(tt.record)).HasError())
+
+			_, diags := ephemeral.Diff(ctx, d, body)
+			require.True(t, diags.HasError())
+
+			changed, diags := ephemeral.DiffWithOptions(ctx, d, body, ephemeral.DiffOpts{RecoverFromCorruption: true})
+			require.False(t, diags.HasError())
+			require.Len(t, diags.Warnings(), 1)
+			require.True(t, changed)
+
+			// Regarded as absent, a null body is unchanged.
+			changed, diags = ephemeral.DiffWithOptions(ctx, d, types.DynamicNull(), ephemeral.DiffOpts{RecoverFromCorruption: true})
+			require.False(t, diags.HasError())
+			require.False(t, changed)
+		})
+	}
+}
+
+func TestSetWithOptionsChunkSize(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	body := objectBody(map[string]string{"password": "foo", "token": "bar", "secret": "baz"})
+	require.False(t, ephemeral.SetWithOptions(ctx, d, mustToJSON(t, body), ephemeral.Options{ChunkSize: 10}).HasError())
+
+	// The chunk keys are internal, which are not listed by ListKeys.
+	keys, diags := d.Keys(ctx)
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{"ephemeral_body", "ephemeral_body.0", "ephemeral_body.1", "ephemeral_body.2", "ephemeral_body.3", "ephemeral_body.4"}, keys)
+
+	nb, diags := ephemeral.GetNullBody(ctx, d)
+	require.False(t, diags.HasError())
+	require.JSONEq(t, `{"password": null, "token": null, "secret": null}`, string(nb))
+
+	changed, diags := ephemeral.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	// Fewer chunks removes the stale ones
+	body = objectBody(map[string]string{"password": "foo"})
+	require.False(t, ephemeral.SetWithOptions(ctx, d, mustToJSON(t, body), ephemeral.Options{ChunkSize: 10}).HasError())
+	keys, diags = d.Keys(ctx)
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{"ephemeral_body", "ephemeral_body.0", "ephemeral_body.1"}, keys)
+
+	nb, diags = ephemeral.GetNullBody(ctx, d)
+	require.False(t, diags.HasError())
+	require.JSONEq(t, `{"password": null}`, string(nb))
+
+	// Removing the record removes all the chunks
+	require.False(t, ephemeral.Set(ctx, d, nil).HasError())
+	keys, diags = d.Keys(ctx)
+	require.False(t, diags.HasError())
+	require.Empty(t, keys)
+}
+
+func TestClear(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo", "token": "bar"})
+
+	cases := []struct {
+		name  string
+		clear func(d ephemeral.PrivateData) diag.Diagnostics
+	}{
+		{
+			name:  "Clear",
+			clear: func(d ephemeral.PrivateData) diag.Diagnostics { return ephemeral.Clear(ctx, d) },
+		},
+		{
+			name:  "Set nil",
+			clear: func(d ephemeral.PrivateData) diag.Diagnostics { return ephemeral.Set(ctx, d, nil) },
+		},
+		{
+			name:  "Set empty",
+			clear: func(d ephemeral.PrivateData) diag.Diagnostics { return ephemeral.Set(ctx, d, []byte{}) },
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := ephemeral.NewMemoryPrivateData()
+			require.False(t, ephemeral.SetWithOptions(ctx, d, mustToJSON(t, body), ephemeral.Options{ChunkSize: 10}).HasError())
+
+			require.False(t, tt.clear(d).HasError())
+			keys, diags := ephemeral.ListKeys(ctx, d, "")
+			require.False(t, diags.HasError())
+			require.Empty(t, keys)
+
+			// Clearing a nonexistent record is not an error
+			require.False(t, tt.clear(d).HasError())
+		})
+	}
+}
+
+// lossyPrivateData drops the writes to the keys in drop silently.
+type lossyPrivateData struct {
+	*ephemeral.MemoryPrivateData
+	drop map[string]bool
+}
+
+func (d lossyPrivateData) SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics {
+	if d.drop[key] {
+		return nil
+	}
+	return d.MemoryPrivateData.SetKey(ctx, key, value)
+}
+
+func TestSetWithOptionsVerifyWrite(t *testing.T) {
+	ctx := context.Background()
+	body := mustToJSON(t, objectBody(map[string]string{"password": "foo"}))
+
+	d := lossyPrivateData{MemoryPrivateData: ephemeral.NewMemoryPrivateData(), drop: map[string]bool{"ephemeral_body": true}}
+	require.False(t, ephemeral.SetWithOptions(ctx, d, body, ephemeral.Options{}).HasError())
+	require.True(t, ephemeral.SetWithOptions(ctx, d, body, ephemeral.Options{VerifyWrite: true}).HasError())
+
+	d = lossyPrivateData{MemoryPrivateData: ephemeral.NewMemoryPrivateData(), drop: map[string]bool{"ephemeral_body.1": true}}
+	require.True(t, ephemeral.SetWithOptions(ctx, d, body, ephemeral.Options{ChunkSize: 8, VerifyWrite: true}).HasError())
+
+	d = lossyPrivateData{MemoryPrivateData: ephemeral.NewMemoryPrivateData()}
+	require.False(t, ephemeral.SetWithOptions(ctx, d, body, ephemeral.Options{ChunkSize: 8, VerifyWrite: true}).HasError())
+	require.False(t, ephemeral.SetWithOptions(ctx, d, nil, ephemeral.Options{VerifyWrite: true}).HasError())
+	exists, diags := ephemeral.Exists(ctx, d)
+	require.False(t, diags.HasError())
+	require.False(t, exists)
+}
+
+func TestSetWithOptionsSaltHash(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo"})
+	other := objectBody(map[string]string{"password": "bar"})
+
+	hashOf := func(d ephemeral.PrivateData) []byte {
+		b, diags := d.GetKey(ctx, "ephemeral_body")
+		require.False(t, diags.HasError())
+		var rec struct {
+			Hash []byte `json:"hash"`
+		}
+		require.NoError(t, json.Unmarshal(b, &rec))
+		return rec.Hash
+	}
+
+	plain := ephemeral.NewMemoryPrivateData()
+	require.False(t, ephemeral.Set(ctx, plain, mustToJSON(t, body)).HasError())
+
+	d1 := ephemeral.NewMemoryPrivateData()
+	require.False(t, ephemeral.SetWithOptions(ctx, d1, mustToJSON(t, body), ephemeral.Options{SaltHash: true}).HasError())
+	d2 := ephemeral.NewMemoryPrivateData()
+	require.False(t, ephemeral.SetWithOptions(ctx, d2, mustToJSON(t, body), ephemeral.Options{SaltHash: true}).HasError())
+
+	// The salted hashes differ from the plain one, and from each other.
+	require.NotEqual(t, hashOf(plain), hashOf(d1))
+	require.NotEqual(t, hashOf(d1), hashOf(d2))
+
+	ctx = ephemeral.WithDiffCache(ctx)
+	for _, d := range []ephemeral.PrivateData{plain, d1, d2} {
+		changed, diags := ephemeral.Diff(ctx, d, body)
+		require.False(t, diags.HasError())
+		require.False(t, changed)
+		changed, diags = ephemeral.Diff(ctx, d, other)
+		require.False(t, diags.HasError())
+		require.True(t, changed)
+	}
+
+	matched, diags := ephemeral.MatchesHash(ctx, d1, hashOf(plain))
+	require.False(t, diags.HasError())
+	require.False(t, matched)
+}
+
+func TestExplain(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	body := objectBody(map[string]string{"password": "foo", "token": "bar"})
+
+	explain := func(body types.Dynamic) string {
+		s, diags := ephemeral.Explain(ctx, d, body)
+		require.False(t, diags.HasError())
+		return s
+	}
+
+	require.Equal(t, "ephemeral body unchanged", explain(types.DynamicNull()))
+	require.Equal(t, "ephemeral body will be set (first apply)", explain(body))
+	require.Equal(t, "ephemeral body is not known yet", explain(types.DynamicUnknown()))
+
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, body)).HasError())
+
+	require.Equal(t, "ephemeral body unchanged", explain(body))
+	require.Equal(t, "ephemeral body changed", explain(objectBody(map[string]string{"password": "secret", "token": "bar"})))
+	require.Equal(t, "ephemeral body changed at 1 path: token", explain(objectBody(map[string]string{"password": "foo"})))
+	s := explain(objectBody(map[string]string{"password": "foo", "a/b": "x", "c": "secret"}))
+	require.Equal(t, "ephemeral body changed at 3 paths: a/b, c, token", s)
+	require.NotContains(t, s, "secret")
+	require.Equal(t, "ephemeral body will be removed", explain(types.DynamicNull()))
+}
+
+func TestDiffMarshaled(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	body := objectBody(map[string]string{"password": "foo"})
+	eb := mustToJSON(t, body)
+
+	changed, diags := ephemeral.DiffMarshaled(ctx, d, nil, true)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	changed, diags = ephemeral.DiffMarshaled(ctx, d, eb, false)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	require.False(t, ephemeral.Set(ctx, d, eb).HasError())
+
+	changed, diags = ephemeral.DiffMarshaled(ctx, d, eb, false)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	changed, diags = ephemeral.DiffMarshaled(ctx, d, mustToJSON(t, objectBody(map[string]string{"password": "bar"})), false)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	changed, diags = ephemeral.DiffMarshaled(ctx, d, nil, true)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+}
+
+func TestMergeNullBody(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	// No record
+	b, diags := ephemeral.MergeNullBody(ctx, d, []byte(`{"name": "x"}`))
+	require.False(t, diags.HasError())
+	require.JSONEq(t, `{"name": "x"}`, string(b))
+
+	eb := []byte(`{"password": "foo", "properties": {"token": "bar"}}`)
+	require.False(t, ephemeral.Set(ctx, d, eb).HasError())
+
+	b, diags = ephemeral.MergeNullBody(ctx, d, []byte(`{"name": "x", "properties": {"size": 1}}`))
+	require.False(t, diags.HasError())
+	require.Empty(t, diags.Warnings())
+	require.Equal(t, `{"name":"x","properties":{"size":1,"token":null},"password":null}`, string(b))
+
+	// The conflicting secrets are kept null.
+	b, diags = ephemeral.MergeNullBody(ctx, d, []byte(`{"name": "x", "password": "foo", "properties": {"token": "bar"}}`))
+	require.False(t, diags.HasError())
+	require.Len(t, diags.Warnings(), 1)
+	require.Equal(t, "The ephemeral body paths are kept null in the body: password, properties.token", diags.Warnings()[0].Detail())
+	require.Equal(t, `{"name":"x","password":null,"properties":{"token":null}}`, string(b))
+
+	_, diags = ephemeral.MergeNullBody(ctx, d, []byte(`[1]`))
+	require.True(t, diags.HasError())
+}
+
+func TestValidateEphemeralBody(t *testing.T) {
+	eb := objectBody(map[string]string{"password": "foo", "user": "bar"})
+
+	b, diags := ephemeral.ValidateEphemeralBody([]byte(`{"name": "x"}`), eb)
+	require.False(t, diags.HasError())
+	require.JSONEq(t, `{"password": "foo", "user": "bar"}`, string(b))
+
+	_, diags = ephemeral.ValidateEphemeralBody([]byte(`{"name": "x", "password": "y", "user": null}`), eb)
+	require.True(t, diags.HasError())
+	require.Equal(t, "Both define the following paths: password, user", diags.Errors()[0].Detail())
+}
+
+func TestValidateEphemeralBodyDetailed(t *testing.T) {
+	eb := objectBody(map[string]string{"password": "foo", "user": "bar"})
+
+	b, paths, diags := ephemeral.ValidateEphemeralBodyDetailed([]byte(`{"name": "x"}`), eb)
+	require.False(t, diags.HasError())
+	require.Empty(t, paths)
+	require.JSONEq(t, `{"password": "foo", "user": "bar"}`, string(b))
+
+	b, paths, diags = ephemeral.ValidateEphemeralBodyDetailed([]byte(`{"name": "x", "user": {"a": 1}}`), eb)
+	require.True(t, diags.HasError())
+	require.Nil(t, b)
+	require.Equal(t, []string{"user"}, paths)
+
+	b, paths, diags = ephemeral.ValidateEphemeralBodyDetailed([]byte(`{"user": "x"}`), types.DynamicUnknown())
+	require.False(t, diags.HasError())
+	require.Nil(t, b)
+	require.Nil(t, paths)
+}
+
+func TestValidateEphemeralBodyWithOptions(t *testing.T) {
+	eb := objectBody(map[string]string{"password": "foo", "user": "bar"})
+	body := []byte(`{"name": "x", "password": "y", "user": null}`)
+
+	cases := []struct {
+		name    string
+		opts    ephemeral.ValidateOptions
+		summary string
+		detail  string
+	}{
+		{
+			name:    "plain",
+			summary: "the body and the ephemeral body are not disjointed",
+			detail:  "Both define the following paths: password, user",
+		},
+		{
+			name:    "attribute",
+			opts:    ephemeral.ValidateOptions{EphemeralBodyAttribute: "sensitive_body"},
+			summary: "Invalid configuration",
+			detail:  `The "body" and the "sensitive_body" are not disjointed, both define the following paths: password, user`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			b, paths, diags := ephemeral.ValidateEphemeralBodyWithOptions(body, eb, tt.opts)
+			require.Nil(t, b)
+			require.Equal(t, []string{"password", "user"}, paths)
+			require.Len(t, diags, 1)
+			require.Equal(t, tt.summary, diags[0].Summary())
+			require.Equal(t, tt.detail, diags[0].Detail())
+
+			b, paths, diags = ephemeral.ValidateEphemeralBodyWithOptions([]byte(`{"name": "x"}`), eb, tt.opts)
+			require.False(t, diags.HasError())
+			require.Empty(t, paths)
+			require.JSONEq(t, `{"password": "foo", "user": "bar"}`, string(b))
+		})
+	}
+}
+
+func TestValidateEphemeralBodies(t *testing.T) {
+	body := []byte(`{"name": "x", "password": "y"}`)
+
+	ebs, diags := ephemeral.ValidateEphemeralBodies(body, map[string]types.Dynamic{
+		"ephemeral_auth":    objectBody(map[string]string{"token": "foo"}),
+		"ephemeral_tls":     objectBody(map[string]string{"cert": "bar"}),
+		"ephemeral_null":    types.DynamicNull(),
+		"ephemeral_unknown": types.DynamicUnknown(),
+	})
+	require.False(t, diags.HasError())
+	require.Len(t, ebs, 2)
+	require.JSONEq(t, `{"token": "foo"}`, string(ebs["ephemeral_auth"]))
+	require.JSONEq(t, `{"cert": "bar"}`, string(ebs["ephemeral_tls"]))
+
+	ebs, diags = ephemeral.ValidateEphemeralBodies(body, map[string]types.Dynamic{
+		"ephemeral_auth": objectBody(map[string]string{"token": "foo", "password": "foo"}),
+		"ephemeral_tls":  objectBody(map[string]string{"cert": "bar", "token": "bar"}),
+		"ephemeral_key":  objectBody(map[string]string{"key": "baz", "cert": "baz"}),
+		"ephemeral_null": types.DynamicNull(),
+	})
+	require.True(t, diags.HasError())
+	require.Nil(t, ebs)
+	var details []string
+	for _, d := range diags.Errors() {
+		require.Equal(t, "Invalid configuration", d.Summary())
+		details = append(details, d.Detail())
+	}
+	require.Equal(t, []string{
+		`The "body" and the "ephemeral_auth" are not disjointed, both define the following paths: password`,
+		`The "ephemeral_auth" and the "ephemeral_tls" are not disjointed, both define the following paths: token`,
+		`The "ephemeral_key" and the "ephemeral_tls" are not disjointed, both define the following paths: cert`,
+	}, details)
+}
+
+func TestRecordVersion(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo"})
+
+	d := ephemeral.NewMemoryPrivateData()
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, body)).HasError())
+	b, diags := d.GetKey(ctx, "ephemeral_body")
+	require.False(t, diags.HasError())
+	var rec map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(b, &rec))
+	require.JSONEq(t, `5`, string(rec["version"]))
+
+	// The unversioned (version 0) record only has the "hash" and "null".
+	v0, err := json.Marshal(map[string]json.RawMessage{"hash": rec["hash"], "null": rec["null"]})
+	require.NoError(t, err)
+	require.False(t, d.SetKey(ctx, "ephemeral_body", v0).HasError())
+
+	changed, diags := ephemeral.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	nb, diags := ephemeral.GetNullBody(ctx, d)
+	require.False(t, diags.HasError())
+	require.JSONEq(t, `{"password":null}`, string(nb))
+
+	// The record written by a newer version is rejected.
+	rec["version"] = json.RawMessage(`6`)
+	v5, err := json.Marshal(rec)
+	require.NoError(t, err)
+	require.False(t, d.SetKey(ctx, "ephemeral_body", v5).HasError())
+	_, diags = ephemeral.Diff(ctx, d, body)
+	require.True(t, diags.HasError())
+	_, diags = ephemeral.GetNullBody(ctx, d)
+	require.True(t, diags.HasError())
+}
@@ -0,0 +1,123 @@
+package ephemeral
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/dynamic"
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+)
+
+// Explain returns a short human readable sentence describing how the ephemeral body changes, compared to the one
+// recorded in the private state, e.g. "ephemeral body unchanged", "ephemeral body will be set (first apply)", or
+// "ephemeral body changed at 2 paths: a.b, c". It is meant to be put into the diagnostics or the plan output.
+// Only the paths are mentioned, the values (secrets) are never included.
+// As only the hash of the ephemeral body is recorded, the paths are only reported for the structural changes
+// (i.e. keys are added or removed).
+func Explain(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (string, diag.Diagnostics) {
+	return defaultStore.Explain(ctx, d, ephemeralBody)
+}
+
+// Explain returns a short human readable sentence describing how the ephemeral body changes.
+// See the package level Explain for details.
+func (s *Store) Explain(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (string, diag.Diagnostics) {
+	if ephemeralBody.IsUnknown() {
+		return "ephemeral body is not known yet", nil
+	}
+
+	changed, diags := s.Diff(ctx, d, ephemeralBody)
+	if diags.HasError() {
+		return "", diags
+	}
+	if !changed {
+		return "ephemeral body unchanged", diags
+	}
+
+	rec, odiags := getRecord(ctx, d, s.key)
+	diags.Append(odiags...)
+	if diags.HasError() {
+		return "", diags
+	}
+	if rec == nil {
+		return "ephemeral body will be set (first apply)", diags
+	}
+	if ephemeralBody.IsNull() {
+		return "ephemeral body will be removed", diags
+	}
+	if !isJSONContentType(rec.ContentType) {
+		return "ephemeral body changed", diags
+	}
+
+	nb, odiags := s.nullBodyOf(ctx, d, rec)
+	diags.Append(odiags...)
+	if diags.HasError() {
+		return "", diags
+	}
+	if nb == nil {
+		return "ephemeral body changed", diags
+	}
+
+	paths, err := structuralChangedPaths(nb, ephemeralBody)
+	if err != nil {
+		diags.AddError(
+			`Error to compare the structure of the ephemeral body`,
+			err.Error(),
+		)
+		return "", diags
+	}
+	switch len(paths) {
+	case 0:
+		return "ephemeral body changed", diags
+	case 1:
+		return fmt.Sprintf("ephemeral body changed at 1 path: %s", paths[0]), diags
+	default:
+		return fmt.Sprintf("ephemeral body changed at %d paths: %s", len(paths), strings.Join(paths, ", ")), diags
+	}
+}
+
+// structuralChangedPaths returns the paths (in the dot notation) where the nullified ephemeral body differs from
+// the nullified body recorded.
+func structuralChangedPaths(nullBody []byte, ephemeralBody types.Dynamic) ([]string, error) {
+	changes, err := structuralChanges(nullBody, ephemeralBody)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, c := range changes {
+		paths = append(paths, dottedPath(c.Path))
+	}
+	return paths, nil
+}
+
+// structuralChanges returns the changes from the nullified body recorded to the nullified ephemeral body, which is
+// regarded as an empty object if null. Only the paths of the changes are meaningful, as the values are all null.
+func structuralChanges(nullBody []byte, ephemeralBody types.Dynamic) ([]jsonset.Change, error) {
+	if ephemeralBody.IsNull() {
+		return jsonset.AllDiffs(nullBody, []byte(`{}`))
+	}
+	ebody, err := dynamic.ToJSON(ephemeralBody)
+	if err != nil {
+		return nil, err
+	}
+	nb, err := jsonset.NullifyObject(ebody)
+	if err != nil {
+		return nil, err
+	}
+	return jsonset.AllDiffs(nullBody, nb)
+}
+
+// dottedPath converts the JSON pointer of the nullified body (where only objects are kept) to the dot notation.
+// The root pointer is represented as ".".
+func dottedPath(pointer string) string {
+	if pointer == "" {
+		return "."
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, tk := range tokens {
+		tokens[i] = jsonset.UnescapePointerToken(tk)
+	}
+	return strings.Join(tokens, ".")
+}
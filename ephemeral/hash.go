@@ -0,0 +1,96 @@
+package ephemeral
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// HashEncoding is the encoding of an externally supplied hash.
+type HashEncoding int
+
+const (
+	// HashRaw means the hash is the raw digest bytes.
+	HashRaw HashEncoding = iota
+	// HashHex means the hash is hex encoded, case-insensitively.
+	HashHex
+	// HashBase64 means the hash is base64 encoded, either in the standard or the URL alphabet, padded or not.
+	HashBase64
+)
+
+// GetHash gets the hash of the ephemeral body stored in the private state, in the standard base64 encoding, e.g. to
+// build the comparison tooling or logging that doesn't depend on Diff. An empty string is returned if no record
+// exists. See MatchesHash for how the hash is calculated.
+func GetHash(ctx context.Context, d PrivateData) (string, diag.Diagnostics) {
+	return defaultStore.GetHash(ctx, d)
+}
+
+// GetHash gets the base64 encoded hash of the ephemeral body. See the package level GetHash for details.
+func (s *Store) GetHash(ctx context.Context, d PrivateData) (string, diag.Diagnostics) {
+	rec, diags := getRecord(ctx, d, s.key)
+	if diags.HasError() || rec == nil {
+		return "", diags
+	}
+	if rec.Hash == nil {
+		diags.AddError(
+			`Invalid ephemeral body private data`,
+			`Key "hash" not found`,
+		)
+		return "", diags
+	}
+	return base64.StdEncoding.EncodeToString(rec.Hash), diags
+}
+
+// MatchesHash tells whether the hash stored in the private state equals the externally supplied raw hash,
+// e.g. a content hash (ETag-like) computed by the server, which can be used to detect the server side drift.
+// The external hash must be the digest (SHA-256, unless the Store is configured by WithHasher) of the ephemeral
+// body bytes that are passed to Set, normalized by jsonset.Normalize for the JSON content type.
+// It returns false without error if no record exists.
+func MatchesHash(ctx context.Context, d PrivateData, externalHash []byte) (bool, diag.Diagnostics) {
+	return MatchesHashWithEncoding(ctx, d, externalHash, HashRaw)
+}
+
+// MatchesHashWithEncoding is similar to MatchesHash, while the external hash is encoded in enc.
+func MatchesHashWithEncoding(ctx context.Context, d PrivateData, externalHash []byte, enc HashEncoding) (bool, diag.Diagnostics) {
+	return defaultStore.MatchesHashWithEncoding(ctx, d, externalHash, enc)
+}
+
+// MatchesHashWithEncoding tells whether the hash stored in the private state equals the external hash encoded in enc.
+// See the package level MatchesHash for details.
+func (s *Store) MatchesHashWithEncoding(ctx context.Context, d PrivateData, externalHash []byte, enc HashEncoding) (bool, diag.Diagnostics) {
+	rec, diags := getRecord(ctx, d, s.key)
+	if diags.HasError() || rec == nil {
+		return false, diags
+	}
+	hash, err := decodeHash(externalHash, enc)
+	if err != nil {
+		diags.AddError(
+			`Invalid external hash`,
+			err.Error(),
+		)
+		return false, diags
+	}
+	return bytes.Equal(hash, rec.Hash), diags
+}
+
+func decodeHash(hash []byte, enc HashEncoding) ([]byte, error) {
+	switch enc {
+	case HashRaw:
+		return hash, nil
+	case HashHex:
+		return hex.DecodeString(strings.TrimSpace(string(hash)))
+	case HashBase64:
+		s := strings.TrimRight(strings.TrimSpace(string(hash)), "=")
+		if strings.ContainsAny(s, "-_") {
+			return base64.RawURLEncoding.DecodeString(s)
+		}
+		return base64.RawStdEncoding.DecodeString(s)
+	default:
+		return nil, fmt.Errorf("unknown hash encoding %d", enc)
+	}
+}
@@ -0,0 +1,79 @@
+package ephemeral_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesHash(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	eb := mustToJSON(t, objectBody(map[string]string{"password": "foo"}))
+	sum := sha256.Sum256(eb)
+	other := sha256.Sum256([]byte("other"))
+
+	matched, diags := ephemeral.MatchesHash(ctx, d, sum[:])
+	require.False(t, diags.HasError())
+	require.False(t, matched)
+
+	require.False(t, ephemeral.Set(ctx, d, eb).HasError())
+
+	cases := []struct {
+		name    string
+		hash    string
+		enc     ephemeral.HashEncoding
+		matched bool
+		err     bool
+	}{
+		{name: "raw", hash: string(sum[:]), enc: ephemeral.HashRaw, matched: true},
+		{name: "raw mismatch", hash: string(other[:]), enc: ephemeral.HashRaw, matched: false},
+		{name: "hex", hash: hex.EncodeToString(sum[:]), enc: ephemeral.HashHex, matched: true},
+		{name: "upper case hex", hash: strings.ToUpper(hex.EncodeToString(sum[:])), enc: ephemeral.HashHex, matched: true},
+		{name: "base64", hash: base64.StdEncoding.EncodeToString(sum[:]), enc: ephemeral.HashBase64, matched: true},
+		{name: "unpadded base64 url", hash: base64.RawURLEncoding.EncodeToString(sum[:]), enc: ephemeral.HashBase64, matched: true},
+		{name: "base64 mismatch", hash: base64.StdEncoding.EncodeToString(other[:]), enc: ephemeral.HashBase64, matched: false},
+		{name: "invalid hex", hash: "xyz", enc: ephemeral.HashHex, err: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, diags := ephemeral.MatchesHashWithEncoding(ctx, d, []byte(tt.hash), tt.enc)
+			if tt.err {
+				require.True(t, diags.HasError())
+				return
+			}
+			require.False(t, diags.HasError())
+			require.Equal(t, tt.matched, matched)
+		})
+	}
+}
+
+func TestGetHash(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	hash, diags := ephemeral.GetHash(ctx, d)
+	require.False(t, diags.HasError())
+	require.Empty(t, hash)
+
+	eb := mustToJSON(t, objectBody(map[string]string{"password": "foo"}))
+	require.False(t, ephemeral.Set(ctx, d, eb).HasError())
+	sum := sha256.Sum256(eb)
+	hash, diags = ephemeral.GetHash(ctx, d)
+	require.False(t, diags.HasError())
+	require.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), hash)
+
+	for _, record := range []string{`{"hash":`, `{"null":"e30="}`} {
+		require.False(t, d.SetKey(ctx, "ephemeral_body", []byte(record)).HasError())
+		hash, diags = ephemeral.GetHash(ctx, d)
+		require.True(t, diags.HasError())
+		require.Empty(t, hash)
+	}
+}
@@ -0,0 +1,110 @@
+package ephemeral
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"slices"
+)
+
+// DefaultHashAlgorithm is the name of the hash algorithm used by a Store without WithHasher.
+const DefaultHashAlgorithm = "sha256"
+
+// hasher is a named hash algorithm.
+type hasher struct {
+	name string
+	new  func() hash.Hash
+}
+
+var defaultHasher = hasher{name: DefaultHashAlgorithm, new: sha256.New}
+
+// WithHasher returns a copy of the default Store that hashes the ephemeral body with the named hash algorithm.
+// See Store.WithHasher for details.
+func WithHasher(name string, newHash func() hash.Hash) *Store {
+	return defaultStore.WithHasher(name, newHash)
+}
+
+// WithHasher returns a copy of the Store that hashes the ephemeral body with the hash algorithm returned by
+// newHash, e.g. for the FIPS environments, or a cheaper hash for the very large ephemeral bodies. The name
+// identifies the algorithm, which is stored in the record, so that Diff uses the same algorithm that Set used.
+// An empty name or a nil newHash resets to the SHA-256 default.
+//
+// Diff (and the functions based on it) hashes the ephemeral body with the algorithm recorded: the Store's own
+// algorithm, SHA-256 (e.g. for the records written before the Store is configured), or an allowed crypto.Hash
+// (see WithHashFunc). A record of any other algorithm results into an error, rather than being regarded as changed.
+func (s *Store) WithHasher(name string, newHash func() hash.Hash) *Store {
+	ns := *s
+	ns.hasher = defaultHasher
+	if name != "" && newHash != nil {
+		ns.hasher = hasher{name: name, new: newHash}
+	}
+	return &ns
+}
+
+// WithHashFunc returns a copy of the default Store that hashes the ephemeral body with h. See Store.WithHashFunc.
+func WithHashFunc(h crypto.Hash) *Store {
+	return defaultStore.WithHashFunc(h)
+}
+
+// WithHashFunc is similar to WithHasher, while the hash algorithm is the standard crypto.Hash, named by its String
+// (e.g. "SHA-512"). Only the SHA-2, SHA-3 and BLAKE2b algorithms are allowed, the weak ones (e.g. MD5 and SHA-1)
+// reset to the SHA-256 default, as well as crypto.SHA256. The implementation of h must also be linked into the
+// binary, e.g. crypto.BLAKE2b_256 requires the import of golang.org/x/crypto/blake2b, otherwise it resets likewise.
+//
+// Diff also recognizes the records of any allowed and available crypto.Hash by its name, regardless of the Store's
+// own algorithm, so that the records written before the algorithm is switched are still compared.
+func (s *Store) WithHashFunc(h crypto.Hash) *Store {
+	if !allowedCryptoHash(h) || h == crypto.SHA256 {
+		return s.WithHasher("", nil)
+	}
+	return s.WithHasher(h.String(), h.New)
+}
+
+// allowedCryptoHashes are the crypto.Hash algorithms allowed by WithHashFunc and recognized by Diff.
+var allowedCryptoHashes = []crypto.Hash{
+	crypto.SHA256,
+	crypto.SHA384,
+	crypto.SHA512,
+	crypto.SHA512_256,
+	crypto.SHA3_256,
+	crypto.SHA3_384,
+	crypto.SHA3_512,
+	crypto.BLAKE2b_256,
+	crypto.BLAKE2b_384,
+	crypto.BLAKE2b_512,
+}
+
+// allowedCryptoHash tells whether h is allowed and available.
+func allowedCryptoHash(h crypto.Hash) bool {
+	return slices.Contains(allowedCryptoHashes, h) && h.Available()
+}
+
+// cryptoHasher returns the hasher of the allowed and available crypto.Hash of the name, as named by WithHashFunc.
+func cryptoHasher(name string) (hasher, bool) {
+	for _, h := range allowedCryptoHashes {
+		if h.String() == name && h.Available() {
+			return hasher{name: name, new: h.New}, true
+		}
+	}
+	return hasher{}, false
+}
+
+// recordHasher returns the hasher of the hash algorithm of the record.
+func (s *Store) recordHasher(rec *record) (hasher, error) {
+	name := rec.Algorithm
+	if name == "" {
+		name = DefaultHashAlgorithm
+	}
+	switch name {
+	case s.hasher.name:
+		return s.hasher, nil
+	case DefaultHashAlgorithm:
+		return defaultHasher, nil
+	default:
+		if h, ok := cryptoHasher(name); ok {
+			return h, nil
+		}
+		return hasher{}, fmt.Errorf("the ephemeral body is hashed with an unrecognized algorithm %q", name)
+	}
+}
@@ -0,0 +1,115 @@
+package ephemeral_test
+
+import (
+	"context"
+	"crypto"
+	_ "crypto/md5"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/json"
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHasher(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo"})
+	other := objectBody(map[string]string{"password": "bar"})
+	store := ephemeral.WithHasher("sha512", sha512.New)
+
+	d := ephemeral.NewMemoryPrivateData()
+	require.False(t, store.Set(ctx, d, mustToJSON(t, body)).HasError())
+	b, diags := d.GetKey(ctx, "ephemeral_body")
+	require.False(t, diags.HasError())
+	var rec struct {
+		Hash []byte `json:"hash"`
+		Alg  string `json:"alg"`
+	}
+	require.NoError(t, json.Unmarshal(b, &rec))
+	require.Equal(t, "sha512", rec.Alg)
+	require.Len(t, rec.Hash, sha512.Size)
+
+	changed, diags := store.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	changed, diags = store.Diff(ctx, d, other)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+	res, diags := store.DiffDetailed(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.Equal(t, res.PrevHash, res.CurHash)
+
+	// The algorithm recorded is not recognized.
+	_, diags = ephemeral.Diff(ctx, d, body)
+	require.True(t, diags.HasError())
+	_, diags = ephemeral.WithHasher("sha1", sha1.New).Diff(ctx, d, body)
+	require.True(t, diags.HasError())
+
+	// The SHA-256 record is still recognized.
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, body)).HasError())
+	changed, diags = store.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	changed, diags = store.Diff(ctx, d, other)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+}
+
+func TestWithHashFunc(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo"})
+	other := objectBody(map[string]string{"password": "bar"})
+
+	d := ephemeral.NewMemoryPrivateData()
+	require.False(t, ephemeral.WithHashFunc(crypto.SHA512).Set(ctx, d, mustToJSON(t, body)).HasError())
+	b, diags := d.GetKey(ctx, "ephemeral_body")
+	require.False(t, diags.HasError())
+	var rec struct {
+		Hash []byte `json:"hash"`
+		Alg  string `json:"alg"`
+	}
+	require.NoError(t, json.Unmarshal(b, &rec))
+	require.Equal(t, "SHA-512", rec.Alg)
+	require.Len(t, rec.Hash, sha512.Size)
+
+	// The record of a crypto.Hash is recognized by the Store of another algorithm.
+	for _, store := range []*ephemeral.Store{
+		ephemeral.WithHashFunc(crypto.SHA512),
+		ephemeral.WithHashFunc(crypto.SHA384),
+		ephemeral.WithHasher("", nil),
+	} {
+		changed, diags := store.Diff(ctx, d, body)
+		require.False(t, diags.HasError())
+		require.False(t, changed)
+		changed, diags = store.Diff(ctx, d, other)
+		require.False(t, diags.HasError())
+		require.True(t, changed)
+	}
+
+	// SHA-256, the weak and the unavailable hashes reset to the default.
+	for _, h := range []crypto.Hash{crypto.SHA256, crypto.MD5, crypto.SHA1, crypto.BLAKE2b_256} {
+		require.False(t, ephemeral.WithHashFunc(h).Set(ctx, d, mustToJSON(t, body)).HasError())
+		b, diags = d.GetKey(ctx, "ephemeral_body")
+		require.False(t, diags.HasError())
+		rec.Alg = ""
+		require.NoError(t, json.Unmarshal(b, &rec))
+		require.Empty(t, rec.Alg)
+	}
+
+	// The record of a weak hash is not recognized, even if it is available.
+	for _, alg := range []string{crypto.MD5.String(), crypto.SHA1.String(), crypto.MD4.String()} {
+		require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, body)).HasError())
+		b, diags = d.GetKey(ctx, "ephemeral_body")
+		require.False(t, diags.HasError())
+		var raw map[string]interface{}
+		require.NoError(t, json.Unmarshal(b, &raw))
+		raw["alg"] = alg
+		b, err := json.Marshal(raw)
+		require.NoError(t, err)
+		require.False(t, d.SetKey(ctx, "ephemeral_body", b).HasError())
+		_, diags = ephemeral.Diff(ctx, d, body)
+		require.True(t, diags.HasError(), alg)
+	}
+}
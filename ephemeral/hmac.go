@@ -0,0 +1,101 @@
+package ephemeral
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// WithHMACKey returns a copy of the default Store that authenticates the record with HMAC-SHA256 keyed by the
+// provider supplied secret. See Store.WithHMACKey for details.
+func WithHMACKey(key []byte) *Store {
+	return defaultStore.WithHMACKey(key)
+}
+
+// WithHMACKey returns a copy of the Store that authenticates the record with HMAC-SHA256 keyed by the provider
+// supplied secret, over the whole record (e.g. the hash, the algorithm, and the timestamp) and the nullified body
+// (as stored, including the chunks). This protects the record from being edited
+// in the state file, e.g. to make Diff believe nothing changed for an ephemeral body that gates a credential
+// rotation. A nil or empty key disables the authentication, which is the plain SHA-256 behavior.
+//
+// Diff (and the functions based on it) then verifies the record before comparing the hash:
+//   - A record with a mismatched MAC, i.e. tampered, or written with a different key, results into an error.
+//   - A record written with a MAC results into an error if the Store has no key.
+//   - A record written without a MAC (e.g. before the key is configured, or with the MAC stripped), or with the MAC
+//     of an older record version that only covers the nullified body and the hash, is regarded as changed, with
+//     a warning, so that the record is re-established (authenticated) on the next apply.
+func (s *Store) WithHMACKey(key []byte) *Store {
+	ns := *s
+	ns.hmacKey = nil
+	if len(key) != 0 {
+		ns.hmacKey = slices.Clone(key)
+	}
+	return &ns
+}
+
+// mac returns the MAC of the record (with the MAC itself zeroed) and the nullified body as stored, or nil if the
+// Store has no HMAC key. The nullified body is covered separately, as it is stored in the chunks if chunked.
+// The record is encoded as it is marshaled into the private state, whose fields are in a fixed order.
+func (s *Store) mac(rec record, nullBody []byte) []byte {
+	if s.hmacKey == nil {
+		return nil
+	}
+	rec.MAC = nil
+	b, err := json.Marshal(rec)
+	if err != nil {
+		// The record only consists of the types that always marshal.
+		panic(err)
+	}
+	h := hmac.New(sha256.New, s.hmacKey)
+	h.Write(b)
+	h.Write([]byte{0})
+	h.Write(nullBody)
+	return h.Sum(nil)
+}
+
+// verifyMAC verifies the MAC of the record. It returns false (with a warning) if the Store has an HMAC key while
+// the record has no MAC. See WithHMACKey for details.
+func (s *Store) verifyMAC(ctx context.Context, d PrivateData, rec *record) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if rec.MAC == nil && s.hmacKey == nil {
+		return true, nil
+	}
+	if s.hmacKey == nil {
+		diags.AddError(
+			`Unverifiable ephemeral body private data`,
+			`The ephemeral body record is authenticated with an HMAC, while no HMAC key is configured`,
+		)
+		return false, diags
+	}
+	if rec.MAC == nil {
+		diags.AddWarning(
+			`Unauthenticated ephemeral body private data`,
+			`The ephemeral body record has no HMAC, it is regarded as changed to be re-established on the next apply`,
+		)
+		return false, diags
+	}
+	if rec.Version < macRecordVersion {
+		// The MAC of the older versions doesn't cover the whole record, which can't be trusted.
+		diags.AddWarning(
+			`Unauthenticated ephemeral body private data`,
+			`The HMAC of the ephemeral body record is of an older version, it is regarded as changed to be re-established on the next apply`,
+		)
+		return false, diags
+	}
+	nb, diags := rawNullBodyOf(ctx, d, s.key, rec)
+	if diags.HasError() {
+		return false, diags
+	}
+	if !hmac.Equal(rec.MAC, s.mac(*rec, nb)) {
+		diags.AddError(
+			`Tampered ephemeral body private data`,
+			`The HMAC of the ephemeral body record doesn't match, the record is either tampered or authenticated with a different key`,
+		)
+		return false, diags
+	}
+	return true, diags
+}
@@ -0,0 +1,128 @@
+package ephemeral_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHMACKey(t *testing.T) {
+	ctx := context.Background()
+	foo := objectBody(map[string]string{"password": "foo"})
+	bar := objectBody(map[string]string{"password": "bar"})
+
+	s := ephemeral.WithHMACKey([]byte("secret"))
+	d := ephemeral.NewMemoryPrivateData()
+	require.False(t, s.SetWithOptions(ctx, d, mustToJSON(t, foo), ephemeral.Options{ChunkSize: 4}).HasError())
+
+	changed, diags := s.Diff(ctx, d, foo)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	changed, diags = s.Diff(ctx, d, bar)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	// No key
+	_, diags = ephemeral.Diff(ctx, d, foo)
+	require.True(t, diags.HasError())
+
+	// Different key
+	_, diags = ephemeral.WithHMACKey([]byte("other")).Diff(ctx, d, foo)
+	require.True(t, diags.HasError())
+
+	// Tampered to make the new body regarded as unchanged
+	tampered := ephemeral.NewMemoryPrivateData()
+	require.False(t, ephemeral.Set(ctx, tampered, mustToJSON(t, bar)).HasError())
+	b, _ := tampered.GetKey(ctx, ephemeral.DefaultKey)
+	var barRec map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &barRec))
+	b, _ = d.GetKey(ctx, ephemeral.DefaultKey)
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &rec))
+	rec["hash"] = barRec["hash"]
+	b, err := json.Marshal(rec)
+	require.NoError(t, err)
+	require.False(t, d.SetKey(ctx, ephemeral.DefaultKey, b).HasError())
+	_, diags = s.Diff(ctx, d, bar)
+	require.True(t, diags.HasError())
+
+	// Unauthenticated record is regarded as changed
+	changed, diags = s.Diff(ctx, tampered, bar)
+	require.False(t, diags.HasError())
+	require.Len(t, diags.Warnings(), 1)
+	require.True(t, changed)
+}
+
+func TestWithHMACKeyTamperedRecord(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo"})
+	s := ephemeral.WithHMACKey([]byte("secret")).WithTTL(time.Hour)
+
+	cases := []struct {
+		name   string
+		tamper func(rec map[string]interface{})
+	}{
+		{
+			name: "written_at",
+			tamper: func(rec map[string]interface{}) {
+				rec["written_at"] = time.Now().Add(time.Hour).UTC().Format(time.RFC3339Nano)
+			},
+		},
+		{
+			name: "alg",
+			tamper: func(rec map[string]interface{}) {
+				rec["alg"] = "SHA-384"
+			},
+		},
+		{
+			name: "hash_salt",
+			tamper: func(rec map[string]interface{}) {
+				rec["hash_salt"] = "AAAA"
+			},
+		},
+		{
+			name: "content_type",
+			tamper: func(rec map[string]interface{}) {
+				rec["content_type"] = "text/plain"
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := ephemeral.NewMemoryPrivateData()
+			require.False(t, s.Set(ctx, d, mustToJSON(t, body)).HasError())
+			b, diags := d.GetKey(ctx, ephemeral.DefaultKey)
+			require.False(t, diags.HasError())
+			var rec map[string]interface{}
+			require.NoError(t, json.Unmarshal(b, &rec))
+			tt.tamper(rec)
+			b, err := json.Marshal(rec)
+			require.NoError(t, err)
+			require.False(t, d.SetKey(ctx, ephemeral.DefaultKey, b).HasError())
+
+			_, diags = s.Diff(ctx, d, body)
+			require.True(t, diags.HasError())
+			require.Equal(t, "Tampered ephemeral body private data", diags.Errors()[0].Summary())
+		})
+	}
+
+	// The MAC of an older record version is regarded as unauthenticated, as it doesn't cover the whole record.
+	d := ephemeral.NewMemoryPrivateData()
+	require.False(t, s.Set(ctx, d, mustToJSON(t, body)).HasError())
+	b, diags := d.GetKey(ctx, ephemeral.DefaultKey)
+	require.False(t, diags.HasError())
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &rec))
+	rec["version"] = 3
+	b, err := json.Marshal(rec)
+	require.NoError(t, err)
+	require.False(t, d.SetKey(ctx, ephemeral.DefaultKey, b).HasError())
+	changed, diags := s.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.Len(t, diags.Warnings(), 1)
+	require.True(t, changed)
+}
@@ -0,0 +1,137 @@
+package ephemeral
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+const (
+	pkRegistry = "ephemeral_keys"
+)
+
+// KeyLister is implemented by the PrivateData that is able to enumerate its keys.
+//
+// Note that the framework's private state (i.e. the Private field of the resource requests/responses)
+// doesn't support enumeration. For it, use Register/Unregister to record the keys in a registry,
+// which is then used by ListKeys.
+type KeyLister interface {
+	Keys(ctx context.Context) ([]string, diag.Diagnostics)
+}
+
+// ListKeys returns the sorted keys of the private data that have the prefix.
+// If the private data implements KeyLister, the keys are enumerated from it. Otherwise, the keys
+// recorded in the registry (via Register) are returned.
+// The internal keys are excluded, i.e. the registry and the chunks of a chunked record (see Options.ChunkSize),
+// so that only the keys being set are returned.
+func ListKeys(ctx context.Context, d PrivateData, prefix string) ([]string, diag.Diagnostics) {
+	var (
+		keys  []string
+		diags diag.Diagnostics
+	)
+	if l, ok := d.(KeyLister); ok {
+		keys, diags = l.Keys(ctx)
+	} else {
+		keys, diags = registeredKeys(ctx, d)
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	all := map[string]bool{}
+	for _, k := range keys {
+		all[k] = true
+	}
+	var out []string
+	for _, k := range keys {
+		if k == pkRegistry || isChunkKey(all, k) {
+			continue
+		}
+		if strings.HasPrefix(k, prefix) {
+			out = append(out, k)
+		}
+	}
+	slices.Sort(out)
+	return out, diags
+}
+
+// isChunkKey tells whether the key is a chunk key (see chunkKey) of another key among all.
+func isChunkKey(all map[string]bool, key string) bool {
+	i := strings.LastIndexByte(key, '.')
+	if i <= 0 || i == len(key)-1 {
+		return false
+	}
+	for _, c := range key[i+1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return all[key[:i]]
+}
+
+// Register records the key in the registry stored in the private data, so that it can be listed by ListKeys
+// for a PrivateData that doesn't implement KeyLister.
+func Register(ctx context.Context, d PrivateData, key string) diag.Diagnostics {
+	keys, diags := registeredKeys(ctx, d)
+	if diags.HasError() {
+		return diags
+	}
+	if slices.Contains(keys, key) {
+		return diags
+	}
+	keys = append(keys, key)
+	slices.Sort(keys)
+	return append(diags, setRegisteredKeys(ctx, d, keys)...)
+}
+
+// Unregister removes the key from the registry stored in the private data.
+func Unregister(ctx context.Context, d PrivateData, key string) diag.Diagnostics {
+	keys, diags := registeredKeys(ctx, d)
+	if diags.HasError() {
+		return diags
+	}
+	idx := slices.Index(keys, key)
+	if idx == -1 {
+		return diags
+	}
+	keys = slices.Delete(keys, idx, idx+1)
+	return append(diags, setRegisteredKeys(ctx, d, keys)...)
+}
+
+func registeredKeys(ctx context.Context, d PrivateData) ([]string, diag.Diagnostics) {
+	b, diags := d.GetKey(ctx, pkRegistry)
+	if diags.HasError() {
+		return nil, diags
+	}
+	if b == nil {
+		return nil, diags
+	}
+	var keys []string
+	if err := json.Unmarshal(b, &keys); err != nil {
+		diags.AddError(
+			`Error to unmarshal the ephemeral key registry`,
+			err.Error(),
+		)
+		return nil, diags
+	}
+	return keys, diags
+}
+
+func setRegisteredKeys(ctx context.Context, d PrivateData, keys []string) diag.Diagnostics {
+	if len(keys) == 0 {
+		return d.SetKey(ctx, pkRegistry, nil)
+	}
+	b, err := json.Marshal(keys)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError(
+			`Error to marshal the ephemeral key registry`,
+			err.Error(),
+		)
+		return diags
+	}
+	return d.SetKey(ctx, pkRegistry, b)
+}
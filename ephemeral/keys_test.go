@@ -0,0 +1,71 @@
+package ephemeral_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+// privateOnly hides the KeyLister implementation of the underlying private data.
+type privateOnly struct {
+	d ephemeral.PrivateData
+}
+
+func (p privateOnly) GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p.d.GetKey(ctx, key)
+}
+
+func (p privateOnly) SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics {
+	return p.d.SetKey(ctx, key, value)
+}
+
+func TestListKeys(t *testing.T) {
+	ctx := context.Background()
+
+	d := ephemeral.NewMemoryPrivateData()
+	require.False(t, d.SetKey(ctx, "ephemeral_body", []byte(`{}`)).HasError())
+	require.False(t, d.SetKey(ctx, "ephemeral_headers", []byte(`{}`)).HasError())
+	require.False(t, d.SetKey(ctx, "other", []byte(`{}`)).HasError())
+
+	keys, diags := ephemeral.ListKeys(ctx, d, "ephemeral_")
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{"ephemeral_body", "ephemeral_headers"}, keys)
+
+	// Registry based listing
+	p := privateOnly{d: ephemeral.NewMemoryPrivateData()}
+	keys, diags = ephemeral.ListKeys(ctx, p, "")
+	require.False(t, diags.HasError())
+	require.Empty(t, keys)
+
+	require.False(t, ephemeral.Register(ctx, p, "ephemeral_headers").HasError())
+	require.False(t, ephemeral.Register(ctx, p, "ephemeral_body").HasError())
+	require.False(t, ephemeral.Register(ctx, p, "ephemeral_body").HasError())
+	keys, diags = ephemeral.ListKeys(ctx, p, "ephemeral_")
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{"ephemeral_body", "ephemeral_headers"}, keys)
+
+	require.False(t, ephemeral.Unregister(ctx, p, "ephemeral_body").HasError())
+	keys, diags = ephemeral.ListKeys(ctx, p, "ephemeral_")
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{"ephemeral_headers"}, keys)
+
+	// The chunk keys of a chunked record are not listed.
+	d = ephemeral.NewMemoryPrivateData()
+	body := mustToJSON(t, objectBody(map[string]string{"password": "a long enough password to be chunked"}))
+	for _, id := range []string{"foo", "bar/baz"} {
+		s := ephemeral.NewStore(ephemeral.KeyForResource(id))
+		require.False(t, s.SetWithOptions(ctx, d, body, ephemeral.Options{ChunkSize: 8}).HasError())
+	}
+	all, diags := d.Keys(ctx)
+	require.False(t, diags.HasError())
+	require.Greater(t, len(all), 2)
+	keys, diags = ephemeral.ListKeys(ctx, d, "ephemeral_body")
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{ephemeral.KeyForResource("bar/baz"), ephemeral.KeyForResource("foo")}, keys)
+	keys, diags = ephemeral.ListKeys(ctx, d, ephemeral.KeyForResource("foo"))
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{ephemeral.KeyForResource("foo")}, keys)
+}
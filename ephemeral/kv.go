@@ -0,0 +1,73 @@
+package ephemeral
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// KV is a simple key-value backend keyed by string, e.g. an external store used for cross-resource coordination.
+// It can be used wherever a PrivateData is expected via FromKV. Conversely, the framework's private state
+// (or any PrivateData) can be used as a KV via PrivateDataKV.
+type KV interface {
+	// Get returns the value of the key, or nil if the key doesn't exist.
+	Get(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	// Set sets the non-empty value of the key.
+	Set(ctx context.Context, key string, value []byte) diag.Diagnostics
+	// Delete removes the key. Removing a nonexistent key is not an error.
+	Delete(ctx context.Context, key string) diag.Diagnostics
+}
+
+// FromKV adapts the KV as a PrivateData, so that it can be used by the Store and the package level functions.
+// Setting a key to a nil or zero-length value deletes the key, same as the framework's private state.
+// If the KV implements KeyLister, the returned PrivateData implements it as well.
+func FromKV(kv KV) PrivateData {
+	if _, ok := kv.(KeyLister); ok {
+		return kvListerPrivateData{kvPrivateData{kv}}
+	}
+	return kvPrivateData{kv}
+}
+
+type kvPrivateData struct {
+	kv KV
+}
+
+func (d kvPrivateData) GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics) {
+	return d.kv.Get(ctx, key)
+}
+
+func (d kvPrivateData) SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics {
+	if len(value) == 0 {
+		return d.kv.Delete(ctx, key)
+	}
+	return d.kv.Set(ctx, key, value)
+}
+
+type kvListerPrivateData struct {
+	kvPrivateData
+}
+
+func (d kvListerPrivateData) Keys(ctx context.Context) ([]string, diag.Diagnostics) {
+	return d.kv.(KeyLister).Keys(ctx)
+}
+
+// PrivateDataKV adapts the PrivateData (e.g. the framework's private state) as a KV.
+func PrivateDataKV(d PrivateData) KV {
+	return privateDataKV{d}
+}
+
+type privateDataKV struct {
+	d PrivateData
+}
+
+func (kv privateDataKV) Get(ctx context.Context, key string) ([]byte, diag.Diagnostics) {
+	return kv.d.GetKey(ctx, key)
+}
+
+func (kv privateDataKV) Set(ctx context.Context, key string, value []byte) diag.Diagnostics {
+	return kv.d.SetKey(ctx, key, value)
+}
+
+func (kv privateDataKV) Delete(ctx context.Context, key string) diag.Diagnostics {
+	return kv.d.SetKey(ctx, key, nil)
+}
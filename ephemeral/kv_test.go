@@ -0,0 +1,83 @@
+package ephemeral_test
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+// mapKV is a KV backed by a map, which records the deletions.
+type mapKV struct {
+	data    map[string][]byte
+	deleted []string
+}
+
+func (kv *mapKV) Get(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return kv.data[key], nil
+}
+
+func (kv *mapKV) Set(_ context.Context, key string, value []byte) diag.Diagnostics {
+	kv.data[key] = value
+	return nil
+}
+
+func (kv *mapKV) Delete(_ context.Context, key string) diag.Diagnostics {
+	delete(kv.data, key)
+	kv.deleted = append(kv.deleted, key)
+	return nil
+}
+
+type mapListerKV struct {
+	*mapKV
+}
+
+func (kv mapListerKV) Keys(_ context.Context) ([]string, diag.Diagnostics) {
+	return slices.Sorted(maps.Keys(kv.data)), nil
+}
+
+func TestFromKV(t *testing.T) {
+	ctx := context.Background()
+	kv := &mapKV{data: map[string][]byte{}}
+	d := ephemeral.FromKV(kv)
+
+	body := objectBody(map[string]string{"password": "foo"})
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, body)).HasError())
+	require.Contains(t, kv.data, "ephemeral_body")
+
+	changed, diags := ephemeral.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	require.False(t, ephemeral.Set(ctx, d, nil).HasError())
+	require.Empty(t, kv.data)
+	require.Equal(t, []string{"ephemeral_body"}, kv.deleted)
+
+	// Enumeration is delegated to the KV.
+	_, ok := d.(ephemeral.KeyLister)
+	require.False(t, ok)
+	lkv := mapListerKV{&mapKV{data: map[string][]byte{"a": []byte(`1`)}}}
+	keys, diags := ephemeral.ListKeys(ctx, ephemeral.FromKV(lkv), "")
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{"a"}, keys)
+}
+
+func TestPrivateDataKV(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+	kv := ephemeral.PrivateDataKV(d)
+
+	require.False(t, kv.Set(ctx, "a", []byte(`1`)).HasError())
+	v, diags := kv.Get(ctx, "a")
+	require.False(t, diags.HasError())
+	require.Equal(t, []byte(`1`), v)
+
+	require.False(t, kv.Delete(ctx, "a").HasError())
+	v, diags = d.GetKey(ctx, "a")
+	require.False(t, diags.HasError())
+	require.Nil(t, v)
+}
@@ -0,0 +1,44 @@
+package ephemeral
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// MemoryPrivateData is an in-memory PrivateData, which is mainly used for testing.
+// Similar to the framework's private state, setting a key to a nil or zero-length value removes the key.
+type MemoryPrivateData struct {
+	data map[string][]byte
+}
+
+var _ PrivateData = &MemoryPrivateData{}
+var _ KeyLister = &MemoryPrivateData{}
+
+// NewMemoryPrivateData returns an empty MemoryPrivateData.
+func NewMemoryPrivateData() *MemoryPrivateData {
+	return &MemoryPrivateData{data: map[string][]byte{}}
+}
+
+func (d *MemoryPrivateData) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	v, ok := d.data[key]
+	if !ok {
+		return nil, nil
+	}
+	return slices.Clone(v), nil
+}
+
+func (d *MemoryPrivateData) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	if len(value) == 0 {
+		delete(d.data, key)
+		return nil
+	}
+	d.data[key] = slices.Clone(value)
+	return nil
+}
+
+func (d *MemoryPrivateData) Keys(_ context.Context) ([]string, diag.Diagnostics) {
+	return slices.Sorted(maps.Keys(d.data)), nil
+}
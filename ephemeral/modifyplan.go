@@ -0,0 +1,101 @@
+package ephemeral
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// ModifyPlanConfig configures ModifyPlan.
+type ModifyPlanConfig struct {
+	// Store is the store of the ephemeral body record, defaults to the package level one.
+	Store *Store
+
+	// EphemeralBodyPath is the path of the (write-only) attribute holding the ephemeral body in the config.
+	// Any attribute type is accepted, the value is converted as a dynamic value.
+	EphemeralBodyPath path.Path
+
+	// TriggerPath, if not empty, is the path of a computed attribute that is marked as unknown in the plan
+	// when the ephemeral body changes, so that an update is planned.
+	TriggerPath path.Path
+
+	// RequiresReplace makes the resource replaced when the ephemeral body changes, by adding the EphemeralBodyPath
+	// to the response's RequiresReplace, as the framework's RequiresReplace plan modifier does. The replacement is
+	// not planned while the ephemeral body is unknown, see ModifyPlan.
+	RequiresReplace bool
+}
+
+// ModifyPlan packages the common pattern of a resource's ModifyPlan that reacts to the change of the ephemeral
+// body: it reads the ephemeral body from the config, compares it against the record in the private state
+// (see Diff), and applies the configured effects when it changes.
+//
+// Nothing is done on create (there is no prior state to compare with) or destroy (there is no plan).
+// The decision is deferred while the ephemeral body is unknown: only the trigger attribute is marked as unknown,
+// while the replacement is left to the plan where the ephemeral body is known, rather than planning a replacement
+// for every config whose ephemeral body is computed.
+func ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, cfg ModifyPlanConfig) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	var v attr.Value
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, cfg.EphemeralBodyPath, &v)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ephemeralBody, ok := v.(types.Dynamic)
+	if !ok {
+		ephemeralBody = types.DynamicValue(v)
+	}
+
+	changed := true
+	if !ephemeralBody.IsUnknown() {
+		s := cfg.Store
+		if s == nil {
+			s = defaultStore
+		}
+		var d PrivateData = NewMemoryPrivateData()
+		if req.Private != nil {
+			d = req.Private
+		}
+		var diags diag.Diagnostics
+		changed, diags = s.Diff(ctx, d, ephemeralBody)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if !changed {
+		return
+	}
+
+	if len(cfg.TriggerPath.Steps()) != 0 {
+		typ, diags := req.Plan.Schema.TypeAtPath(ctx, cfg.TriggerPath)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		unknown, err := typ.ValueFromTerraform(ctx, tftypes.NewValue(typ.TerraformType(ctx), tftypes.UnknownValue))
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				cfg.TriggerPath,
+				`Error to build the unknown value of the trigger attribute`,
+				err.Error(),
+			)
+			return
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, cfg.TriggerPath, unknown)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	if cfg.RequiresReplace && !ephemeralBody.IsUnknown() {
+		resp.RequiresReplace = append(resp.RequiresReplace, cfg.EphemeralBodyPath)
+	}
+}
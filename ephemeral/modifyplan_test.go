@@ -0,0 +1,103 @@
+package ephemeral_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModifyPlan(t *testing.T) {
+	ctx := context.Background()
+	sch := schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"body":    schema.DynamicAttribute{Optional: true, WriteOnly: true},
+			"trigger": schema.StringAttribute{Computed: true},
+		},
+	}
+	typ := sch.Type().TerraformType(ctx)
+	obj := func(body tftypes.Value, trigger tftypes.Value) tftypes.Value {
+		return tftypes.NewValue(typ, map[string]tftypes.Value{"body": body, "trigger": trigger})
+	}
+	knownBody := tftypes.NewValue(tftypes.String, "secret")
+	unknownBody := tftypes.NewValue(tftypes.DynamicPseudoType, tftypes.UnknownValue)
+	nullBody := tftypes.NewValue(tftypes.DynamicPseudoType, nil)
+	trigger := tftypes.NewValue(tftypes.String, "t")
+	unknownTrigger := tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+	null := tftypes.NewValue(typ, nil)
+
+	cases := []struct {
+		name            string
+		config          tftypes.Value
+		state           tftypes.Value
+		plan            tftypes.Value
+		expectPlan      tftypes.Value
+		requiresReplace bool
+	}{
+		{
+			name:       "create",
+			config:     obj(knownBody, trigger),
+			state:      null,
+			plan:       obj(knownBody, trigger),
+			expectPlan: obj(knownBody, trigger),
+		},
+		{
+			name:       "destroy",
+			config:     null,
+			state:      obj(nullBody, trigger),
+			plan:       null,
+			expectPlan: null,
+		},
+		{
+			name:            "changed",
+			config:          obj(knownBody, trigger),
+			state:           obj(nullBody, trigger),
+			plan:            obj(knownBody, trigger),
+			expectPlan:      obj(knownBody, unknownTrigger),
+			requiresReplace: true,
+		},
+		{
+			name:       "unchanged",
+			config:     obj(nullBody, trigger),
+			state:      obj(nullBody, trigger),
+			plan:       obj(nullBody, trigger),
+			expectPlan: obj(nullBody, trigger),
+		},
+		{
+			name:       "unknown",
+			config:     obj(unknownBody, trigger),
+			state:      obj(nullBody, trigger),
+			plan:       obj(unknownBody, trigger),
+			expectPlan: obj(unknownBody, unknownTrigger),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := resource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: sch, Raw: tt.config},
+				State:  tfsdk.State{Schema: sch, Raw: tt.state},
+				Plan:   tfsdk.Plan{Schema: sch, Raw: tt.plan},
+			}
+			resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+			ephemeral.ModifyPlan(ctx, req, resp, ephemeral.ModifyPlanConfig{
+				EphemeralBodyPath: path.Root("body"),
+				TriggerPath:       path.Root("trigger"),
+				RequiresReplace:   true,
+			})
+			require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+			require.True(t, tt.expectPlan.Equal(resp.Plan.Raw), resp.Plan.Raw.String())
+			if tt.requiresReplace {
+				require.Equal(t, path.Paths{path.Root("body")}, resp.RequiresReplace)
+			} else {
+				require.Empty(t, resp.RequiresReplace)
+			}
+		})
+	}
+}
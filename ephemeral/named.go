@@ -0,0 +1,150 @@
+package ephemeral
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// SetNamed is similar to Set, while the record is stored as the named entry of the records at the private state
+// key, for the resources having a dynamic set of ephemeral bodies. See Store.SetNamed for details.
+func SetNamed(ctx context.Context, d PrivateData, name string, ebody []byte) diag.Diagnostics {
+	return defaultStore.SetNamed(ctx, d, name, ebody)
+}
+
+// DiffNamed is similar to Diff, while it compares against the named record. See Store.SetNamed for details.
+func DiffNamed(ctx context.Context, d PrivateData, name string, ephemeralBody types.Dynamic) (bool, diag.Diagnostics) {
+	return defaultStore.DiffNamed(ctx, d, name, ephemeralBody)
+}
+
+// GetNullBodyNamed is similar to GetNullBody, while it gets the nullified body of the named record.
+// See Store.SetNamed for details.
+func GetNullBodyNamed(ctx context.Context, d PrivateData, name string) ([]byte, diag.Diagnostics) {
+	return defaultStore.GetNullBodyNamed(ctx, d, name)
+}
+
+// ListNames returns the sorted names of the named records. See Store.SetNamed for details.
+func ListNames(ctx context.Context, d PrivateData) ([]string, diag.Diagnostics) {
+	return defaultStore.ListNames(ctx, d)
+}
+
+// SetNamed is similar to Set, while the record is stored as the named entry of the records at the Store's private
+// state key, i.e. all the named ephemeral bodies share a single private state key. Setting a nil ebody removes only
+// the named entry, leaving the others intact, and the key is removed once no entry is left.
+//
+// A missing name is regarded as no record being stored yet, by DiffNamed and GetNullBodyNamed. The key holding
+// the named records must not be used by the unnamed functions (e.g. Set), and vice versa.
+func (s *Store) SetNamed(ctx context.Context, d PrivateData, name string, ebody []byte) diag.Diagnostics {
+	return s.Set(ctx, namedPrivateData{d: d, key: s.key, name: name}, ebody)
+}
+
+// DiffNamed is similar to Diff, while it compares against the named record. See Store.SetNamed for details.
+func (s *Store) DiffNamed(ctx context.Context, d PrivateData, name string, ephemeralBody types.Dynamic) (bool, diag.Diagnostics) {
+	return s.Diff(ctx, namedPrivateData{d: d, key: s.key, name: name}, ephemeralBody)
+}
+
+// GetNullBodyNamed is similar to GetNullBody, while it gets the nullified body of the named record.
+// See Store.SetNamed for details.
+func (s *Store) GetNullBodyNamed(ctx context.Context, d PrivateData, name string) ([]byte, diag.Diagnostics) {
+	return s.GetNullBody(ctx, namedPrivateData{d: d, key: s.key, name: name})
+}
+
+// ListNames returns the sorted names of the named records, e.g. to remove the records of the ephemeral bodies that
+// are no longer configured. No name is returned if no named record exists.
+func (s *Store) ListNames(ctx context.Context, d PrivateData) ([]string, diag.Diagnostics) {
+	recs, diags := namedPrivateData{d: d, key: s.key}.records(ctx)
+	if diags.HasError() {
+		return nil, diags
+	}
+	return slices.Sorted(maps.Keys(recs.Named)), diags
+}
+
+// namedRecords is the named records stored at a private state key.
+type namedRecords struct {
+	Named map[string]json.RawMessage `json:"named"`
+}
+
+// namedPrivateData is a PrivateData, whose key is mapped to the named entry of the records stored at the key
+// of the underlying PrivateData. The other keys are passed through.
+type namedPrivateData struct {
+	d    PrivateData
+	key  string
+	name string
+}
+
+func (nd namedPrivateData) GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics) {
+	if key != nd.key {
+		return nd.d.GetKey(ctx, key)
+	}
+	recs, diags := nd.records(ctx)
+	if diags.HasError() {
+		return nil, diags
+	}
+	return recs.Named[nd.name], diags
+}
+
+func (nd namedPrivateData) SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics {
+	if key != nd.key {
+		return nd.d.SetKey(ctx, key, value)
+	}
+	recs, diags := nd.records(ctx)
+	if diags.HasError() {
+		return diags
+	}
+	if len(value) == 0 {
+		delete(recs.Named, nd.name)
+	} else {
+		recs.Named[nd.name] = value
+	}
+	if len(recs.Named) == 0 {
+		return append(diags, nd.d.SetKey(ctx, nd.key, nil)...)
+	}
+	b, err := json.Marshal(recs)
+	if err != nil {
+		diags.AddError(
+			`Error to marshal the named ephemeral body private data`,
+			err.Error(),
+		)
+		return diags
+	}
+	return append(diags, nd.d.SetKey(ctx, nd.key, b)...)
+}
+
+// records returns the named records stored at the key, which is empty if the key doesn't exist.
+func (nd namedPrivateData) records(ctx context.Context) (namedRecords, diag.Diagnostics) {
+	recs := namedRecords{Named: map[string]json.RawMessage{}}
+	b, diags := nd.d.GetKey(ctx, nd.key)
+	if diags.HasError() || len(b) == 0 {
+		return recs, diags
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		diags.AddError(
+			`Error to unmarshal the named ephemeral body private data`,
+			err.Error(),
+		)
+		return recs, diags
+	}
+	if _, ok := m["named"]; !ok {
+		diags.AddError(
+			`Invalid named ephemeral body private data`,
+			`The private state key holds an unnamed ephemeral body record`,
+		)
+		return recs, diags
+	}
+	if err := json.Unmarshal(m["named"], &recs.Named); err != nil {
+		diags.AddError(
+			`Error to unmarshal the named ephemeral body private data`,
+			err.Error(),
+		)
+		return recs, diags
+	}
+	if recs.Named == nil {
+		recs.Named = map[string]json.RawMessage{}
+	}
+	return recs, diags
+}
@@ -0,0 +1,75 @@
+package ephemeral_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetNamed(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	creds := objectBody(map[string]string{"password": "foo"})
+	token := objectBody(map[string]string{"token": "bar"})
+
+	// A missing name is regarded as no record stored yet.
+	changed, diags := ephemeral.DiffNamed(ctx, d, "creds", creds)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+	nb, diags := ephemeral.GetNullBodyNamed(ctx, d, "creds")
+	require.False(t, diags.HasError())
+	require.Nil(t, nb)
+
+	require.False(t, ephemeral.SetNamed(ctx, d, "creds", mustToJSON(t, creds)).HasError())
+	require.False(t, ephemeral.SetNamed(ctx, d, "token", mustToJSON(t, token)).HasError())
+
+	keys, diags := d.Keys(ctx)
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{"ephemeral_body"}, keys)
+	names, diags := ephemeral.ListNames(ctx, d)
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{"creds", "token"}, names)
+
+	changed, diags = ephemeral.DiffNamed(ctx, d, "creds", creds)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	changed, diags = ephemeral.DiffNamed(ctx, d, "token", creds)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+	nb, diags = ephemeral.GetNullBodyNamed(ctx, d, "token")
+	require.False(t, diags.HasError())
+	require.JSONEq(t, `{"token": null}`, string(nb))
+
+	// Removing a name leaves the others intact.
+	require.False(t, ephemeral.SetNamed(ctx, d, "creds", nil).HasError())
+	changed, diags = ephemeral.DiffNamed(ctx, d, "creds", types.DynamicNull())
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	changed, diags = ephemeral.DiffNamed(ctx, d, "token", token)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	names, diags = ephemeral.ListNames(ctx, d)
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{"token"}, names)
+
+	// The key is removed once no entry is left.
+	require.False(t, ephemeral.SetNamed(ctx, d, "token", nil).HasError())
+	keys, diags = d.Keys(ctx)
+	require.False(t, diags.HasError())
+	require.Empty(t, keys)
+	names, diags = ephemeral.ListNames(ctx, d)
+	require.False(t, diags.HasError())
+	require.Empty(t, names)
+
+	// The key holding an unnamed record can't be used for the named records.
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, creds)).HasError())
+	_, diags = ephemeral.DiffNamed(ctx, d, "creds", creds)
+	require.True(t, diags.HasError())
+	require.True(t, ephemeral.SetNamed(ctx, d, "creds", mustToJSON(t, creds)).HasError())
+	_, diags = ephemeral.ListNames(ctx, d)
+	require.True(t, diags.HasError())
+}
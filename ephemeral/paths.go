@@ -0,0 +1,172 @@
+package ephemeral
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/dynamic"
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+)
+
+// PathDiff is the result of DiffPaths. The paths are in the dot notation (see Explain), and sorted.
+type PathDiff struct {
+	// Added are the leaf paths that only exist in the ephemeral body.
+	Added []string
+
+	// Removed are the leaf paths that only exist in the record.
+	Removed []string
+
+	// Changed are the leaf paths that exist in both, while the values differ.
+	Changed []string
+}
+
+// DiffPaths is similar to Diff, while it returns the leaf paths that are added, removed, or changed, compared to
+// the record written by SetWithOptions with Options.TrackPaths. Each of them is reported as a separate path, e.g.
+// for a PATCH request of only the changed fields. The leaves are compared semantically, e.g. 1.0 equals 1.
+//
+// In case no record exists, all the paths of the ephemeral body are reported as added. In case the ephemeral
+// body is null, all the recorded paths are reported as removed. It errors if the ephemeral body is unknown, or the
+// record doesn't track the paths. As Diff, a record that is not authentic (see WithHMACKey), or expired (see WithTTL)
+// is regarded as absent.
+func DiffPaths(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (PathDiff, diag.Diagnostics) {
+	return defaultStore.DiffPaths(ctx, d, ephemeralBody)
+}
+
+// DiffPaths returns the changed leaf paths. See the package level DiffPaths for details.
+func (s *Store) DiffPaths(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (PathDiff, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if ephemeralBody.IsUnknown() {
+		diags.AddError(
+			`Error to diff the paths of the ephemeral body`,
+			`The ephemeral body is not known yet`,
+		)
+		return PathDiff{}, diags
+	}
+
+	rec, diags := getRecord(ctx, d, s.key)
+	if diags.HasError() {
+		return PathDiff{}, diags
+	}
+	if rec != nil {
+		authentic, odiags := s.verifyMAC(ctx, d, rec)
+		diags.Append(odiags...)
+		if diags.HasError() {
+			return PathDiff{}, diags
+		}
+		if !authentic || s.expired(rec, s.now()) {
+			rec = nil
+		}
+	}
+	var (
+		old  map[string][]byte
+		salt []byte
+		h    = s.hasher
+	)
+	if rec != nil {
+		if rec.Paths == nil {
+			diags.AddError(
+				`Error to diff the paths of the ephemeral body`,
+				`The ephemeral body record doesn't track the paths, it must be set with the TrackPaths option`,
+			)
+			return PathDiff{}, diags
+		}
+		old, salt = rec.Paths, rec.Salt
+		h = defaultHasher
+		if rec.Version >= pathsHasherRecordVersion {
+			var err error
+			if h, err = s.recordHasher(rec); err != nil {
+				diags.AddError(
+					`Unsupported ephemeral body private data`,
+					err.Error(),
+				)
+				return PathDiff{}, diags
+			}
+		}
+	}
+
+	cur := map[string][]byte{}
+	if !ephemeralBody.IsNull() {
+		ebody, err := dynamic.ToJSON(ephemeralBody)
+		if err != nil {
+			diags.AddError(
+				`Error to marshal the ephemeral body`,
+				err.Error(),
+			)
+			return PathDiff{}, diags
+		}
+		if cur, err = leafHashes(h, ebody, salt); err != nil {
+			diags.AddError(
+				`Error to hash the paths of the ephemeral body`,
+				err.Error(),
+			)
+			return PathDiff{}, diags
+		}
+	}
+
+	var res PathDiff
+	for p, h := range cur {
+		oh, ok := old[p]
+		switch {
+		case !ok:
+			res.Added = append(res.Added, dottedPath(p))
+		case !bytes.Equal(oh, h):
+			res.Changed = append(res.Changed, dottedPath(p))
+		}
+	}
+	for p := range old {
+		if _, ok := cur[p]; !ok {
+			res.Removed = append(res.Removed, dottedPath(p))
+		}
+	}
+	slices.Sort(res.Added)
+	slices.Sort(res.Removed)
+	slices.Sort(res.Changed)
+	return res, diags
+}
+
+// leafHashes returns the salted hashes (by h) of the normalized leaf values of the JSON body, keyed by their JSON
+// pointers. The leaves are the non-object values, and the empty objects.
+func leafHashes(h hasher, ebody, salt []byte) (map[string][]byte, error) {
+	v, err := decodeJSON(ebody)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string][]byte{}
+	if err := collectLeafHashes(h, out, nil, v, salt); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func collectLeafHashes(h hasher, out map[string][]byte, path []string, v interface{}, salt []byte) error {
+	if m, ok := v.(map[string]interface{}); ok && len(m) != 0 {
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			if err := collectLeafHashes(h, out, append(slices.Clip(path), k), m[k], salt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	nb, err := jsonset.Normalize(b)
+	if err != nil {
+		return fmt.Errorf("normalizing %s: %v", jsonset.BuildPointer(path...), err)
+	}
+	pointer := jsonset.BuildPointer(path...)
+	hh := h.new()
+	hh.Write(salt)
+	hh.Write([]byte(pointer))
+	hh.Write([]byte{0})
+	hh.Write(nb)
+	out[pointer] = hh.Sum(nil)
+	return nil
+}
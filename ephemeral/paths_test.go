@@ -0,0 +1,136 @@
+package ephemeral_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha512"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/dynamic"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffPaths(t *testing.T) {
+	ctx := context.Background()
+	body := func(s string) types.Dynamic {
+		d, err := dynamic.FromJSONImplied([]byte(s))
+		require.NoError(t, err)
+		return d
+	}
+	old := `{"auth": {"user": "u", "password": "p"}, "keys": [1, 2], "ttl": 1}`
+
+	d := ephemeral.NewMemoryPrivateData()
+	res, diags := ephemeral.DiffPaths(ctx, d, body(old))
+	require.False(t, diags.HasError())
+	require.Equal(t, ephemeral.PathDiff{Added: []string{"auth.password", "auth.user", "keys", "ttl"}}, res)
+
+	require.False(t, ephemeral.SetWithOptions(ctx, d, []byte(old), ephemeral.Options{TrackPaths: true}).HasError())
+
+	cases := []struct {
+		name   string
+		body   types.Dynamic
+		result ephemeral.PathDiff
+		err    bool
+	}{
+		{
+			name: "unchanged",
+			body: body(`{"ttl": 1.0, "keys": [1, 2], "auth": {"password": "p", "user": "u"}}`),
+		},
+		{
+			name: "added, removed and changed",
+			body: body(`{"auth": {"user": "u", "token": "t"}, "keys": [2, 1], "ttl": 1}`),
+			result: ephemeral.PathDiff{
+				Added:   []string{"auth.token"},
+				Removed: []string{"auth.password"},
+				Changed: []string{"keys"},
+			},
+		},
+		{
+			name:   "null",
+			body:   types.DynamicNull(),
+			result: ephemeral.PathDiff{Removed: []string{"auth.password", "auth.user", "keys", "ttl"}},
+		},
+		{
+			name: "unknown",
+			body: types.DynamicUnknown(),
+			err:  true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, diags := ephemeral.DiffPaths(ctx, d, tt.body)
+			if tt.err {
+				require.True(t, diags.HasError())
+				return
+			}
+			require.False(t, diags.HasError())
+			require.Equal(t, tt.result, res)
+		})
+	}
+
+	// The paths are not tracked
+	require.False(t, ephemeral.Set(ctx, d, []byte(old)).HasError())
+	_, diags = ephemeral.DiffPaths(ctx, d, body(old))
+	require.True(t, diags.HasError())
+}
+
+func TestDiffPathsStore(t *testing.T) {
+	ctx := context.Background()
+	body := func(s string) types.Dynamic {
+		d, err := dynamic.FromJSONImplied([]byte(s))
+		require.NoError(t, err)
+		return d
+	}
+	old := `{"user": "u", "password": "p"}`
+	opts := ephemeral.Options{TrackPaths: true}
+
+	// The paths are hashed with the Store's hash algorithm.
+	s := ephemeral.WithHashFunc(crypto.SHA512)
+	d := ephemeral.NewMemoryPrivateData()
+	require.False(t, s.SetWithOptions(ctx, d, []byte(old), opts).HasError())
+	b, diags := d.GetKey(ctx, ephemeral.DefaultKey)
+	require.False(t, diags.HasError())
+	var rec struct {
+		Paths map[string][]byte `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(b, &rec))
+	require.Len(t, rec.Paths["/password"], sha512.Size)
+	for _, store := range []*ephemeral.Store{s, ephemeral.WithHasher("", nil)} {
+		res, diags := store.DiffPaths(ctx, d, body(`{"user": "u", "password": "q"}`))
+		require.False(t, diags.HasError())
+		require.Equal(t, ephemeral.PathDiff{Changed: []string{"password"}}, res)
+	}
+
+	// The tampered path hash is detected with the HMAC key.
+	s = ephemeral.WithHMACKey([]byte("secret"))
+	d = ephemeral.NewMemoryPrivateData()
+	require.False(t, s.SetWithOptions(ctx, d, []byte(old), opts).HasError())
+	b, diags = d.GetKey(ctx, ephemeral.DefaultKey)
+	require.False(t, diags.HasError())
+	var raw map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &raw))
+	raw["paths"].(map[string]interface{})["/password"] = raw["paths"].(map[string]interface{})["/user"]
+	b, err := json.Marshal(raw)
+	require.NoError(t, err)
+	require.False(t, d.SetKey(ctx, ephemeral.DefaultKey, b).HasError())
+	_, diags = s.DiffPaths(ctx, d, body(old))
+	require.True(t, diags.HasError())
+	require.Equal(t, "Tampered ephemeral body private data", diags.Errors()[0].Summary())
+
+	// The expired record is regarded as absent.
+	now := time.Now()
+	s = ephemeral.WithTTL(time.Hour).WithClock(func() time.Time { return now })
+	d = ephemeral.NewMemoryPrivateData()
+	require.False(t, s.SetWithOptions(ctx, d, []byte(old), opts).HasError())
+	res, diags := s.DiffPaths(ctx, d, body(old))
+	require.False(t, diags.HasError())
+	require.Equal(t, ephemeral.PathDiff{}, res)
+	now = now.Add(2 * time.Hour)
+	res, diags = s.DiffPaths(ctx, d, body(old))
+	require.False(t, diags.HasError())
+	require.Equal(t, ephemeral.PathDiff{Added: []string{"password", "user"}}, res)
+}
@@ -0,0 +1,55 @@
+package ephemeral
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+)
+
+// UseStateWhenUnchanged returns a plan modifier of the ephemeral body attribute, which keeps the planned value as
+// the prior state when the ephemeral body is unchanged. See Store.UseStateWhenUnchanged for details.
+func UseStateWhenUnchanged() planmodifier.Dynamic {
+	return defaultStore.UseStateWhenUnchanged()
+}
+
+// UseStateWhenUnchanged returns a plan modifier of the ephemeral body attribute, which compares the ephemeral body
+// in the config against the record in the private state (see Diff), and keeps the planned value as the prior
+// state when there is no change, to avoid a spurious update. The plan is left as is when it changes.
+//
+// The private state is read from the plan modifier request's Private, which the framework populates with the
+// resource's private state, i.e. the same one that Set writes to in Create/Update (via the response's Private).
+//
+// Nothing is done on create (there is no prior state to compare with), destroy (there is no plan), or when the
+// ephemeral body is unknown.
+func (s *Store) UseStateWhenUnchanged() planmodifier.Dynamic {
+	return useStateWhenUnchanged{store: s}
+}
+
+type useStateWhenUnchanged struct {
+	store *Store
+}
+
+func (m useStateWhenUnchanged) Description(context.Context) string {
+	return "Once the ephemeral body is unchanged, the value of this attribute in state will not change."
+}
+
+func (m useStateWhenUnchanged) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m useStateWhenUnchanged) PlanModifyDynamic(ctx context.Context, req planmodifier.DynamicRequest, resp *planmodifier.DynamicResponse) {
+	if req.Plan.Raw.IsNull() || req.State.Raw.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var d PrivateData = NewMemoryPrivateData()
+	if req.Private != nil {
+		d = req.Private
+	}
+	changed, diags := m.store.Diff(ctx, d, req.ConfigValue)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || changed {
+		return
+	}
+	resp.PlanValue = req.StateValue
+}
@@ -0,0 +1,88 @@
+package ephemeral_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseStateWhenUnchanged(t *testing.T) {
+	ctx := context.Background()
+	sch := schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"body": schema.DynamicAttribute{Optional: true, Sensitive: true},
+		},
+	}
+	typ := sch.Type().TerraformType(ctx)
+	obj := func(body tftypes.Value) tftypes.Value {
+		return tftypes.NewValue(typ, map[string]tftypes.Value{"body": body})
+	}
+	null := tftypes.NewValue(typ, nil)
+	stateValue := types.DynamicValue(types.StringValue("old"))
+
+	cases := []struct {
+		name      string
+		state     tftypes.Value
+		plan      tftypes.Value
+		config    types.Dynamic
+		planValue types.Dynamic
+		expect    types.Dynamic
+	}{
+		{
+			name:      "create",
+			state:     null,
+			plan:      obj(tftypes.NewValue(tftypes.DynamicPseudoType, nil)),
+			config:    types.DynamicNull(),
+			planValue: types.DynamicNull(),
+			expect:    types.DynamicNull(),
+		},
+		{
+			name:      "unchanged",
+			state:     obj(tftypes.NewValue(tftypes.String, "old")),
+			plan:      obj(tftypes.NewValue(tftypes.DynamicPseudoType, nil)),
+			config:    types.DynamicNull(),
+			planValue: types.DynamicNull(),
+			expect:    stateValue,
+		},
+		{
+			name:      "changed",
+			state:     obj(tftypes.NewValue(tftypes.String, "old")),
+			plan:      obj(tftypes.NewValue(tftypes.String, "new")),
+			config:    types.DynamicValue(types.StringValue("new")),
+			planValue: types.DynamicValue(types.StringValue("new")),
+			expect:    types.DynamicValue(types.StringValue("new")),
+		},
+		{
+			name:      "unknown",
+			state:     obj(tftypes.NewValue(tftypes.String, "old")),
+			plan:      obj(tftypes.NewValue(tftypes.DynamicPseudoType, tftypes.UnknownValue)),
+			config:    types.DynamicUnknown(),
+			planValue: types.DynamicUnknown(),
+			expect:    types.DynamicUnknown(),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := planmodifier.DynamicRequest{
+				Path:        path.Root("body"),
+				ConfigValue: tt.config,
+				Plan:        tfsdk.Plan{Schema: sch, Raw: tt.plan},
+				PlanValue:   tt.planValue,
+				State:       tfsdk.State{Schema: sch, Raw: tt.state},
+				StateValue:  stateValue,
+			}
+			resp := &planmodifier.DynamicResponse{PlanValue: req.PlanValue}
+			ephemeral.UseStateWhenUnchanged().PlanModifyDynamic(ctx, req, resp)
+			require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+			require.True(t, tt.expect.Equal(resp.PlanValue), resp.PlanValue.String())
+		})
+	}
+}
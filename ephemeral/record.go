@@ -0,0 +1,236 @@
+package ephemeral
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+)
+
+// recordVersion is the version of the record format written by Set.
+//
+// Version history:
+//   - 0: The unversioned record, only having "hash" and "null".
+//   - 1: The "version" is added. The other fields are all optional additions to the version 0.
+//   - 2: The "hash" of the JSON ephemeral body is calculated on its normalized form. See hashOf.
+//   - 3: The "hash_salt" is added, which salts the "hash" if present. See Options.SaltHash.
+//   - 4: The "mac" covers the whole record, rather than only the "hash" and the nullified body. See Store.mac.
+//   - 5: The "paths" are hashed with the algorithm of the "hash", rather than always SHA-256. See leafHashes.
+const recordVersion = 5
+
+// macRecordVersion is the first version whose "mac" covers the whole record.
+const macRecordVersion = 4
+
+// pathsHasherRecordVersion is the first version whose "paths" are hashed with the algorithm of the "hash".
+const pathsHasherRecordVersion = 5
+
+// record is the ephemeral body record stored in the private state.
+// The []byte fields are marshaled as base64 encoded strings.
+type record struct {
+	// Version is the version of the record format, which is absent (i.e. 0) for the unversioned records.
+	Version int `json:"version,omitempty"`
+
+	// Hash is the hash of the ephemeral body.
+	Hash []byte `json:"hash"`
+
+	// HashSalt is the random salt of Hash, which is only present for the records written with Options.SaltHash.
+	HashSalt []byte `json:"hash_salt,omitempty"`
+
+	// Algorithm is the name of the hash algorithm of Hash. It is absent for the DefaultHashAlgorithm.
+	Algorithm string `json:"alg,omitempty"`
+
+	// Null is the nullified ephemeral body. It is absent for the non-JSON ephemeral body.
+	Null []byte `json:"null,omitempty"`
+
+	// Chunks is the count of the chunks that the nullified body is split into, in which case Null is absent.
+	Chunks int `json:"chunks,omitempty"`
+
+	// ContentType is the content type of the ephemeral body. It is absent for the JSON ephemeral body.
+	ContentType string `json:"content_type,omitempty"`
+
+	// Nonce is the nonce used to encrypt the nullified body (in Null or the chunks), which is only present
+	// for the records written by an encrypted Store.
+	Nonce []byte `json:"nonce,omitempty"`
+
+	// Paths maps the JSON pointer of every leaf of the ephemeral body to the salted hash of the leaf value, which
+	// is only present for the records written with Options.TrackPaths.
+	Paths map[string][]byte `json:"paths,omitempty"`
+
+	// Salt is the random salt of the hashes in Paths.
+	Salt []byte `json:"salt,omitempty"`
+
+	// MAC is the HMAC-SHA256 of the nullified body (as stored) and the hash, which is only present for the records
+	// written by a Store with an HMAC key.
+	MAC []byte `json:"mac,omitempty"`
+
+	// WrittenAt is the time when the record is written. It is absent for the records written by older versions.
+	WrittenAt *time.Time `json:"written_at,omitempty"`
+}
+
+// getRecord gets the record stored in the private state at the key.
+// If it doesn't exist, nil is returned.
+func getRecord(ctx context.Context, d PrivateData, key string) (*record, diag.Diagnostics) {
+	return getRecordRecovering(ctx, d, key, false)
+}
+
+// getRecordRecovering is similar to getRecord. If recoverCorrupt is true, a corrupt record (e.g. truncated by an
+// interrupted write) is regarded as nonexistent, with a warning instead of an error.
+func getRecordRecovering(ctx context.Context, d PrivateData, key string, recoverCorrupt bool) (*record, diag.Diagnostics) {
+	b, diags := d.GetKey(ctx, key)
+	if diags.HasError() {
+		return nil, diags
+	}
+	if b == nil {
+		return nil, diags
+	}
+
+	var rec record
+	err := json.Unmarshal(b, &rec)
+	if err == nil && rec.Hash == nil && recoverCorrupt {
+		err = fmt.Errorf(`key "hash" not found`)
+	}
+	if err == nil && rec.Version > recordVersion {
+		// Not a corruption, a newer provider version must be used instead.
+		diags.AddError(
+			`Unsupported ephemeral body private data`,
+			fmt.Sprintf("The ephemeral body record is of version %d, which is written by a newer version of the provider (supporting up to version %d)", rec.Version, recordVersion),
+		)
+		return nil, diags
+	}
+	if err != nil {
+		if recoverCorrupt {
+			diags.AddWarning(
+				`Corrupt ephemeral body private data is discarded`,
+				fmt.Sprintf("The ephemeral body record is regarded as absent, and will be re-established on the next apply: %v", err),
+			)
+			return nil, diags
+		}
+		diags.AddError(
+			`Error to unmarshal the ephemeral body private data`,
+			err.Error(),
+		)
+		return nil, diags
+	}
+	migrateRecord(&rec)
+	return &rec, diags
+}
+
+// migrateRecord upgrades the record of an older version to the recordVersion in place. The migrated record is
+// persisted on the next Set.
+func migrateRecord(rec *record) {
+	if rec.Version == 0 {
+		// The version 1 is a superset of the version 0.
+		rec.Version = 1
+	}
+}
+
+// setRecord sets the record to the private state at the key.
+func setRecord(ctx context.Context, d PrivateData, key string, rec record) diag.Diagnostics {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError(
+			`Error to marshal the ephemeral body private data`,
+			err.Error(),
+		)
+		return diags
+	}
+	return d.SetKey(ctx, key, b)
+}
+
+// normalizesHash tells whether the hash of the record is calculated on the normalized ephemeral body.
+func (rec *record) normalizesHash() bool {
+	return rec.Version >= 2 && isJSONContentType(rec.ContentType)
+}
+
+// hashOf calculates the hash of the ephemeral body by h, prefixed by the salt, if any. If normalize is set, the hash
+// is calculated on the normalized form (see jsonset.Normalize), so that the semantically equal JSON values (e.g. 5
+// and 5.0) have the same hash. A body failing the normalization is hashed as is.
+func hashOf(h hasher, salt, ebody []byte, normalize bool) []byte {
+	if normalize {
+		if nb, err := jsonset.Normalize(ebody); err == nil {
+			ebody = nb
+		}
+	}
+	hh := h.new()
+	hh.Write(salt)
+	hh.Write(ebody)
+	return hh.Sum(nil)
+}
+
+// rawNullBodyOf returns the nullified body of the record stored at the key, reassembling the chunks if needed.
+// The nullified body is returned as is, i.e. it is still encrypted for the records written by an encrypted Store.
+func rawNullBodyOf(ctx context.Context, d PrivateData, key string, rec *record) ([]byte, diag.Diagnostics) {
+	if rec.Chunks == 0 {
+		return rec.Null, nil
+	}
+	var (
+		nb    []byte
+		diags diag.Diagnostics
+	)
+	for i := range rec.Chunks {
+		b, odiags := d.GetKey(ctx, chunkKey(key, i))
+		diags.Append(odiags...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		if b == nil {
+			diags.AddError(
+				`Invalid ephemeral body private data`,
+				fmt.Sprintf(`Chunk %q not found`, chunkKey(key, i)),
+			)
+			return nil, diags
+		}
+		var chunk []byte
+		if err := json.Unmarshal(b, &chunk); err != nil {
+			diags.AddError(
+				`Error to unmarshal the ephemeral body private data chunk`,
+				err.Error(),
+			)
+			return nil, diags
+		}
+		nb = append(nb, chunk...)
+	}
+	return nb, diags
+}
+
+func chunkKey(key string, i int) string {
+	return fmt.Sprintf("%s.%d", key, i)
+}
+
+// setChunk sets the i-th chunk at the key. The chunk is stored as a base64 encoded JSON string,
+// as the private state value must be valid JSON.
+func setChunk(ctx context.Context, d PrivateData, key string, i int, chunk []byte) diag.Diagnostics {
+	b, err := json.Marshal(chunk)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError(
+			`Error to marshal the ephemeral body private data chunk`,
+			err.Error(),
+		)
+		return diags
+	}
+	return d.SetKey(ctx, chunkKey(key, i), b)
+}
+
+// storedChunks returns the count of the chunks of the record stored at the key.
+// Any error reading the record is ignored, in which case no chunk is assumed.
+func storedChunks(ctx context.Context, d PrivateData, key string) int {
+	rec, diags := getRecord(ctx, d, key)
+	if diags.HasError() || rec == nil {
+		return 0
+	}
+	return rec.Chunks
+}
+
+// removeChunks removes the chunks in the range of [from, to) at the key.
+func removeChunks(ctx context.Context, d PrivateData, key string, from, to int) diag.Diagnostics {
+	var diags diag.Diagnostics
+	for i := from; i < to; i++ {
+		diags.Append(d.SetKey(ctx, chunkKey(key, i), nil)...)
+	}
+	return diags
+}
@@ -0,0 +1,75 @@
+package ephemeral
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// RedactMask is the value that Redact replaces the ephemeral values with.
+const RedactMask = "***"
+
+// Redact returns the JSON payload (e.g. the request body combining the body and the ephemeral body) with every
+// value at a path of the nullified ephemeral body (see GetNullBody) replaced with RedactMask, so that it is safe
+// to be logged. The other values are left intact.
+//
+// Objects are redacted recursively by their common keys, and arrays element-wise by their common indexes. A path
+// of the nullified body that is absent from the payload is skipped. Once the nullified body has a leaf (i.e. null)
+// at a path, or the payload has a different type of value there, the whole payload value at that path is masked.
+// A nil nullBody (e.g. no record stored) leaves the payload as is. The result is in the compact form, with the
+// object keys sorted.
+func Redact(payload []byte, nullBody []byte) ([]byte, error) {
+	pv, err := decodeJSON(payload)
+	if err != nil {
+		return nil, fmt.Errorf("JSON unmarshal payload: %v", err)
+	}
+	if nullBody == nil {
+		return json.Marshal(pv)
+	}
+	nv, err := decodeJSON(nullBody)
+	if err != nil {
+		return nil, fmt.Errorf("JSON unmarshal null body: %v", err)
+	}
+	return json.Marshal(redactValue(pv, nv))
+}
+
+func redactValue(pv, nv interface{}) interface{} {
+	switch nv := nv.(type) {
+	case map[string]interface{}:
+		pm, ok := pv.(map[string]interface{})
+		if !ok {
+			return RedactMask
+		}
+		for k, v := range nv {
+			if pmv, ok := pm[k]; ok {
+				pm[k] = redactValue(pmv, v)
+			}
+		}
+		return pm
+	case []interface{}:
+		pa, ok := pv.([]interface{})
+		if !ok {
+			return RedactMask
+		}
+		for i := 0; i < min(len(pa), len(nv)); i++ {
+			pa[i] = redactValue(pa[i], nv[i])
+		}
+		return pa
+	default:
+		return RedactMask
+	}
+}
+
+// decodeJSON decodes the single JSON value, keeping the numbers as is.
+func decodeJSON(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid character after top-level value")
+	}
+	return v, nil
+}
@@ -0,0 +1,76 @@
+package ephemeral_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	cases := []struct {
+		name     string
+		payload  string
+		nullBody string
+		result   string
+		err      bool
+	}{
+		{
+			name:     "invalid payload",
+			payload:  `{`,
+			nullBody: `{}`,
+			err:      true,
+		},
+		{
+			name:    "no null body",
+			payload: `{"name": "x", "n": 1.50}`,
+			result:  `{"n":1.50,"name":"x"}`,
+		},
+		{
+			name:     "nested",
+			payload:  `{"name": "x", "props": {"sku": "basic", "password": "secret", "cert": {"data": "y"}}}`,
+			nullBody: `{"props": {"password": null, "cert": null, "missing": null}}`,
+			result:   `{"name":"x","props":{"cert":"***","password":"***","sku":"basic"}}`,
+		},
+		{
+			name:     "arrays",
+			payload:  `{"creds": [{"user": "a", "password": "p1"}, {"user": "b", "password": "p2"}], "tokens": ["t1", "t2"]}`,
+			nullBody: `{"creds": [{"password": null}], "tokens": [null, null, null]}`,
+			result:   `{"creds":[{"password":"***","user":"a"},{"password":"p2","user":"b"}],"tokens":["***","***"]}`,
+		},
+		{
+			name:     "type mismatch",
+			payload:  `{"secret": "x"}`,
+			nullBody: `{"secret": {"a": null}}`,
+			result:   `{"secret":"***"}`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var nullBody []byte
+			if tt.nullBody != "" {
+				nullBody = []byte(tt.nullBody)
+			}
+			result, err := ephemeral.Redact([]byte(tt.payload), nullBody)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.result, string(result))
+		})
+	}
+}
+
+func TestRedactWithNullBody(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+	require.False(t, ephemeral.Set(ctx, d, []byte(`{"props": {"password": "secret"}}`)).HasError())
+	nb, diags := ephemeral.GetNullBody(ctx, d)
+	require.False(t, diags.HasError())
+
+	result, err := ephemeral.Redact([]byte(`{"name": "x", "props": {"sku": "basic", "password": "secret"}}`), nb)
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"x","props":{"password":"***","sku":"basic"}}`, string(result))
+}
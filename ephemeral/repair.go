@@ -0,0 +1,71 @@
+package ephemeral
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+)
+
+// Repair back-fills the nullified body of the legacy record that only has the "hash" (e.g. written by an early
+// version), so that GetNullBody (and the functions based on it) works for it, without waiting for the next Set.
+// The ebody is the current JSON ephemeral body, e.g. marshaled by dynamic.ToJSON on read. The record is only
+// repaired if its hash still matches the ebody, in which case the Diff result is unchanged, as the hash is kept.
+// Otherwise, or if there is nothing to repair (e.g. no record, a non-JSON content type, or the nullified body is
+// already present), it is a no-op, leaving the record to be re-established by Set on the next apply.
+func Repair(ctx context.Context, d PrivateData, ebody []byte) diag.Diagnostics {
+	return defaultStore.Repair(ctx, d, ebody)
+}
+
+// Repair back-fills the nullified body of the legacy record. See the package level Repair for details.
+func (s *Store) Repair(ctx context.Context, d PrivateData, ebody []byte) diag.Diagnostics {
+	rec, diags := getRecord(ctx, d, s.key)
+	if diags.HasError() || rec == nil {
+		return diags
+	}
+	if rec.Hash == nil || rec.Null != nil || rec.Chunks != 0 || !isJSONContentType(rec.ContentType) || len(ebody) == 0 {
+		return diags
+	}
+
+	// An unauthentic record is regarded as changed by Diff, which is left as is.
+	authentic, odiags := s.verifyMAC(ctx, d, rec)
+	diags.Append(odiags...)
+	if diags.HasError() || !authentic {
+		return diags
+	}
+
+	h, err := s.recordHasher(rec)
+	if err != nil {
+		diags.AddError(
+			`Unsupported ephemeral body private data`,
+			err.Error(),
+		)
+		return diags
+	}
+	if !bytes.Equal(hashOf(h, rec.HashSalt, ebody, rec.normalizesHash()), rec.Hash) {
+		return diags
+	}
+
+	nb, err := jsonset.NullifyObject(ebody)
+	if err != nil {
+		diags.AddError(
+			`Error to nullify the ephemeral body`,
+			err.Error(),
+		)
+		return diags
+	}
+	if s.encrypted() {
+		nb, rec.Nonce, err = s.seal(nb)
+		if err != nil {
+			diags.AddError(
+				`Error to encrypt the nullified ephemeral body`,
+				err.Error(),
+			)
+			return diags
+		}
+	}
+	rec.Null = nb
+	rec.MAC = s.mac(*rec, nb)
+	return append(diags, setRecord(ctx, d, s.key, *rec)...)
+}
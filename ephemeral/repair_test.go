@@ -0,0 +1,96 @@
+package ephemeral_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepair(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo"})
+	other := objectBody(map[string]string{"password": "bar"})
+
+	// legacy writes the hash-only record of body by the store.
+	legacy := func(t *testing.T, store *ephemeral.Store) ephemeral.PrivateData {
+		d := ephemeral.NewMemoryPrivateData()
+		require.False(t, store.Set(ctx, d, mustToJSON(t, body)).HasError())
+		b, diags := d.GetKey(ctx, "ephemeral_body")
+		require.False(t, diags.HasError())
+		var rec map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(b, &rec))
+		b, err := json.Marshal(map[string]json.RawMessage{"hash": rec["hash"]})
+		require.NoError(t, err)
+		require.False(t, d.SetKey(ctx, "ephemeral_body", b).HasError())
+		return d
+	}
+
+	cases := []struct {
+		name  string
+		store *ephemeral.Store
+	}{
+		{
+			name:  "default",
+			store: ephemeral.WithHasher("", nil),
+		},
+		{
+			name:  "encrypted",
+			store: ephemeral.NewEncryptedStore([]byte("0123456789abcdef0123456789abcdef"), nil),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := legacy(t, tt.store)
+			nb, diags := tt.store.GetNullBody(ctx, d)
+			require.False(t, diags.HasError())
+			require.Nil(t, nb)
+
+			// A mismatched ebody doesn't repair the record.
+			require.False(t, tt.store.Repair(ctx, d, mustToJSON(t, other)).HasError())
+			nb, diags = tt.store.GetNullBody(ctx, d)
+			require.False(t, diags.HasError())
+			require.Nil(t, nb)
+
+			require.False(t, tt.store.Repair(ctx, d, mustToJSON(t, body)).HasError())
+			nb, diags = tt.store.GetNullBody(ctx, d)
+			require.False(t, diags.HasError())
+			require.JSONEq(t, `{"password": null}`, string(nb))
+
+			changed, diags := tt.store.Diff(ctx, d, body)
+			require.False(t, diags.HasError())
+			require.False(t, changed)
+			changed, diags = tt.store.Diff(ctx, d, other)
+			require.False(t, diags.HasError())
+			require.True(t, changed)
+
+			// Repairing the repaired record is a no-op.
+			b, diags := d.GetKey(ctx, "ephemeral_body")
+			require.False(t, diags.HasError())
+			require.False(t, tt.store.Repair(ctx, d, mustToJSON(t, body)).HasError())
+			nb2, diags := d.GetKey(ctx, "ephemeral_body")
+			require.False(t, diags.HasError())
+			require.Equal(t, b, nb2)
+		})
+	}
+
+	// An unauthenticated record is regarded as changed by a Store with an HMAC key, which is not repaired.
+	store := ephemeral.WithHMACKey([]byte("secret"))
+	d := legacy(t, ephemeral.WithHasher("", nil))
+	require.False(t, store.Repair(ctx, d, mustToJSON(t, body)).HasError())
+	nb, diags := store.GetNullBody(ctx, d)
+	require.False(t, diags.HasError())
+	require.Nil(t, nb)
+	changed, diags := store.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	// No record is a no-op.
+	d = ephemeral.NewMemoryPrivateData()
+	require.False(t, ephemeral.Repair(ctx, d, mustToJSON(t, body)).HasError())
+	exists, diags := ephemeral.Exists(ctx, d)
+	require.False(t, diags.HasError())
+	require.False(t, exists)
+}
@@ -0,0 +1,70 @@
+package ephemeral
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxResourceIDLen is the max length of the resource ID that is embedded into the key as is.
+	maxResourceIDLen = 64
+)
+
+// DefaultKey is the private state key of the record of the package level functions.
+const DefaultKey = pkEphemeralBody
+
+// New returns a Store that stores the ephemeral body record at the private state key. This allows a resource to
+// track several independent ephemeral bodies, e.g. New("ephemeral_body") and New("ephemeral_headers"), or one per
+// sub-resource via KeyForResource. The key must not be empty, nor start with "." (reserved by the framework).
+func New(key string) (*Store, error) {
+	if key == "" {
+		return nil, fmt.Errorf("empty private state key")
+	}
+	if strings.HasPrefix(key, ".") {
+		return nil, fmt.Errorf("private state key %q is reserved", key)
+	}
+	return &Store{key: key, hasher: defaultHasher}, nil
+}
+
+// NewStore is similar to New, while it panics on an invalid key. It is meant for the keys that are constants.
+func NewStore(key string) *Store {
+	s, err := New(key)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Key returns the private state key of the record.
+func (s *Store) Key() string {
+	return s.key
+}
+
+// KeyForResource derives a private state key scoped to the resource ID, which is free of the special characters
+// and bounded in length, regardless of the ID. The derivation is stable across runs (and versions):
+//   - An ID of at most 64 characters of [A-Za-z0-9_-] is embedded as is: "ephemeral_body:" + id
+//   - Otherwise, the ID is hashed: "ephemeral_body#" + hex(sha256(id))
+//
+// The two forms use different separators, so the derived keys never collide with each other, nor with the
+// chunk keys (see Options.ChunkSize). All the derived keys can be listed via ListKeys with the "ephemeral_body:"
+// and "ephemeral_body#" prefixes, or a specific one with the derived key as the prefix.
+func KeyForResource(id string) string {
+	if len(id) != 0 && len(id) <= maxResourceIDLen && isSafeResourceID(id) {
+		return pkEphemeralBody + ":" + id
+	}
+	h := sha256.Sum256([]byte(id))
+	return pkEphemeralBody + "#" + hex.EncodeToString(h[:])
+}
+
+func isSafeResourceID(id string) bool {
+	for _, c := range []byte(id) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package ephemeral_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyForResource(t *testing.T) {
+	cases := []struct {
+		id  string
+		key string
+	}{
+		{id: "vm-1", key: "ephemeral_body:vm-1"},
+		{id: "VM_2", key: "ephemeral_body:VM_2"},
+		{id: "", key: "ephemeral_body#e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{id: "/subscriptions/x/resourceGroups/y", key: "ephemeral_body#62e6e134dcfeddf083d12983ddc536774b041f8843f1bbb0b158a939b3cb6fa5"},
+		{id: "a.0", key: "ephemeral_body#6f632802d53103a2d53a2e1db95e32b6376c0ef53e6df0f16896f0f24210cd4a"},
+		{id: strings.Repeat("a", 65), key: "ephemeral_body#635361c48bb9eab14198e76ea8ab7f1a41685d6ad62aa9146d301d4f17eb0ae0"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.id, func(t *testing.T) {
+			require.Equal(t, tt.key, ephemeral.KeyForResource(tt.id))
+		})
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	s1 := ephemeral.NewStore(ephemeral.KeyForResource("vm-1"))
+	s2 := ephemeral.NewStore(ephemeral.KeyForResource("/vms/2"))
+	require.Equal(t, "ephemeral_body:vm-1", s1.Key())
+
+	body1 := objectBody(map[string]string{"password": "foo"})
+	body2 := objectBody(map[string]string{"password": "bar"})
+	require.False(t, s1.SetWithOptions(ctx, d, mustToJSON(t, body1), ephemeral.Options{ChunkSize: 8}).HasError())
+	require.False(t, s2.Set(ctx, d, mustToJSON(t, body2)).HasError())
+
+	changed, diags := s1.Diff(ctx, d, body1)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	changed, diags = s2.Diff(ctx, d, body1)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	exists, diags := ephemeral.Exists(ctx, d)
+	require.False(t, diags.HasError())
+	require.False(t, exists)
+
+	keys, diags := ephemeral.ListKeys(ctx, d, "ephemeral_body#")
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{s2.Key()}, keys)
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		key string
+		err bool
+	}{
+		{key: ephemeral.DefaultKey},
+		{key: "ephemeral_headers"},
+		{key: "", err: true},
+		{key: ".reserved", err: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.key, func(t *testing.T) {
+			s, err := ephemeral.New(tt.key)
+			if tt.err {
+				require.Error(t, err)
+				require.Panics(t, func() { ephemeral.NewStore(tt.key) })
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.key, s.Key())
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package ephemeral
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// Snapshot is a copy of all the keys and values of a private data, taken by TakeSnapshot.
+type Snapshot struct {
+	data map[string][]byte
+}
+
+// Keys returns the sorted keys in the snapshot.
+func (s Snapshot) Keys() []string {
+	return slices.Sorted(maps.Keys(s.data))
+}
+
+// TakeSnapshot copies all the keys and values of the private data, which can be restored later via Restore.
+// It is meant for testing the multi-step resource lifecycles (e.g. create/update/delete sequences), by restoring
+// the private data between the sub-tests.
+//
+// It only works with the private data that can enumerate its keys (i.e. implements KeyLister, like
+// MemoryPrivateData), an error is returned otherwise.
+func TakeSnapshot(ctx context.Context, d PrivateData) (Snapshot, diag.Diagnostics) {
+	l, ok := d.(KeyLister)
+	if !ok {
+		var diags diag.Diagnostics
+		diags.AddError(
+			`Error to take the snapshot of the private data`,
+			`The private data can't enumerate its keys`,
+		)
+		return Snapshot{}, diags
+	}
+	keys, diags := l.Keys(ctx)
+	if diags.HasError() {
+		return Snapshot{}, diags
+	}
+	snap := Snapshot{data: map[string][]byte{}}
+	for _, k := range keys {
+		v, odiags := d.GetKey(ctx, k)
+		diags.Append(odiags...)
+		if diags.HasError() {
+			return Snapshot{}, diags
+		}
+		if v != nil {
+			snap.data[k] = slices.Clone(v)
+		}
+	}
+	return snap, diags
+}
+
+// Restore restores the private data to the snapshot: the keys absent from the snapshot are removed, and the others
+// are set to the values in the snapshot. Same as TakeSnapshot, the private data must implement KeyLister.
+func Restore(ctx context.Context, d PrivateData, snap Snapshot) diag.Diagnostics {
+	l, ok := d.(KeyLister)
+	if !ok {
+		var diags diag.Diagnostics
+		diags.AddError(
+			`Error to restore the snapshot of the private data`,
+			`The private data can't enumerate its keys`,
+		)
+		return diags
+	}
+	keys, diags := l.Keys(ctx)
+	if diags.HasError() {
+		return diags
+	}
+	for _, k := range keys {
+		if _, ok := snap.data[k]; ok {
+			continue
+		}
+		diags.Append(d.SetKey(ctx, k, nil)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+	for _, k := range snap.Keys() {
+		diags.Append(d.SetKey(ctx, k, slices.Clone(snap.data[k]))...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+	return diags
+}
@@ -0,0 +1,63 @@
+package ephemeral_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	foo := objectBody(map[string]string{"password": "foo"})
+	bar := objectBody(map[string]string{"password": "bar"})
+	headers := ephemeral.NewStore("ephemeral_headers")
+
+	// create
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, foo)).HasError())
+	snap, diags := ephemeral.TakeSnapshot(ctx, d)
+	require.False(t, diags.HasError())
+	require.Equal(t, []string{ephemeral.DefaultKey}, snap.Keys())
+
+	cases := []struct {
+		name string
+		step func(t *testing.T)
+	}{
+		{
+			name: "update",
+			step: func(t *testing.T) {
+				require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, bar)).HasError())
+				require.False(t, headers.Set(ctx, d, mustToJSON(t, foo)).HasError())
+			},
+		},
+		{
+			name: "delete",
+			step: func(t *testing.T) {
+				require.False(t, ephemeral.Set(ctx, d, nil).HasError())
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.step(t)
+			require.False(t, ephemeral.Restore(ctx, d, snap).HasError())
+
+			changed, diags := ephemeral.Diff(ctx, d, foo)
+			require.False(t, diags.HasError())
+			require.False(t, changed)
+			changed, diags = headers.Diff(ctx, d, types.DynamicNull())
+			require.False(t, diags.HasError())
+			require.False(t, changed)
+		})
+	}
+
+	// Not enumerable
+	notLister := struct{ ephemeral.PrivateData }{d}
+	_, diags = ephemeral.TakeSnapshot(ctx, notLister)
+	require.True(t, diags.HasError())
+	require.True(t, ephemeral.Restore(ctx, notLister, snap).HasError())
+}
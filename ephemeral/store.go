@@ -0,0 +1,135 @@
+package ephemeral
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// Store stores the ephemeral body record in the private state. The package level functions (e.g. Set, Diff)
+// operate on a default Store, which stores the record at the "ephemeral_body" key without encryption.
+type Store struct {
+	// key is the private state key of the record.
+	key string
+
+	// master and kdf are used to derive the encryption key of the nullified body. No encryption if kdf is nil.
+	master []byte
+	kdf    func(master []byte, keyName string) []byte
+
+	// hmacKey is the key to authenticate the record. No authentication if it is nil.
+	hmacKey []byte
+
+	// hasher is the hash algorithm of the ephemeral body.
+	hasher hasher
+
+	// ttl is the duration after which the record expires. No expiration if it is zero.
+	ttl time.Duration
+
+	// clock returns the current time, defaults to time.Now if it is nil.
+	clock func() time.Time
+}
+
+var defaultStore = &Store{key: pkEphemeralBody, hasher: defaultHasher}
+
+// NewEncryptedStore returns a Store that encrypts the nullified ephemeral body (which reveals the structure of
+// the ephemeral body) with AES-GCM, using a random nonce stored in the record. The AES key is derived from
+// the master secret and the private state key name by kdf, which must return a 16, 24 or 32 bytes key.
+// If kdf is nil, HKDF-SHA256 is used to derive a 32 bytes key, with the key name as the info.
+//
+// Diff only compares the hash, hence doesn't need the key. While DiffKind, Explain and GetNullBody decrypt the
+// nullified body with the same derivation, and error if the record can't be decrypted (e.g. the master secret
+// changed, or the record isn't encrypted).
+func NewEncryptedStore(master []byte, kdf func(master []byte, keyName string) []byte) *Store {
+	if kdf == nil {
+		kdf = hkdfSHA256
+	}
+	return &Store{
+		key:    pkEphemeralBody,
+		master: master,
+		kdf:    kdf,
+		hasher: defaultHasher,
+	}
+}
+
+func hkdfSHA256(master []byte, keyName string) []byte {
+	// The HKDF only errors on an invalid key length, which can't happen here.
+	key, _ := hkdf.Key(sha256.New, master, nil, keyName, 32)
+	return key
+}
+
+func (s *Store) encrypted() bool {
+	return s.kdf != nil
+}
+
+func (s *Store) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.kdf(s.master, s.key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid derived key: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the plaintext with a random nonce, which is returned together with the ciphertext.
+// The key name is used as the additional data, which binds the ciphertext to the key.
+func (s *Store) seal(plaintext []byte) ([]byte, []byte, error) {
+	aead, err := s.aead()
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	return aead.Seal(nil, nonce, plaintext, []byte(s.key)), nonce, nil
+}
+
+func (s *Store) open(ciphertext, nonce []byte) ([]byte, error) {
+	aead, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce size %d", len(nonce))
+	}
+	return aead.Open(nil, nonce, ciphertext, []byte(s.key))
+}
+
+// nullBodyOf returns the nullified body of the record, reassembling the chunks and decrypting it if needed.
+func (s *Store) nullBodyOf(ctx context.Context, d PrivateData, rec *record) ([]byte, diag.Diagnostics) {
+	nb, diags := rawNullBodyOf(ctx, d, s.key, rec)
+	if diags.HasError() || nb == nil {
+		return nb, diags
+	}
+	switch {
+	case rec.Nonce == nil && !s.encrypted():
+		return nb, diags
+	case rec.Nonce == nil:
+		diags.AddError(
+			`Invalid ephemeral body private data`,
+			`The nullified body is not encrypted, while the store requires encryption`,
+		)
+		return nil, diags
+	case !s.encrypted():
+		diags.AddError(
+			`Invalid ephemeral body private data`,
+			`The nullified body is encrypted, while the store has no encryption key`,
+		)
+		return nil, diags
+	}
+	nb, err := s.open(nb, rec.Nonce)
+	if err != nil {
+		diags.AddError(
+			`Error to decrypt the nullified ephemeral body`,
+			err.Error(),
+		)
+		return nil, diags
+	}
+	return nb, diags
+}
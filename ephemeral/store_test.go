@@ -0,0 +1,74 @@
+package ephemeral_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedStore(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo"})
+
+	for _, opts := range []ephemeral.Options{{}, {ChunkSize: 8}} {
+		d := ephemeral.NewMemoryPrivateData()
+		store := ephemeral.NewEncryptedStore([]byte("master"), nil)
+		require.False(t, store.SetWithOptions(ctx, d, mustToJSON(t, body), opts).HasError())
+
+		// The nullified body isn't stored in plain text.
+		keys, diags := d.Keys(ctx)
+		require.False(t, diags.HasError())
+		for _, k := range keys {
+			b, diags := d.GetKey(ctx, k)
+			require.False(t, diags.HasError())
+			require.NotContains(t, string(b), "password")
+			require.True(t, json.Valid(b))
+		}
+
+		nb, diags := store.GetNullBody(ctx, d)
+		require.False(t, diags.HasError())
+		require.JSONEq(t, `{"password": null}`, string(nb))
+
+		changed, diags := store.Diff(ctx, d, body)
+		require.False(t, diags.HasError())
+		require.False(t, changed)
+
+		kind, diags := store.DiffKind(ctx, d, objectBody(map[string]string{"token": "foo"}))
+		require.False(t, diags.HasError())
+		require.Equal(t, ephemeral.StructuralChange, kind)
+
+		// Different master secret
+		_, diags = ephemeral.NewEncryptedStore([]byte("other"), nil).GetNullBody(ctx, d)
+		require.True(t, diags.HasError())
+
+		// No encryption
+		_, diags = ephemeral.GetNullBody(ctx, d)
+		require.True(t, diags.HasError())
+	}
+}
+
+func TestEncryptedStoreKDF(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	var keyNames []string
+	kdf := func(master []byte, keyName string) []byte {
+		keyNames = append(keyNames, keyName)
+		key := make([]byte, 16)
+		copy(key, master)
+		return key
+	}
+	store := ephemeral.NewEncryptedStore([]byte("master"), kdf)
+	require.False(t, store.Set(ctx, d, mustToJSON(t, objectBody(map[string]string{"password": "foo"}))).HasError())
+	nb, diags := store.GetNullBody(ctx, d)
+	require.False(t, diags.HasError())
+	require.JSONEq(t, `{"password": null}`, string(nb))
+	require.Equal(t, []string{"ephemeral_body", "ephemeral_body"}, keyNames)
+
+	// Invalid derived key
+	store = ephemeral.NewEncryptedStore([]byte("master"), func([]byte, string) []byte { return []byte("short") })
+	require.True(t, store.Set(ctx, d, mustToJSON(t, objectBody(map[string]string{"password": "foo"}))).HasError())
+}
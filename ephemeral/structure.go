@@ -0,0 +1,103 @@
+package ephemeral
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+)
+
+// StructuralDiff is the result of DiffStructure. The paths are in the dot notation (see Explain), in the depth-first
+// order with the keys sorted.
+type StructuralDiff struct {
+	// Changed is the same as what Diff returns.
+	Changed bool
+
+	// Added are the paths that only exist in the ephemeral body.
+	Added []string
+
+	// Removed are the paths that only exist in the recorded ephemeral body.
+	Removed []string
+
+	// Replaced are the paths that exist in both, while one is an object and the other is not, e.g. a string
+	// attribute turns into a nested object.
+	Replaced []string
+
+	// StructureUnknown tells that the structure is not compared, as the ephemeral body is unknown, or the record
+	// has no nullified body (e.g. written before the nullified body is recorded, or of a non-JSON content type).
+	// No path is reported then, which is different from a change of only the values.
+	StructureUnknown bool
+}
+
+// DiffStructure is similar to Diff, while it also reports which paths are added, removed or replaced, by comparing
+// the nullified ephemeral body with the one recorded, e.g. to be put into a plan diagnostic or a log. As for Explain,
+// only the paths are reported, the values (secrets) are never included, and a change of only the values reports no
+// path while Changed is true. In case the ephemeral body is unknown, or the record has no nullified body (e.g. a
+// legacy hash-only record, see Repair), the structure can't be compared, which is reported by StructureUnknown.
+//
+// In case no record exists, the top level paths of the ephemeral body are reported as added. In case the ephemeral
+// body is null, the top level paths of the recorded one are reported as removed.
+func DiffStructure(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (StructuralDiff, diag.Diagnostics) {
+	return defaultStore.DiffStructure(ctx, d, ephemeralBody)
+}
+
+// DiffStructure is similar to Diff, while it also reports the changed paths. See the package level DiffStructure
+// for details.
+func (s *Store) DiffStructure(ctx context.Context, d PrivateData, ephemeralBody types.Dynamic) (StructuralDiff, diag.Diagnostics) {
+	changed, diags := s.Diff(ctx, d, ephemeralBody)
+	if diags.HasError() {
+		return StructuralDiff{}, diags
+	}
+	res := StructuralDiff{Changed: changed}
+	if !changed {
+		return res, diags
+	}
+	if ephemeralBody.IsUnknown() {
+		res.StructureUnknown = true
+		return res, diags
+	}
+
+	rec, odiags := getRecord(ctx, d, s.key)
+	diags.Append(odiags...)
+	if diags.HasError() {
+		return StructuralDiff{}, diags
+	}
+	nb := []byte(`{}`)
+	if rec != nil {
+		if !isJSONContentType(rec.ContentType) {
+			res.StructureUnknown = true
+			return res, diags
+		}
+		nb, odiags = s.nullBodyOf(ctx, d, rec)
+		diags.Append(odiags...)
+		if diags.HasError() {
+			return StructuralDiff{}, diags
+		}
+		if nb == nil {
+			res.StructureUnknown = true
+			return res, diags
+		}
+	}
+
+	changes, err := structuralChanges(nb, ephemeralBody)
+	if err != nil {
+		diags.AddError(
+			`Error to compare the structure of the ephemeral body`,
+			err.Error(),
+		)
+		return StructuralDiff{}, diags
+	}
+	for _, c := range changes {
+		p := dottedPath(c.Path)
+		switch c.Type {
+		case jsonset.ChangeAdd:
+			res.Added = append(res.Added, p)
+		case jsonset.ChangeRemove:
+			res.Removed = append(res.Removed, p)
+		case jsonset.ChangeReplace:
+			res.Replaced = append(res.Replaced, p)
+		}
+	}
+	return res, diags
+}
@@ -0,0 +1,96 @@
+package ephemeral_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStructure(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo", "token": "bar"})
+	nested := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{
+			"password": types.StringType,
+			"token":    types.ObjectType{AttrTypes: map[string]attr.Type{"value": types.StringType}},
+		},
+		map[string]attr.Value{
+			"password": types.StringValue("foo"),
+			"token": types.ObjectValueMust(
+				map[string]attr.Type{"value": types.StringType},
+				map[string]attr.Value{"value": types.StringValue("secret")},
+			),
+		},
+	))
+
+	d := ephemeral.NewMemoryPrivateData()
+	diff := func(ebody types.Dynamic) ephemeral.StructuralDiff {
+		res, diags := ephemeral.DiffStructure(ctx, d, ebody)
+		require.False(t, diags.HasError())
+		return res
+	}
+
+	// No record
+	require.Equal(t, ephemeral.StructuralDiff{Changed: true, Added: []string{"password", "token"}}, diff(body))
+	require.Equal(t, ephemeral.StructuralDiff{}, diff(types.DynamicNull()))
+
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, body)).HasError())
+
+	cases := []struct {
+		name   string
+		ebody  types.Dynamic
+		result ephemeral.StructuralDiff
+	}{
+		{
+			name:   "Unchanged",
+			ebody:  body,
+			result: ephemeral.StructuralDiff{},
+		},
+		{
+			name:   "Value changed only",
+			ebody:  objectBody(map[string]string{"password": "secret", "token": "bar"}),
+			result: ephemeral.StructuralDiff{Changed: true},
+		},
+		{
+			name:   "Added and removed",
+			ebody:  objectBody(map[string]string{"password": "foo", "a/b": "secret"}),
+			result: ephemeral.StructuralDiff{Changed: true, Added: []string{"a/b"}, Removed: []string{"token"}},
+		},
+		{
+			name:   "Replaced",
+			ebody:  nested,
+			result: ephemeral.StructuralDiff{Changed: true, Replaced: []string{"token"}},
+		},
+		{
+			name:   "Unknown",
+			ebody:  types.DynamicUnknown(),
+			result: ephemeral.StructuralDiff{Changed: true, StructureUnknown: true},
+		},
+		{
+			name:   "Null",
+			ebody:  types.DynamicNull(),
+			result: ephemeral.StructuralDiff{Changed: true, Removed: []string{"password", "token"}},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.result, diff(tt.ebody))
+		})
+	}
+
+	// The legacy hash-only record
+	b, diags := d.GetKey(ctx, "ephemeral_body")
+	require.False(t, diags.HasError())
+	var rec map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(b, &rec))
+	b, err := json.Marshal(map[string]json.RawMessage{"hash": rec["hash"]})
+	require.NoError(t, err)
+	require.False(t, d.SetKey(ctx, "ephemeral_body", b).HasError())
+	require.Equal(t, ephemeral.StructuralDiff{}, diff(body))
+	require.Equal(t, ephemeral.StructuralDiff{Changed: true, StructureUnknown: true}, diff(nested))
+}
@@ -0,0 +1,57 @@
+package ephemeral
+
+import (
+	"time"
+)
+
+// WithTTL returns a copy of the default Store whose records expire after ttl. See Store.WithTTL for details.
+func WithTTL(ttl time.Duration) *Store {
+	return defaultStore.WithTTL(ttl)
+}
+
+// WithTTL returns a copy of the Store whose records expire after ttl since they are written by Set, e.g. to re-run
+// the update path periodically for an ephemeral body that generates short-lived tokens, even if it is unchanged.
+// Diff, DiffWithOptions and DiffMarshaled regard a non-null ephemeral body as changed once the record is expired,
+// regardless of the hash (and DiffOpts.Grace). A record written without a timestamp (i.e. by an older version) is regarded as
+// expired, so that it is re-established with the timestamp on the next apply, and so is a record written in the
+// future. A zero or negative ttl disables the expiration, which is the default.
+func (s *Store) WithTTL(ttl time.Duration) *Store {
+	ns := *s
+	ns.ttl = max(ttl, 0)
+	return &ns
+}
+
+// WithClock returns a copy of the default Store using now as the clock. See Store.WithClock for details.
+func WithClock(now func() time.Time) *Store {
+	return defaultStore.WithClock(now)
+}
+
+// WithClock returns a copy of the Store that uses now as the clock, for the timestamp written by Set and the
+// expiration of WithTTL, e.g. to fake the time in tests. A nil now resets to time.Now. DiffOpts.Now, if set,
+// takes precedence in Diff.
+func (s *Store) WithClock(now func() time.Time) *Store {
+	ns := *s
+	ns.clock = now
+	return &ns
+}
+
+// now returns the current time by the clock of the Store.
+func (s *Store) now() time.Time {
+	if s.clock != nil {
+		return s.clock()
+	}
+	return time.Now()
+}
+
+// expired tells whether the record is expired by the TTL of the Store at now.
+func (s *Store) expired(rec *record, now time.Time) bool {
+	if s.ttl == 0 {
+		return false
+	}
+	if rec.WrittenAt == nil {
+		return true
+	}
+	// A record written in the future (e.g. clock skew, or edited) is regarded as expired.
+	elapsed := now.Sub(*rec.WrittenAt)
+	return elapsed < 0 || elapsed >= s.ttl
+}
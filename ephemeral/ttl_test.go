@@ -0,0 +1,97 @@
+package ephemeral_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTTL(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo"})
+	written := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := written
+	store := ephemeral.WithTTL(time.Hour).WithClock(func() time.Time { return now })
+
+	d := ephemeral.NewMemoryPrivateData()
+	require.False(t, store.Set(ctx, d, mustToJSON(t, body)).HasError())
+	b, diags := d.GetKey(ctx, "ephemeral_body")
+	require.False(t, diags.HasError())
+	var rec struct {
+		WrittenAt time.Time `json:"written_at"`
+	}
+	require.NoError(t, json.Unmarshal(b, &rec))
+	require.True(t, written.Equal(rec.WrittenAt))
+
+	now = written.Add(59 * time.Minute)
+	changed, diags := store.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	now = written.Add(time.Hour)
+	changed, diags = store.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+	changed, diags = store.DiffMarshaled(ctx, d, mustToJSON(t, body), false)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	// A record written in the future is expired
+	now = written.Add(-24 * time.Hour)
+	changed, diags = store.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	// DiffOpts.Now takes precedence over the clock
+	changed, diags = store.DiffWithOptions(ctx, d, body, ephemeral.DiffOpts{Now: func() time.Time { return written }})
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	// Without a TTL, the expired record is regarded as unchanged
+	changed, diags = store.WithTTL(0).Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	// Re-establishing the record refreshes the timestamp
+	require.False(t, store.Set(ctx, d, mustToJSON(t, body)).HasError())
+	changed, diags = store.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	// Unknown is always changed, and null is changed as long as the record exists
+	changed, diags = store.Diff(ctx, d, types.DynamicUnknown())
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+	changed, diags = store.Diff(ctx, ephemeral.NewMemoryPrivateData(), types.DynamicNull())
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+}
+
+func TestWithTTLLegacyRecord(t *testing.T) {
+	ctx := context.Background()
+	body := objectBody(map[string]string{"password": "foo"})
+
+	d := ephemeral.NewMemoryPrivateData()
+	require.False(t, ephemeral.Set(ctx, d, mustToJSON(t, body)).HasError())
+	b, diags := d.GetKey(ctx, "ephemeral_body")
+	require.False(t, diags.HasError())
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &rec))
+	delete(rec, "written_at")
+	b, err := json.Marshal(rec)
+	require.NoError(t, err)
+	require.False(t, d.SetKey(ctx, "ephemeral_body", b).HasError())
+
+	changed, diags := ephemeral.Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+
+	changed, diags = ephemeral.WithTTL(time.Hour).Diff(ctx, d, body)
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+}
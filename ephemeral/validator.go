@@ -0,0 +1,109 @@
+package ephemeral
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/dynamic"
+)
+
+// DisjointValidator returns a validator of the ephemeral body attribute, which validates it doesn't joint with the
+// body attribute at bodyPath (see ValidateEphemeralBody), instead of calling ValidateEphemeralBody in the
+// resource's ValidateConfig. Any attribute type of the body is accepted, the value is converted as a dynamic value.
+//
+// Nothing is validated if either the body or the ephemeral body is null or unknown. The error diagnostics are
+// reported at the ephemeral body attribute, in the "Invalid configuration" style naming both attributes.
+func DisjointValidator(bodyPath path.Path) validator.Dynamic {
+	return disjointValidator{bodyPath: bodyPath}
+}
+
+type disjointValidator struct {
+	bodyPath path.Path
+}
+
+func (v disjointValidator) Description(context.Context) string {
+	return fmt.Sprintf("The value must not define any path that is also defined by %s", v.bodyPath)
+}
+
+func (v disjointValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v disjointValidator) ValidateDynamic(ctx context.Context, req validator.DynamicRequest, resp *validator.DynamicResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	var bv attr.Value
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, v.bodyPath, &bv)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if bv.IsNull() || bv.IsUnknown() {
+		return
+	}
+	body, ok := bv.(types.Dynamic)
+	if !ok {
+		body = types.DynamicValue(bv)
+	}
+	if uv := body.UnderlyingValue(); uv == nil || uv.IsNull() || uv.IsUnknown() {
+		return
+	}
+	b, err := dynamic.ToJSON(body)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			v.bodyPath,
+			"failed to marshal body",
+			err.Error(),
+		)
+		return
+	}
+
+	_, _, diags := ValidateEphemeralBodyWithOptions(b, req.ConfigValue, ValidateOptions{
+		BodyAttribute:          v.bodyPath.String(),
+		EphemeralBodyAttribute: req.Path.String(),
+	})
+	for _, d := range diags {
+		resp.Diagnostics.AddAttributeError(req.Path, d.Summary(), d.Detail())
+	}
+}
+
+// ObjectBodyValidator returns a validator of the ephemeral body attribute, which validates it is a JSON object
+// (i.e. an object or a map), as assumed by the disjointness check and the nullification, instead of failing
+// confusingly when it is hashed. Null and unknown values are not validated.
+func ObjectBodyValidator() validator.Dynamic {
+	return objectBodyValidator{}
+}
+
+type objectBodyValidator struct{}
+
+func (v objectBodyValidator) Description(context.Context) string {
+	return "The value must be a JSON object"
+}
+
+func (v objectBodyValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v objectBodyValidator) ValidateDynamic(ctx context.Context, req validator.DynamicRequest, resp *validator.DynamicResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	uv := req.ConfigValue.UnderlyingValue()
+	if uv == nil || uv.IsNull() || uv.IsUnknown() {
+		return
+	}
+	switch uv.(type) {
+	case types.Object, types.Map:
+		return
+	}
+	resp.Diagnostics.AddAttributeError(
+		req.Path,
+		"Invalid configuration",
+		fmt.Sprintf("%q must be a JSON object", req.Path.String()),
+	)
+}
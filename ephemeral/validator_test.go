@@ -0,0 +1,122 @@
+package ephemeral_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisjointValidator(t *testing.T) {
+	ctx := context.Background()
+	sch := schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"body":           schema.DynamicAttribute{Optional: true},
+			"ephemeral_body": schema.DynamicAttribute{Optional: true, WriteOnly: true},
+		},
+	}
+	typ := sch.Type().TerraformType(ctx)
+	objType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{"name": tftypes.String, "password": tftypes.String}}
+	object := func(attrs map[string]string) tftypes.Value {
+		vals := map[string]tftypes.Value{"name": tftypes.NewValue(tftypes.String, nil), "password": tftypes.NewValue(tftypes.String, nil)}
+		for k, v := range attrs {
+			vals[k] = tftypes.NewValue(tftypes.String, v)
+		}
+		return tftypes.NewValue(objType, vals)
+	}
+	unknown := tftypes.NewValue(tftypes.DynamicPseudoType, tftypes.UnknownValue)
+	null := tftypes.NewValue(tftypes.DynamicPseudoType, nil)
+
+	cases := []struct {
+		name   string
+		body   tftypes.Value
+		eb     tftypes.Value
+		detail string
+	}{
+		{
+			name: "disjointed",
+			body: tftypes.NewValue(tftypes.Object{AttributeTypes: map[string]tftypes.Type{"name": tftypes.String}}, map[string]tftypes.Value{"name": tftypes.NewValue(tftypes.String, "x")}),
+			eb:   tftypes.NewValue(tftypes.Object{AttributeTypes: map[string]tftypes.Type{"password": tftypes.String}}, map[string]tftypes.Value{"password": tftypes.NewValue(tftypes.String, "y")}),
+		},
+		{
+			name:   "jointed",
+			body:   object(map[string]string{"name": "x"}),
+			eb:     object(map[string]string{"password": "y"}),
+			detail: `The "body" and the "ephemeral_body" are not disjointed, both define the following paths: name, password`,
+		},
+		{
+			name: "null body",
+			body: null,
+			eb:   object(map[string]string{"password": "y"}),
+		},
+		{
+			name: "unknown body",
+			body: unknown,
+			eb:   object(map[string]string{"password": "y"}),
+		},
+		{
+			name: "unknown ephemeral body",
+			body: object(map[string]string{"name": "x"}),
+			eb:   unknown,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tfsdk.Config{
+				Schema: sch,
+				Raw:    tftypes.NewValue(typ, map[string]tftypes.Value{"body": tt.body, "ephemeral_body": tt.eb}),
+			}
+			var eb types.Dynamic
+			require.False(t, config.GetAttribute(ctx, path.Root("ephemeral_body"), &eb).HasError())
+
+			req := validator.DynamicRequest{Path: path.Root("ephemeral_body"), ConfigValue: eb, Config: config}
+			var resp validator.DynamicResponse
+			ephemeral.DisjointValidator(path.Root("body")).ValidateDynamic(ctx, req, &resp)
+			if tt.detail == "" {
+				require.False(t, resp.Diagnostics.HasError())
+				return
+			}
+			require.Len(t, resp.Diagnostics, 1)
+			require.Equal(t, "Invalid configuration", resp.Diagnostics[0].Summary())
+			require.Equal(t, tt.detail, resp.Diagnostics[0].Detail())
+		})
+	}
+}
+
+func TestObjectBodyValidator(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name  string
+		value types.Dynamic
+		err   bool
+	}{
+		{name: "object", value: objectBody(map[string]string{"password": "foo"})},
+		{name: "map", value: types.DynamicValue(types.MapValueMust(types.StringType, map[string]attr.Value{"password": types.StringValue("foo")}))},
+		{name: "null", value: types.DynamicNull()},
+		{name: "unknown", value: types.DynamicUnknown()},
+		{name: "unknown string", value: types.DynamicValue(types.StringUnknown())},
+		{name: "string", value: types.DynamicValue(types.StringValue("foo")), err: true},
+		{name: "tuple", value: types.DynamicValue(types.TupleValueMust([]attr.Type{types.StringType}, []attr.Value{types.StringValue("foo")})), err: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validator.DynamicRequest{Path: path.Root("ephemeral_body"), ConfigValue: tt.value}
+			var resp validator.DynamicResponse
+			ephemeral.ObjectBodyValidator().ValidateDynamic(ctx, req, &resp)
+			if !tt.err {
+				require.False(t, resp.Diagnostics.HasError())
+				return
+			}
+			require.Len(t, resp.Diagnostics, 1)
+			require.Equal(t, `"ephemeral_body" must be a JSON object`, resp.Diagnostics[0].Detail())
+		})
+	}
+}
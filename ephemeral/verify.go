@@ -0,0 +1,37 @@
+package ephemeral
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// verifyingPrivateData wraps a PrivateData, re-reading every key after it is written to confirm the write is persisted.
+type verifyingPrivateData struct {
+	PrivateData
+}
+
+func (d verifyingPrivateData) SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics {
+	diags := d.PrivateData.SetKey(ctx, key, value)
+	if diags.HasError() {
+		return diags
+	}
+	got, odiags := d.PrivateData.GetKey(ctx, key)
+	diags.Append(odiags...)
+	if diags.HasError() {
+		return diags
+	}
+	// Writing a nil or zero-length value removes the key.
+	if len(value) == 0 && len(got) == 0 {
+		return diags
+	}
+	if !bytes.Equal(got, value) {
+		diags.AddError(
+			`Failed to verify the ephemeral body private data write`,
+			fmt.Sprintf(`The value read back from the private state key %q doesn't match the value written`, key),
+		)
+	}
+	return diags
+}
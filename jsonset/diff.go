@@ -0,0 +1,270 @@
+package jsonset
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// ChangeType is the type of a Change.
+type ChangeType int
+
+const (
+	// ChangeAdd means the path only exists in the new json.
+	ChangeAdd ChangeType = iota
+	// ChangeRemove means the path only exists in the old json.
+	ChangeRemove
+	// ChangeReplace means the path exists in both, while the values differ.
+	ChangeReplace
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeAdd:
+		return "add"
+	case ChangeRemove:
+		return "remove"
+	case ChangeReplace:
+		return "replace"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is a difference between two json values.
+type Change struct {
+	Type ChangeType
+
+	// Path is the JSON pointer of the changed value.
+	Path string
+
+	// Old is the old value, which is nil for ChangeAdd.
+	Old json.RawMessage
+
+	// New is the new value, which is nil for ChangeRemove.
+	New json.RawMessage
+}
+
+// AllDiffs returns all the differences from the old json to the new json, in a single pass.
+// Objects are compared by keys recursively, a key only exists in either side results into a ChangeAdd or
+// ChangeRemove of the whole value. Arrays are compared by index, the common indexes are compared recursively,
+// while the extra elements result into ChangeAdd or ChangeRemove. Other values (including values of different
+// kinds) that are not semantically equal (see Equal) result into a ChangeReplace.
+// The changes are ordered depth-first, with object keys sorted.
+func AllDiffs(old, new []byte) ([]Change, error) {
+	return AllDiffsOpts(old, new, Options{})
+}
+
+// AllDiffsOpts is similar to AllDiffs, with the behavior tuned by opts:
+//   - SmartArrayDiff: The arrays are aligned by the longest common subsequence. See Options.SmartArrayDiff.
+//   - Strict: The json values having duplicate object keys result into an error. See Options.Strict.
+func AllDiffsOpts(old, new []byte, opts Options) ([]Change, error) {
+	if err := opts.checkStrict(old); err != nil {
+		return nil, fmt.Errorf("JSON unmarshal old: %v", err)
+	}
+	if err := opts.checkStrict(new); err != nil {
+		return nil, fmt.Errorf("JSON unmarshal new: %v", err)
+	}
+	ov, err := unmarshal(old)
+	if err != nil {
+		return nil, fmt.Errorf("JSON unmarshal old: %v", err)
+	}
+	nv, err := unmarshal(new)
+	if err != nil {
+		return nil, fmt.Errorf("JSON unmarshal new: %v", err)
+	}
+	d := differ{smartArray: opts.SmartArrayDiff}
+	if err := d.diffAll(nil, ov, nv); err != nil {
+		return nil, err
+	}
+	return d.changes, nil
+}
+
+// Patch returns the JSON patch (RFC 6902) that transforms the old json into the new json (see ApplyPatch), e.g.
+// to drive a PATCH-style API call from what actually changed. Objects are compared by keys recursively, a key only
+// exists in the old or the new json results into a "remove" or "add" operation. Arrays are replaced as a whole
+// by a "replace" operation once they are not semantically equal (see Equal), rather than being patched
+// element-wise. Other values that are not semantically equal result into a "replace" operation as well.
+// The operations are ordered depth-first, with object keys sorted. Equal jsons result into an empty patch "[]".
+func Patch(old, new []byte) ([]byte, error) {
+	ov, err := unmarshal(old)
+	if err != nil {
+		return nil, fmt.Errorf("JSON unmarshal old: %v", err)
+	}
+	nv, err := unmarshal(new)
+	if err != nil {
+		return nil, fmt.Errorf("JSON unmarshal new: %v", err)
+	}
+	d := differ{wholeArrays: true}
+	if err := d.diffAll(nil, ov, nv); err != nil {
+		return nil, err
+	}
+
+	type operation struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value,omitempty"`
+	}
+	ops := make([]operation, 0, len(d.changes))
+	for _, c := range d.changes {
+		ops = append(ops, operation{Op: c.Type.String(), Path: c.Path, Value: c.New})
+	}
+	return json.Marshal(ops)
+}
+
+type differ struct {
+	changes    []Change
+	smartArray bool
+	// wholeArrays makes the arrays compared as a whole, i.e. different arrays result into a ChangeReplace.
+	wholeArrays bool
+}
+
+func (d *differ) diffAll(path []string, ov, nv interface{}) error {
+	changes := &d.changes
+	switch ov := ov.(type) {
+	case map[string]interface{}:
+		nv, ok := nv.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := slices.Collect(maps.Keys(ov))
+		for k := range nv {
+			if _, ok := ov[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			ovv, ook := ov[k]
+			nvv, nok := nv[k]
+			kpath := append(slices.Clip(path), k)
+			switch {
+			case !nok:
+				if err := appendChange(changes, ChangeRemove, kpath, ovv, nil); err != nil {
+					return err
+				}
+			case !ook:
+				if err := appendChange(changes, ChangeAdd, kpath, nil, nvv); err != nil {
+					return err
+				}
+			default:
+				if err := d.diffAll(kpath, ovv, nvv); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case []interface{}:
+		nv, ok := nv.([]interface{})
+		if !ok || d.wholeArrays {
+			break
+		}
+		if d.smartArray {
+			return d.diffAligned(path, ov, nv)
+		}
+		for i := 0; i < max(len(ov), len(nv)); i++ {
+			ipath := append(slices.Clip(path), strconv.Itoa(i))
+			var err error
+			switch {
+			case i >= len(nv):
+				err = appendChange(changes, ChangeRemove, ipath, ov[i], nil)
+			case i >= len(ov):
+				err = appendChange(changes, ChangeAdd, ipath, nil, nv[i])
+			default:
+				err = d.diffAll(ipath, ov[i], nv[i])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if (&comparer{}).equal(path, ov, nv) {
+		return nil
+	}
+	return appendChange(changes, ChangeReplace, path, ov, nv)
+}
+
+// diffAligned diffs the arrays aligned by the longest common subsequence of the equal elements. Between two
+// adjacent common elements, the old and new elements are compared pairwise in order (at the old index), while the
+// extra ones result into ChangeRemove (at the old index) or ChangeAdd (at the new index).
+func (d *differ) diffAligned(path []string, ov, nv []interface{}) error {
+	c := &comparer{}
+	// lcs[i][j] is the length of the LCS of ov[i:] and nv[j:].
+	lcs := make([][]int, len(ov)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(nv)+1)
+	}
+	for i := len(ov) - 1; i >= 0; i-- {
+		for j := len(nv) - 1; j >= 0; j-- {
+			if c.equal(nil, ov[i], nv[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	gap := func(oi, oj, ni, nj int) error {
+		for ; oi < oj && ni < nj; oi, ni = oi+1, ni+1 {
+			if err := d.diffAll(append(slices.Clip(path), strconv.Itoa(oi)), ov[oi], nv[ni]); err != nil {
+				return err
+			}
+		}
+		for ; oi < oj; oi++ {
+			if err := appendChange(&d.changes, ChangeRemove, append(slices.Clip(path), strconv.Itoa(oi)), ov[oi], nil); err != nil {
+				return err
+			}
+		}
+		for ; ni < nj; ni++ {
+			if err := appendChange(&d.changes, ChangeAdd, append(slices.Clip(path), strconv.Itoa(ni)), nil, nv[ni]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// oi and ni are the starts of the current gap, i and j walk the LCS.
+	var oi, ni, i, j int
+	for i < len(ov) && j < len(nv) {
+		switch {
+		case c.equal(nil, ov[i], nv[j]) && lcs[i][j] == lcs[i+1][j+1]+1:
+			if err := gap(oi, i, ni, j); err != nil {
+				return err
+			}
+			i, j = i+1, j+1
+			oi, ni = i, j
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return gap(oi, len(ov), ni, len(nv))
+}
+
+func appendChange(changes *[]Change, typ ChangeType, path []string, ov, nv interface{}) error {
+	c := Change{
+		Type: typ,
+		Path: BuildPointer(path...),
+	}
+	if typ != ChangeAdd {
+		b, err := json.Marshal(ov)
+		if err != nil {
+			return err
+		}
+		c.Old = b
+	}
+	if typ != ChangeRemove {
+		b, err := json.Marshal(nv)
+		if err != nil {
+			return err
+		}
+		c.New = b
+	}
+	*changes = append(*changes, c)
+	return nil
+}
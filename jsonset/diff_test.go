@@ -0,0 +1,180 @@
+package jsonset_test
+
+import (
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllDiffs(t *testing.T) {
+	cases := []struct {
+		name    string
+		old     string
+		new     string
+		changes []jsonset.Change
+		err     bool
+	}{
+		{
+			name: "Invalid json",
+			old:  `{`,
+			new:  `{}`,
+			err:  true,
+		},
+		{
+			name: "Equal",
+			old:  `{"a": 1, "b": [1, {"c": 1.0}]}`,
+			new:  `{"b": [1, {"c": 1}], "a": 1.00}`,
+		},
+		{
+			name: "Added, removed and replaced keys",
+			old:  `{"z": 1, "a": {"x": 1, "y": "old"}, "r": true}`,
+			new:  `{"z": 1, "a": {"y": "new", "w": [1]}, "n": null}`,
+			changes: []jsonset.Change{
+				{Type: jsonset.ChangeAdd, Path: "/a/w", New: []byte(`[1]`)},
+				{Type: jsonset.ChangeRemove, Path: "/a/x", Old: []byte(`1`)},
+				{Type: jsonset.ChangeReplace, Path: "/a/y", Old: []byte(`"old"`), New: []byte(`"new"`)},
+				{Type: jsonset.ChangeAdd, Path: "/n", New: []byte(`null`)},
+				{Type: jsonset.ChangeRemove, Path: "/r", Old: []byte(`true`)},
+			},
+		},
+		{
+			name: "Arrays compared by index",
+			old:  `[1, {"a": 1}, 3]`,
+			new:  `[2, {"a": 2}]`,
+			changes: []jsonset.Change{
+				{Type: jsonset.ChangeReplace, Path: "/0", Old: []byte(`1`), New: []byte(`2`)},
+				{Type: jsonset.ChangeReplace, Path: "/1/a", Old: []byte(`1`), New: []byte(`2`)},
+				{Type: jsonset.ChangeRemove, Path: "/2", Old: []byte(`3`)},
+			},
+		},
+		{
+			name: "Different kinds",
+			old:  `{"a/b": {"c": 1}}`,
+			new:  `{"a/b": [9007199254740993]}`,
+			changes: []jsonset.Change{
+				{Type: jsonset.ChangeReplace, Path: "/a~1b", Old: []byte(`{"c":1}`), New: []byte(`[9007199254740993]`)},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := jsonset.AllDiffs([]byte(tt.old), []byte(tt.new))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.changes, changes)
+		})
+	}
+}
+
+func TestAllDiffsOptsSmartArrayDiff(t *testing.T) {
+	cases := []struct {
+		name    string
+		old     string
+		new     string
+		changes []jsonset.Change
+	}{
+		{
+			name: "Insert in the middle",
+			old:  `{"a": [1, 2, 3, 4]}`,
+			new:  `{"a": [1, 2, 9, 3, 4]}`,
+			changes: []jsonset.Change{
+				{Type: jsonset.ChangeAdd, Path: "/a/2", New: []byte(`9`)},
+			},
+		},
+		{
+			name: "Delete in the middle",
+			old:  `[{"n": 1}, {"n": 2}, {"n": 3}]`,
+			new:  `[{"n": 1}, {"n": 3}]`,
+			changes: []jsonset.Change{
+				{Type: jsonset.ChangeRemove, Path: "/1", Old: []byte(`{"n":2}`)},
+			},
+		},
+		{
+			name: "Modified element between common ones",
+			old:  `[1, {"n": 2}, 3]`,
+			new:  `[0, 1, {"n": 5}, 3, 4]`,
+			changes: []jsonset.Change{
+				{Type: jsonset.ChangeAdd, Path: "/0", New: []byte(`0`)},
+				{Type: jsonset.ChangeReplace, Path: "/1/n", Old: []byte(`2`), New: []byte(`5`)},
+				{Type: jsonset.ChangeAdd, Path: "/4", New: []byte(`4`)},
+			},
+		},
+		{
+			name: "Equal arrays",
+			old:  `[1, 2.0]`,
+			new:  `[1, 2]`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := jsonset.AllDiffsOpts([]byte(tt.old), []byte(tt.new), jsonset.Options{SmartArrayDiff: true})
+			require.NoError(t, err)
+			require.Equal(t, tt.changes, changes)
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	cases := []struct {
+		name  string
+		old   string
+		new   string
+		patch string
+		err   bool
+	}{
+		{
+			name: "Invalid json",
+			old:  `{`,
+			new:  `{}`,
+			err:  true,
+		},
+		{
+			name:  "Equal",
+			old:   `{"a": 1, "b": [1, 2]}`,
+			new:   `{"b": [1, 2.0], "a": 1.0}`,
+			patch: `[]`,
+		},
+		{
+			name:  "Nested objects",
+			old:   `{"a": 1, "b": {"c": 1, "d": 2}, "e": "x"}`,
+			new:   `{"a": 2, "b": {"c": 1, "f": null}}`,
+			patch: `[{"op":"replace","path":"/a","value":2},{"op":"remove","path":"/b/d"},{"op":"add","path":"/b/f","value":null},{"op":"remove","path":"/e"}]`,
+		},
+		{
+			name:  "Arrays are replaced as a whole",
+			old:   `{"a": [{"b": 1}, 2]}`,
+			new:   `{"a": [{"b": 2}, 2]}`,
+			patch: `[{"op":"replace","path":"/a","value":[{"b":2},2]}]`,
+		},
+		{
+			name:  "Root",
+			old:   `[1]`,
+			new:   `{"a~b": 1}`,
+			patch: `[{"op":"replace","path":"","value":{"a~b":1}}]`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := jsonset.Patch([]byte(tt.old), []byte(tt.new))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.patch, string(patch))
+
+			result, err := jsonset.ApplyPatch([]byte(tt.old), patch)
+			require.NoError(t, err)
+			equal, err := jsonset.Equal([]byte(tt.new), result)
+			require.NoError(t, err)
+			require.True(t, equal, string(result))
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package jsonset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+// checkDuplicateKeys returns an error if any object of the json value has duplicate keys, which encoding/json
+// silently decodes as the last one wins. Only the first duplicate key, in the document order, is reported.
+func checkDuplicateKeys(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return checkDuplicateKeysValue(dec, nil)
+}
+
+func checkDuplicateKeysValue(dec *json.Decoder, path []string) error {
+	tk, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tk {
+	case json.Delim('{'):
+		keys := map[string]bool{}
+		for dec.More() {
+			tk, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := tk.(string)
+			if keys[key] {
+				return fmt.Errorf("duplicate key %q in the object at %q", key, BuildPointer(path...))
+			}
+			keys[key] = true
+			if err := checkDuplicateKeysValue(dec, append(slices.Clip(path), key)); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		return err
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := checkDuplicateKeysValue(dec, append(slices.Clip(path), strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		return err
+	default:
+		return nil
+	}
+}
+
+// checkStrict checks the json value against the Strict option, which is a no-op if it is not set.
+func (opts Options) checkStrict(b []byte) error {
+	if !opts.Strict {
+		return nil
+	}
+	return checkDuplicateKeys(b)
+}
+
+// checkNormalizedKeys returns an error if any object of the decoded json value has distinct keys that are normalized
+// to the same key by normalize, which would otherwise silently shadow each other. Only the first collision, in the
+// depth-first order with the keys sorted, is reported.
+func checkNormalizedKeys(v interface{}, path []string, normalize func(string) string) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := slices.Sorted(maps.Keys(v))
+		normalized := map[string]string{}
+		for _, k := range keys {
+			nk := normalize(k)
+			if ok, exists := normalized[nk]; exists {
+				return fmt.Errorf("keys %q and %q are normalized to the same key %q in the object at %q", ok, k, nk, BuildPointer(path...))
+			}
+			normalized[nk] = k
+		}
+		for _, k := range keys {
+			if err := checkNormalizedKeys(v[k], append(slices.Clip(path), k), normalize); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			if err := checkNormalizedKeys(e, append(slices.Clip(path), strconv.Itoa(i)), normalize); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package jsonset_test
+
+import (
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrict(t *testing.T) {
+	cases := []struct {
+		name string
+		lhs  string
+		rhs  string
+		err  string
+	}{
+		{
+			name: "No duplicate",
+			lhs:  `{"a": 1, "b": [{"a": 1}, {"a": 2}]}`,
+			rhs:  `{"c": {"a": 1}}`,
+		},
+		{
+			name: "Top level duplicate",
+			lhs:  `{"a":1,"a":2}`,
+			rhs:  `{}`,
+			err:  `JSON unmarshal lhs: duplicate key "a" in the object at ""`,
+		},
+		{
+			name: "Nested duplicate",
+			lhs:  `{}`,
+			rhs:  `{"a": [{"b": {"c": 1, "c": 1}}]}`,
+			err:  `JSON unmarshal rhs: duplicate key "c" in the object at "/a/0/b"`,
+		},
+		{
+			name: "Invalid json",
+			lhs:  `{"a":`,
+			rhs:  `{}`,
+			err:  `JSON unmarshal lhs`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := jsonset.Options{Strict: true}
+			check := func(err error) {
+				if tt.err == "" {
+					require.NoError(t, err)
+					return
+				}
+				require.ErrorContains(t, err, tt.err)
+			}
+			_, err := jsonset.DisjointedOpts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			check(err)
+			_, err = jsonset.Conflicts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			check(err)
+			_, err = jsonset.EqualOpts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			check(err)
+			_, err = jsonset.AllDiffsOpts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			if tt.err != "" {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	// The duplicate keys are decoded as the last one wins without Strict.
+	disjointed, err := jsonset.DisjointedOpts([]byte(`{"a":1,"a":2}`), []byte(`{"b":1}`), jsonset.Options{})
+	require.NoError(t, err)
+	require.True(t, disjointed)
+}
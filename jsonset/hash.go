@@ -0,0 +1,139 @@
+package jsonset
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha256" // registers crypto.SHA256, the default algorithm
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Canonicalization is how the JSON document is canonicalized before hashed.
+type Canonicalization int
+
+const (
+	// CanonicalNone hashes the bytes as is, which are not even validated as JSON.
+	CanonicalNone Canonicalization = iota
+	// CanonicalSortedKeys hashes the compact form with the object keys sorted byte-wise, as json.Marshal emits
+	// (i.e. HTML characters escaped), while the number literals are kept as is.
+	CanonicalSortedKeys
+	// CanonicalJCS hashes the JSON Canonicalization Scheme (RFC 8785) form: the object keys are sorted by their
+	// UTF-16 code units, the strings are minimally escaped, and the numbers are serialized as ECMAScript does for
+	// the IEEE 754 double, which loses the precision beyond a double.
+	CanonicalJCS
+)
+
+// HashOptions tunes Hash.
+type HashOptions struct {
+	// Canonicalization defaults to CanonicalNone.
+	Canonicalization Canonicalization
+
+	// Algorithm defaults to crypto.SHA256. Other algorithms must be linked into the binary, e.g. by importing
+	// crypto/sha512 for crypto.SHA512.
+	Algorithm crypto.Hash
+}
+
+// Hash canonicalizes the JSON document and returns the raw digest. With the zero options, it is the SHA-256 of
+// the bytes, which is the same hash as recorded by the ephemeral package. The other canonicalizations are meant to
+// match the hashes computed by the servers over the semantically equal documents.
+func Hash(b []byte, opts HashOptions) ([]byte, error) {
+	var (
+		canonical []byte
+		err       error
+	)
+	switch opts.Canonicalization {
+	case CanonicalNone:
+		canonical = b
+	case CanonicalSortedKeys:
+		canonical, err = sortedKeysForm(b)
+	case CanonicalJCS:
+		canonical, err = jcsForm(b)
+	default:
+		return nil, fmt.Errorf("unknown canonicalization %d", opts.Canonicalization)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	alg := opts.Algorithm
+	if alg == 0 {
+		alg = crypto.SHA256
+	}
+	if !alg.Available() {
+		return nil, fmt.Errorf("hash algorithm %s is not available", alg)
+	}
+	h := alg.New()
+	h.Write(canonical)
+	return h.Sum(nil), nil
+}
+
+func sortedKeysForm(b []byte) ([]byte, error) {
+	v, err := unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func jcsForm(b []byte) ([]byte, error) {
+	v, err := unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeJCS(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeJCS(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case json.Number:
+		f, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil || math.IsInf(f, 0) {
+			return fmt.Errorf("number %s can't be represented as a double", v)
+		}
+		// The shortest digits that round trip, as ECMAScript does, then laid out by the same rules.
+		n, err := normalizeNumber(strconv.FormatFloat(f, 'e', -1, 64))
+		if err != nil {
+			return err
+		}
+		buf.WriteString(n)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJCS(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := slices.SortedFunc(maps.Keys(v), func(a, b string) int {
+			return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+		})
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeNormalizedString(buf, k)
+			buf.WriteByte(':')
+			if err := writeJCS(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return writeNormalized(buf, v)
+	}
+	return nil
+}
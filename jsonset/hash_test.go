@@ -0,0 +1,114 @@
+package jsonset_test
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	sum := func(s string) []byte {
+		h := sha256.Sum256([]byte(s))
+		return h[:]
+	}
+
+	cases := []struct {
+		name string
+		doc  string
+		opts jsonset.HashOptions
+		hash []byte
+		err  bool
+	}{
+		{
+			name: "none",
+			doc:  `{"b": 1, "a": 2}`,
+			hash: sum(`{"b": 1, "a": 2}`),
+		},
+		{
+			name: "none doesn't validate",
+			doc:  `not json`,
+			hash: sum(`not json`),
+		},
+		{
+			name: "sorted keys",
+			doc:  `{"b": 1.0, "a": "<x>"}`,
+			opts: jsonset.HashOptions{Canonicalization: jsonset.CanonicalSortedKeys},
+			hash: sum(`{"a":"\u003cx\u003e","b":1.0}`),
+		},
+		{
+			name: "jcs",
+			doc:  `{"b": [1.0, 1e2, 0.0000001, -0], "a": "<x>\n"}`,
+			opts: jsonset.HashOptions{Canonicalization: jsonset.CanonicalJCS},
+			hash: sum(`{"a":"<x>\n","b":[1,100,1e-7,0]}`),
+		},
+		{
+			name: "jcs sorts keys by utf-16 code units",
+			doc:  "{\"\uE000\": 1, \"\U0001F600\": 2}",
+			opts: jsonset.HashOptions{Canonicalization: jsonset.CanonicalJCS},
+			hash: sum("{\"\U0001F600\":2,\"\uE000\":1}"),
+		},
+		{
+			name: "jcs number beyond double",
+			doc:  `1e400`,
+			opts: jsonset.HashOptions{Canonicalization: jsonset.CanonicalJCS},
+			err:  true,
+		},
+		{
+			name: "invalid json",
+			doc:  `{`,
+			opts: jsonset.HashOptions{Canonicalization: jsonset.CanonicalSortedKeys},
+			err:  true,
+		},
+		{
+			name: "unavailable algorithm",
+			doc:  `{}`,
+			opts: jsonset.HashOptions{Algorithm: crypto.BLAKE2b_256},
+			err:  true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := jsonset.Hash([]byte(tt.doc), tt.opts)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.hash, hash)
+		})
+	}
+}
+
+func TestHashCrossCanonicalization(t *testing.T) {
+	a := []byte(`{"id": 100, "tags": ["x", "y"], "name": "n"}`)
+	b := []byte(`{"name":"n","tags":["x","y"],"id":100}`)
+	c := []byte(`{"name": "n", "id": 1e2, "tags": ["x", "y"]}`)
+
+	hashes := func(cn jsonset.Canonicalization) [3][]byte {
+		var out [3][]byte
+		for i, doc := range [][]byte{a, b, c} {
+			h, err := jsonset.Hash(doc, jsonset.HashOptions{Canonicalization: cn, Algorithm: crypto.SHA512})
+			require.NoError(t, err)
+			require.Len(t, h, sha512.Size)
+			out[i] = h
+		}
+		return out
+	}
+
+	none := hashes(jsonset.CanonicalNone)
+	require.NotEqual(t, none[0], none[1])
+	require.NotEqual(t, none[1], none[2])
+
+	sorted := hashes(jsonset.CanonicalSortedKeys)
+	require.Equal(t, sorted[0], sorted[1])
+	require.NotEqual(t, sorted[1], sorted[2])
+
+	jcs := hashes(jsonset.CanonicalJCS)
+	require.Equal(t, jcs[0], jcs[1])
+	require.Equal(t, jcs[1], jcs[2])
+}
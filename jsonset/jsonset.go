@@ -1,9 +1,13 @@
 package jsonset
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"maps"
+	"math/big"
+	"slices"
+	"strconv"
 )
 
 // Disjointed tells whether two valid json values are disjointed.
@@ -11,6 +15,9 @@ import (
 //   - Both are objects: Having different sets of keys, or the values of the common key are disjointed.
 //   - Otherwise, the two json values are regarded jointed, including both values have different types, or
 //     different values.
+//
+// Arrays are never compared element-wise, two arrays (of any elements, in any order) are always jointed.
+// See Options.UnorderedArrays of DisjointedOpts to compare them as sets.
 func Disjointed(lhs, rhs []byte) (bool, error) {
 	var lv, rv interface{}
 	if err := json.Unmarshal(lhs, &lv); err != nil {
@@ -22,6 +29,150 @@ func Disjointed(lhs, rhs []byte) (bool, error) {
 	return disjointValue(lv, rv), nil
 }
 
+// DisjointedOpts is similar to Disjointed, with the behavior tuned by opts:
+//   - LeavesOnly: Only the leaf paths are regarded as overlapping. See Options.LeavesOnly.
+//   - IgnoreNulls: The null values (and the objects of only null leaves) are regarded as absent. See Options.IgnoreNulls.
+//   - UnorderedArrays: The arrays are jointed only if they have a common element. See Options.UnorderedArrays.
+//   - UnicodeNormalizeKeys: The object keys are compared after the NFC normalization. See Options.UnicodeNormalizeKeys.
+//   - KeyNormalizer: The object keys are compared after normalization. See Options.KeyNormalizer.
+//   - Strict: The json values having duplicate object keys result into an error. See Options.Strict.
+func DisjointedOpts(lhs, rhs []byte, opts Options) (bool, error) {
+	conflicts, err := Conflicts(lhs, rhs, opts)
+	if err != nil {
+		return false, err
+	}
+	return len(conflicts) == 0, nil
+}
+
+// DisjointedDetail is similar to Disjointed, while it returns the JSON pointers of the conflicting paths (see
+// Conflicts), which is empty if the two values are disjointed. As only objects are merged, a conflict is reported
+// at the deepest path where either value is not an object, e.g. an array (including an array of objects) defined
+// on both sides is reported at the path of the array.
+func DisjointedDetail(lhs, rhs []byte) ([]string, error) {
+	return Conflicts(lhs, rhs, Options{})
+}
+
+// Conflicts returns the JSON pointers of the paths where the two valid json values are jointed, in the sorted order.
+// The paths are built from the keys of lhs.
+// The two values are disjointed (see DisjointedOpts) if and only if there is no conflict.
+func Conflicts(lhs, rhs []byte, opts Options) ([]string, error) {
+	if err := opts.checkStrict(lhs); err != nil {
+		return nil, fmt.Errorf("JSON unmarshal lhs: %v", err)
+	}
+	if err := opts.checkStrict(rhs); err != nil {
+		return nil, fmt.Errorf("JSON unmarshal rhs: %v", err)
+	}
+	var lv, rv interface{}
+	if err := json.Unmarshal(lhs, &lv); err != nil {
+		return nil, fmt.Errorf("JSON unmarshal lhs: %v", err)
+	}
+	if err := json.Unmarshal(rhs, &rv); err != nil {
+		return nil, fmt.Errorf("JSON unmarshal rhs: %v", err)
+	}
+	if normalize := opts.keyNormalizer(); normalize != nil {
+		if err := checkNormalizedKeys(lv, nil, normalize); err != nil {
+			return nil, fmt.Errorf("normalize lhs: %v", err)
+		}
+		if err := checkNormalizedKeys(rv, nil, normalize); err != nil {
+			return nil, fmt.Errorf("normalize rhs: %v", err)
+		}
+	}
+	var conflicts []string
+	conflictValue(nil, lv, rv, opts, &conflicts)
+	slices.Sort(conflicts)
+	return conflicts, nil
+}
+
+func conflictValue(path []string, lv, rv interface{}, opts Options, conflicts *[]string) {
+	if opts.LeavesOnly && (isContainerPlaceholder(lv) || isContainerPlaceholder(rv)) {
+		return
+	}
+	if opts.IgnoreNulls && (isNullOnly(lv) || isNullOnly(rv)) {
+		return
+	}
+	if opts.UnorderedArrays {
+		la, lok := lv.([]interface{})
+		ra, rok := rv.([]interface{})
+		if lok && rok {
+			if haveCommonElement(la, ra) {
+				*conflicts = append(*conflicts, BuildPointer(path...))
+			}
+			return
+		}
+	}
+	lm, lok := lv.(map[string]interface{})
+	rm, rok := rv.(map[string]interface{})
+	if !lok || !rok {
+		*conflicts = append(*conflicts, BuildPointer(path...))
+		return
+	}
+	normalize := opts.keyNormalizer()
+	if normalize != nil {
+		nrm := map[string]interface{}{}
+		for k, v := range rm {
+			nrm[normalize(k)] = v
+		}
+		rm = nrm
+	}
+	for k, lv := range lm {
+		nk := k
+		if normalize != nil {
+			nk = normalize(k)
+		}
+		if rv, ok := rm[nk]; ok {
+			conflictValue(append(slices.Clone(path), k), lv, rv, opts, conflicts)
+		}
+	}
+}
+
+// haveCommonElement tells whether the two arrays have an element in common, regardless of the order. The elements
+// are compared by their normalized form (see Normalize), so that the objects are compared structurally.
+func haveCommonElement(la, ra []interface{}) bool {
+	elements := map[string]bool{}
+	for _, v := range la {
+		elements[normalizedElement(v)] = true
+	}
+	for _, v := range ra {
+		if elements[normalizedElement(v)] {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizedElement(v interface{}) string {
+	// The decoded json value can always be marshaled and normalized.
+	b, _ := json.Marshal(v)
+	nb, _ := Normalize(b)
+	return string(nb)
+}
+
+// isContainerPlaceholder tells whether the json value contributes no leaf, i.e. it is either a null, or an empty object.
+func isContainerPlaceholder(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	m, ok := v.(map[string]interface{})
+	return ok && len(m) == 0
+}
+
+// isNullOnly tells whether the json value is a null, or a non-empty object whose values are all null only.
+func isNullOnly(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return false
+	}
+	for _, v := range m {
+		if !isNullOnly(v) {
+			return false
+		}
+	}
+	return true
+}
+
 func disjointValue(lv, rv interface{}) bool {
 	switch lv := lv.(type) {
 	case map[string]interface{}:
@@ -98,13 +249,17 @@ func diffMap(lm, rm map[string]interface{}) (map[string]interface{}, bool) {
 }
 
 // NullifyObject returns the json object, with value nullified, recursively.
+// The arrays are kept with the same length, whose elements are nullified in the same way (i.e. the object
+// elements have their values nullified, while the other elements become null).
 // If the input is not a json object, nil is returned.
 func NullifyObject(b []byte) ([]byte, error) {
 	var v interface{}
 	if err := json.Unmarshal(b, &v); err != nil {
 		return nil, err
 	}
-
+	if _, ok := v.(map[string]interface{}); !ok {
+		return json.Marshal(nil)
+	}
 	return json.Marshal(nullifyVal(v))
 }
 
@@ -112,6 +267,12 @@ func nullifyVal(v interface{}) interface{} {
 	switch v := v.(type) {
 	case map[string]interface{}:
 		return nullifyMap(v)
+	case []interface{}:
+		vv := make([]interface{}, 0, len(v))
+		for _, elem := range v {
+			vv = append(vv, nullifyVal(elem))
+		}
+		return vv
 	default:
 		return nil
 	}
@@ -124,3 +285,196 @@ func nullifyMap(m map[string]interface{}) map[string]interface{} {
 	}
 	return mm
 }
+
+// Equal tells whether two valid json values are semantically equal.
+// Objects are compared regardless of the key order, arrays are compared element-wise in order,
+// and numbers are compared by value (e.g. 1 equals 1.0), without losing precision.
+func Equal(lhs, rhs []byte) (bool, error) {
+	return EqualOpts(lhs, rhs, Options{})
+}
+
+// EqualOpts is similar to Equal, with the comparison tuned by opts:
+//   - UnorderedArrayPaths: The arrays at these paths are compared as multisets.
+//   - IgnoreKeys: The object members of these keys are ignored at every nesting level.
+//   - Strict: The json values having duplicate object keys result into an error.
+func EqualOpts(lhs, rhs []byte, opts Options) (bool, error) {
+	if err := opts.checkStrict(lhs); err != nil {
+		return false, fmt.Errorf("JSON unmarshal lhs: %v", err)
+	}
+	if err := opts.checkStrict(rhs); err != nil {
+		return false, fmt.Errorf("JSON unmarshal rhs: %v", err)
+	}
+	lv, err := unmarshal(lhs)
+	if err != nil {
+		return false, fmt.Errorf("JSON unmarshal lhs: %v", err)
+	}
+	rv, err := unmarshal(rhs)
+	if err != nil {
+		return false, fmt.Errorf("JSON unmarshal rhs: %v", err)
+	}
+	c, err := newComparer(opts)
+	if err != nil {
+		return false, err
+	}
+	return c.equal(nil, lv, rv), nil
+}
+
+// EqualFunc is similar to Equal, while the leaf values (i.e. the non-object, non-array values) are compared by cmp
+// first, which is called with the JSON pointer of the leaf and both values, whenever either side is a leaf. If cmp
+// reports handled, its equal result is taken, otherwise the leaves are compared as Equal does. This allows the
+// domain specific equality, e.g. comparing CIDRs, or case-folded DNS names. The values passed to cmp are compact,
+// with numbers kept as their literals.
+func EqualFunc(lhs, rhs []byte, cmp func(path string, lv, rv json.RawMessage) (equal bool, handled bool)) (bool, error) {
+	lv, err := unmarshal(lhs)
+	if err != nil {
+		return false, fmt.Errorf("JSON unmarshal lhs: %v", err)
+	}
+	rv, err := unmarshal(rhs)
+	if err != nil {
+		return false, fmt.Errorf("JSON unmarshal rhs: %v", err)
+	}
+	c, err := newComparer(Options{})
+	if err != nil {
+		return false, err
+	}
+	c.leaf = cmp
+	return c.equal(nil, lv, rv), nil
+}
+
+// unmarshal unmarshals the json value, with numbers kept as json.Number to avoid precision loss.
+func unmarshal(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid character after top-level value")
+	}
+	return v, nil
+}
+
+type comparer struct {
+	unorderedArrays []pathPattern
+	ignoreKeys      map[string]bool
+
+	// leaf is the custom comparator of the leaf values, see EqualFunc.
+	leaf func(path string, lv, rv json.RawMessage) (bool, bool)
+}
+
+func newComparer(opts Options) (*comparer, error) {
+	unorderedArrays, err := parsePatterns(opts.UnorderedArrayPaths)
+	if err != nil {
+		return nil, fmt.Errorf("invalid unordered array paths: %v", err)
+	}
+	c := &comparer{unorderedArrays: unorderedArrays}
+	if len(opts.IgnoreKeys) != 0 {
+		c.ignoreKeys = map[string]bool{}
+		for _, k := range opts.IgnoreKeys {
+			c.ignoreKeys[k] = true
+		}
+	}
+	return c, nil
+}
+
+// equal tells whether the two json values at the path (represented as the reference tokens) are equal.
+func (c *comparer) equal(path []string, lv, rv interface{}) bool {
+	if c.leaf != nil && (isLeaf(lv) || isLeaf(rv)) {
+		lb, lerr := json.Marshal(lv)
+		rb, rerr := json.Marshal(rv)
+		if lerr == nil && rerr == nil {
+			if equal, handled := c.leaf(BuildPointer(path...), lb, rb); handled {
+				return equal
+			}
+		}
+	}
+	switch lv := lv.(type) {
+	case map[string]interface{}:
+		rv, ok := rv.(map[string]interface{})
+		if !ok || (c.ignoreKeys == nil && len(lv) != len(rv)) {
+			return false
+		}
+		for k, lvv := range lv {
+			if c.ignoreKeys[k] {
+				continue
+			}
+			rvv, ok := rv[k]
+			if !ok || !c.equal(append(path, k), lvv, rvv) {
+				return false
+			}
+		}
+		for k := range rv {
+			if _, ok := lv[k]; !ok && !c.ignoreKeys[k] {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		rv, ok := rv.([]interface{})
+		if !ok || len(lv) != len(rv) {
+			return false
+		}
+		if matchAny(c.unorderedArrays, path) {
+			return c.equalUnordered(path, lv, rv)
+		}
+		for i := range lv {
+			if !c.equal(append(path, strconv.Itoa(i)), lv[i], rv[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		rv, ok := rv.(json.Number)
+		if !ok {
+			return false
+		}
+		return equalNumber(lv, rv)
+	default:
+		return lv == rv
+	}
+}
+
+// equalUnordered tells whether the two arrays of the same length are equal as multisets.
+// The elements are regarded at the path of their index in the lhs.
+func (c *comparer) equalUnordered(path []string, lv, rv []interface{}) bool {
+	matched := make([]bool, len(rv))
+	for i, le := range lv {
+		found := false
+		for j, re := range rv {
+			if !matched[j] && c.equal(append(path, strconv.Itoa(i)), le, re) {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func isLeaf(v interface{}) bool {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		return false
+	default:
+		return true
+	}
+}
+
+func equalNumber(lv, rv json.Number) bool {
+	if lv == rv {
+		return true
+	}
+	lr, ok := new(big.Rat).SetString(lv.String())
+	if !ok {
+		return false
+	}
+	rr, ok := new(big.Rat).SetString(rv.String())
+	if !ok {
+		return false
+	}
+	return lr.Cmp(rr) == 0
+}
@@ -1,6 +1,8 @@
 package jsonset_test
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
@@ -76,6 +78,12 @@ func TestDisjointed(t *testing.T) {
 			rhs:        []byte("[1]"),
 			disjointed: false,
 		},
+		{
+			name:       "Arrays of the same objects in different order are jointed",
+			lhs:        []byte(`{"creds": [{"name": "a"}, {"name": "b"}]}`),
+			rhs:        []byte(`{"creds": [{"name": "b"}, {"name": "a"}]}`),
+			disjointed: false,
+		},
 	}
 
 	for _, tt := range cases {
@@ -233,7 +241,17 @@ func TestNullifyObject(t *testing.T) {
 		{
 			name:   "Complex map",
 			input:  []byte(`{"m": {"a": 1, "b": 2}, "array": [1,2,3], "p": 1}`),
-			result: `{"m": {"a": null, "b": null}, "array": null, "p": null}`,
+			result: `{"m": {"a": null, "b": null}, "array": [null, null, null], "p": null}`,
+		},
+		{
+			name:   "Array of objects",
+			input:  []byte(`{"a": [{"b": 1}]}`),
+			result: `{"a": [{"b": null}]}`,
+		},
+		{
+			name:   "Nested arrays",
+			input:  []byte(`{"a": [[{"b": "x"}, 1], [], {"c": [true]}], "e": []}`),
+			result: `{"a": [[{"b": null}, null], [], {"c": [null]}], "e": []}`,
 		},
 	}
 
@@ -249,3 +267,683 @@ func TestNullifyObject(t *testing.T) {
 		})
 	}
 }
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		name  string
+		lhs   []byte
+		rhs   []byte
+		equal bool
+		err   bool
+	}{
+		{
+			name: "Invalid json",
+			lhs:  []byte("1"),
+			rhs:  nil,
+			err:  true,
+		},
+		{
+			name:  "Same primaries are equal",
+			lhs:   []byte(`"a"`),
+			rhs:   []byte(` "a" `),
+			equal: true,
+		},
+		{
+			name:  "Different typed primaries are not equal",
+			lhs:   []byte("1"),
+			rhs:   []byte(`"1"`),
+			equal: false,
+		},
+		{
+			name:  "Numbers are compared by value",
+			lhs:   []byte("1"),
+			rhs:   []byte("1.0"),
+			equal: true,
+		},
+		{
+			name:  "Large numbers are compared without precision loss",
+			lhs:   []byte("9007199254740993"),
+			rhs:   []byte("9007199254740992"),
+			equal: false,
+		},
+		{
+			name:  "Objects are compared regardless of key order",
+			lhs:   []byte(`{"a": 1, "b": {"c": null, "d": [1, 2]}}`),
+			rhs:   []byte(`{"b": {"d": [1, 2], "c": null}, "a": 1}`),
+			equal: true,
+		},
+		{
+			name:  "Objects of different keys are not equal",
+			lhs:   []byte(`{"a": 1}`),
+			rhs:   []byte(`{"a": 1, "b": null}`),
+			equal: false,
+		},
+		{
+			name:  "Arrays are compared in order",
+			lhs:   []byte(`[1, 2]`),
+			rhs:   []byte(`[2, 1]`),
+			equal: false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, err := jsonset.Equal(tt.lhs, tt.rhs)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.equal, equal)
+		})
+	}
+}
+
+func TestEqualOptsUnorderedArrayPaths(t *testing.T) {
+	cases := []struct {
+		name  string
+		lhs   string
+		rhs   string
+		paths []string
+		equal bool
+		err   bool
+	}{
+		{
+			name:  "Ordered by default",
+			lhs:   `{"tags": ["a", "b"]}`,
+			rhs:   `{"tags": ["b", "a"]}`,
+			equal: false,
+		},
+		{
+			name:  "Unordered path",
+			lhs:   `{"tags": ["a", "b"], "seq": [1, 2]}`,
+			rhs:   `{"tags": ["b", "a"], "seq": [1, 2]}`,
+			paths: []string{"/tags"},
+			equal: true,
+		},
+		{
+			name:  "Other arrays remain ordered",
+			lhs:   `{"tags": ["a", "b"], "seq": [1, 2]}`,
+			rhs:   `{"tags": ["b", "a"], "seq": [2, 1]}`,
+			paths: []string{"/tags"},
+			equal: false,
+		},
+		{
+			name:  "Multiset respects duplicates",
+			lhs:   `{"tags": ["a", "a", "b"]}`,
+			rhs:   `{"tags": ["a", "b", "b"]}`,
+			paths: []string{"/tags"},
+			equal: false,
+		},
+		{
+			name:  "Wildcard path",
+			lhs:   `{"rules": [{"ports": [80, 443]}, {"ports": [22]}]}`,
+			rhs:   `{"rules": [{"ports": [443, 80]}, {"ports": [22]}]}`,
+			paths: []string{"/rules/*/ports"},
+			equal: true,
+		},
+		{
+			name:  "Prefix path",
+			lhs:   `{"properties": {"ipRules": [{"v": [1, 2]}, {"v": [3]}]}}`,
+			rhs:   `{"properties": {"ipRules": [{"v": [3]}, {"v": [2, 1]}]}}`,
+			paths: []string{"/properties/**"},
+			equal: true,
+		},
+		{
+			name:  "Invalid path",
+			lhs:   `{}`,
+			rhs:   `{}`,
+			paths: []string{"tags"},
+			err:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, err := jsonset.EqualOpts([]byte(tt.lhs), []byte(tt.rhs), jsonset.Options{UnorderedArrayPaths: tt.paths})
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.equal, equal)
+		})
+	}
+}
+
+func TestDisjointedOptsLeavesOnly(t *testing.T) {
+	cases := []struct {
+		name       string
+		lhs        string
+		rhs        string
+		leavesOnly bool
+		conflicts  []string
+		err        bool
+	}{
+		{
+			name: "Invalid json",
+			lhs:  `{`,
+			rhs:  `{}`,
+			err:  true,
+		},
+		{
+			name:      "Distinct leaves in the same container",
+			lhs:       `{"properties": {"a": 1}}`,
+			rhs:       `{"properties": {"b": 2}}`,
+			conflicts: nil,
+		},
+		{
+			name:      "Null container conflicts by default",
+			lhs:       `{"properties": null, "x": 1}`,
+			rhs:       `{"properties": {"b": 2}}`,
+			conflicts: []string{"/properties"},
+		},
+		{
+			name:       "Null container doesn't conflict with leaves only",
+			lhs:        `{"properties": null, "x": 1}`,
+			rhs:        `{"properties": {"b": 2}}`,
+			leavesOnly: true,
+			conflicts:  nil,
+		},
+		{
+			name:       "Empty object container doesn't conflict with leaves only",
+			lhs:        `{"properties": {}}`,
+			rhs:        `{"properties": 1}`,
+			leavesOnly: true,
+			conflicts:  nil,
+		},
+		{
+			name:       "Conflicting leaves are reported",
+			lhs:        `{"properties": {"a": 1, "b": {"c": [1]}, "d/e": true}, "f": "x"}`,
+			rhs:        `{"properties": {"a": 2, "b": {"c": [2]}, "d/e": false}, "g": "y"}`,
+			leavesOnly: true,
+			conflicts:  []string{"/properties/a", "/properties/b/c", "/properties/d~1e"},
+		},
+		{
+			name:       "Root leaves conflict",
+			lhs:        `1`,
+			rhs:        `2`,
+			leavesOnly: true,
+			conflicts:  []string{""},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := jsonset.Options{LeavesOnly: tt.leavesOnly}
+			conflicts, err := jsonset.Conflicts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.conflicts, conflicts)
+
+			disjointed, err := jsonset.DisjointedOpts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.conflicts) == 0, disjointed)
+			if !tt.leavesOnly {
+				disjointed, err := jsonset.Disjointed([]byte(tt.lhs), []byte(tt.rhs))
+				require.NoError(t, err)
+				require.Equal(t, len(tt.conflicts) == 0, disjointed)
+			}
+		})
+	}
+}
+
+func TestDisjointedOptsIgnoreNulls(t *testing.T) {
+	cases := []struct {
+		name        string
+		lhs         string
+		rhs         string
+		ignoreNulls bool
+		conflicts   []string
+	}{
+		{
+			name:      "Null conflicts by default",
+			lhs:       `{"a": null, "b": 1}`,
+			rhs:       `{"a": 1}`,
+			conflicts: []string{"/a"},
+		},
+		{
+			name:        "Null on the lhs",
+			lhs:         `{"a": null, "b": 1}`,
+			rhs:         `{"a": 1}`,
+			ignoreNulls: true,
+			conflicts:   nil,
+		},
+		{
+			name:        "Null on the rhs",
+			lhs:         `{"a": {"b": 1}}`,
+			rhs:         `{"a": null}`,
+			ignoreNulls: true,
+			conflicts:   nil,
+		},
+		{
+			name:        "Nested object of only null leaves",
+			lhs:         `{"a": {"b": null, "c": {"d": null}}}`,
+			rhs:         `{"a": {"b": 1}}`,
+			ignoreNulls: true,
+			conflicts:   nil,
+		},
+		{
+			name:        "Nested object having a non-null leaf",
+			lhs:         `{"a": {"b": null, "c": 1}}`,
+			rhs:         `{"a": {"b": 1, "c": 2}}`,
+			ignoreNulls: true,
+			conflicts:   []string{"/a/c"},
+		},
+		{
+			name:        "Empty object is not absent",
+			lhs:         `{"a": {}}`,
+			rhs:         `{"a": 1}`,
+			ignoreNulls: true,
+			conflicts:   []string{"/a"},
+		},
+		{
+			name:        "Non-null values still conflict",
+			lhs:         `{"a": 1, "b": null}`,
+			rhs:         `{"a": 2, "b": null}`,
+			ignoreNulls: true,
+			conflicts:   []string{"/a"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := jsonset.Options{IgnoreNulls: tt.ignoreNulls}
+			conflicts, err := jsonset.Conflicts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.conflicts, conflicts)
+
+			disjointed, err := jsonset.DisjointedOpts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.conflicts) == 0, disjointed)
+		})
+	}
+}
+
+func TestDisjointedOptsKeyNormalizer(t *testing.T) {
+	// nfc composes the few combining sequences used in this test, standing in for a custom normalizer.
+	nfc := strings.NewReplacer("e\u0301", "\u00e9", "a\u0308", "\u00e4").Replace
+
+	cases := []struct {
+		name       string
+		lhs        string
+		rhs        string
+		normalizer func(string) string
+		conflicts  []string
+		err        bool
+	}{
+		{
+			name:      "Differently normalized keys are distinct by default",
+			lhs:       `{"caf\u00e9": 1}`,
+			rhs:       `{"cafe\u0301": 2}`,
+			conflicts: nil,
+		},
+		{
+			name:       "Differently normalized keys collide with normalizer",
+			lhs:        `{"caf\u00e9": 1}`,
+			rhs:        `{"cafe\u0301": 2}`,
+			normalizer: nfc,
+			conflicts:  []string{"/caf\u00e9"},
+		},
+		{
+			name:       "Nested keys",
+			lhs:        `{"a\u0308": {"x": 1, "e\u0301": 1}}`,
+			rhs:        `{"\u00e4": {"y": 1, "\u00e9": 2}}`,
+			normalizer: nfc,
+			conflicts:  []string{"/a\u0308/e\u0301"},
+		},
+		{
+			name:      "Colliding keys are distinct by default",
+			lhs:       `{"caf\u00e9": 1, "cafe\u0301": 2}`,
+			rhs:       `{"a": 1}`,
+			conflicts: nil,
+		},
+		{
+			name:       "Colliding keys in lhs",
+			lhs:        `{"caf\u00e9": 1, "cafe\u0301": 2}`,
+			rhs:        `{"a": 1}`,
+			normalizer: nfc,
+			err:        true,
+		},
+		{
+			name:       "Nested colliding keys in rhs",
+			lhs:        `{"a": 1}`,
+			rhs:        `{"b": [{"e\u0301": 1, "\u00e9": 2}]}`,
+			normalizer: nfc,
+			err:        true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := jsonset.Options{KeyNormalizer: tt.normalizer}
+			conflicts, err := jsonset.Conflicts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			if tt.err {
+				require.Error(t, err)
+				_, err = jsonset.DisjointedOpts([]byte(tt.lhs), []byte(tt.rhs), opts)
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.conflicts, conflicts)
+
+			disjointed, err := jsonset.DisjointedOpts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.conflicts) == 0, disjointed)
+		})
+	}
+}
+
+func TestDisjointedOptsUnorderedArrays(t *testing.T) {
+	cases := []struct {
+		name      string
+		lhs       string
+		rhs       string
+		unordered bool
+		conflicts []string
+	}{
+		{
+			name:      "Arrays are jointed by default",
+			lhs:       `{"a": [1, 2]}`,
+			rhs:       `{"a": [3]}`,
+			conflicts: []string{"/a"},
+		},
+		{
+			name:      "Arrays of the same objects in different order",
+			lhs:       `{"creds": [{"name": "a", "tags": [1, 2]}, {"name": "b"}]}`,
+			rhs:       `{"creds": [{"name": "b"}, {"tags": [1, 2.0], "name": "a"}]}`,
+			unordered: true,
+			conflicts: []string{"/creds"},
+		},
+		{
+			name:      "Arrays having a common element",
+			lhs:       `{"a": [1, "x", {"k": "v"}]}`,
+			rhs:       `{"a": [{"k": "v"}, 2]}`,
+			unordered: true,
+			conflicts: []string{"/a"},
+		},
+		{
+			name:      "Arrays having no common element",
+			lhs:       `{"a": [1, 2, {"k": "v"}]}`,
+			rhs:       `{"a": [3, {"k": "w"}, [1, 2]]}`,
+			unordered: true,
+			conflicts: nil,
+		},
+		{
+			name:      "Empty array",
+			lhs:       `{"a": []}`,
+			rhs:       `{"a": [1]}`,
+			unordered: true,
+			conflicts: nil,
+		},
+		{
+			name:      "Array and non-array",
+			lhs:       `{"a": [1]}`,
+			rhs:       `{"a": 1}`,
+			unordered: true,
+			conflicts: []string{"/a"},
+		},
+		{
+			name:      "Nested arrays",
+			lhs:       `{"a": {"b": [1], "c": ["x"]}}`,
+			rhs:       `{"a": {"b": [2], "c": ["y", "x"]}}`,
+			unordered: true,
+			conflicts: []string{"/a/c"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := jsonset.Options{UnorderedArrays: tt.unordered}
+			conflicts, err := jsonset.Conflicts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.conflicts, conflicts)
+
+			disjointed, err := jsonset.DisjointedOpts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.conflicts) == 0, disjointed)
+		})
+	}
+}
+
+func TestDisjointedOptsUnicodeNormalizeKeys(t *testing.T) {
+	cases := []struct {
+		name      string
+		lhs       string
+		rhs       string
+		normalize bool
+		conflicts []string
+		err       bool
+	}{
+		{
+			name:      "NFC and NFD keys are distinct by default",
+			lhs:       `{"caf\u00e9": 1}`,
+			rhs:       `{"cafe\u0301": 2}`,
+			conflicts: nil,
+		},
+		{
+			name:      "NFC and NFD keys are the same key",
+			lhs:       `{"caf\u00e9": 1}`,
+			rhs:       `{"cafe\u0301": 2}`,
+			normalize: true,
+			conflicts: []string{"/caf\u00e9"},
+		},
+		{
+			name:      "Nested keys with multiple combining characters",
+			lhs:       `{"a\u0308": {"\u1e69": 1, "x": 1}}`,
+			rhs:       `{"\u00e4": {"s\u0323\u0307": 2, "y": 1}}`,
+			normalize: true,
+			conflicts: []string{"/a\u0308/\u1e69"},
+		},
+		{
+			name:      "Canonical ordering of the combining characters",
+			lhs:       `{"s\u0307\u0323": 1}`,
+			rhs:       `{"s\u0323\u0307": 2}`,
+			normalize: true,
+			conflicts: []string{"/s\u0307\u0323"},
+		},
+		{
+			name:      "NFC and NFD keys colliding in one object are distinct by default",
+			lhs:       `{"caf\u00e9": 1, "cafe\u0301": 2}`,
+			rhs:       `{"a": 1}`,
+			conflicts: nil,
+		},
+		{
+			name:      "NFC and NFD keys colliding in one object",
+			lhs:       `{"caf\u00e9": 1, "cafe\u0301": 2}`,
+			rhs:       `{"a": 1}`,
+			normalize: true,
+			err:       true,
+		},
+		{
+			name:      "Nested NFC and NFD keys colliding in rhs",
+			lhs:       `{"a": 1}`,
+			rhs:       `{"b": {"\u00e9": 1, "e\u0301": 2}}`,
+			normalize: true,
+			err:       true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := jsonset.Options{UnicodeNormalizeKeys: tt.normalize}
+			conflicts, err := jsonset.Conflicts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			if tt.err {
+				require.Error(t, err)
+				_, err = jsonset.DisjointedOpts([]byte(tt.lhs), []byte(tt.rhs), opts)
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.conflicts, conflicts)
+
+			disjointed, err := jsonset.DisjointedOpts([]byte(tt.lhs), []byte(tt.rhs), opts)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.conflicts) == 0, disjointed)
+		})
+	}
+
+	// KeyNormalizer is applied after the NFC normalization.
+	conflicts, err := jsonset.Conflicts([]byte(`{"CAF\u00c9": 1}`), []byte(`{"cafe\u0301": 2}`), jsonset.Options{
+		UnicodeNormalizeKeys: true,
+		KeyNormalizer:        strings.ToLower,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/CAF\u00c9"}, conflicts)
+}
+
+func TestEqualOptsIgnoreKeys(t *testing.T) {
+	cases := []struct {
+		name  string
+		lhs   string
+		rhs   string
+		keys  []string
+		equal bool
+	}{
+		{
+			name:  "Not ignored by default",
+			lhs:   `{"a": 1, "etag": "x"}`,
+			rhs:   `{"a": 1, "etag": "y"}`,
+			equal: false,
+		},
+		{
+			name:  "Different values",
+			lhs:   `{"a": 1, "etag": "x"}`,
+			rhs:   `{"a": 1, "etag": "y"}`,
+			keys:  []string{"etag"},
+			equal: true,
+		},
+		{
+			name:  "Present on one side only, at any depth",
+			lhs:   `{"a": {"b": [{"c": 1, "lastModified": 1}]}, "etag": "x"}`,
+			rhs:   `{"a": {"b": [{"c": 1}], "lastModified": 2}}`,
+			keys:  []string{"etag", "lastModified"},
+			equal: true,
+		},
+		{
+			name:  "Other keys still differ",
+			lhs:   `{"a": 1, "etag": "x"}`,
+			rhs:   `{"b": 1, "etag": "x"}`,
+			keys:  []string{"etag"},
+			equal: false,
+		},
+		{
+			name:  "Extra key on the rhs",
+			lhs:   `{"a": 1}`,
+			rhs:   `{"a": 1, "b": 1, "etag": "x"}`,
+			keys:  []string{"etag"},
+			equal: false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, err := jsonset.EqualOpts([]byte(tt.lhs), []byte(tt.rhs), jsonset.Options{IgnoreKeys: tt.keys})
+			require.NoError(t, err)
+			require.Equal(t, tt.equal, equal)
+		})
+	}
+}
+
+func TestEqualFunc(t *testing.T) {
+	// foldDNS compares the "/hosts/*" strings case-insensitively, regardless of a trailing dot.
+	foldDNS := func(path string, lv, rv json.RawMessage) (bool, bool) {
+		if !strings.HasPrefix(path, "/hosts/") {
+			return false, false
+		}
+		var ls, rs string
+		if json.Unmarshal(lv, &ls) != nil || json.Unmarshal(rv, &rs) != nil {
+			return false, false
+		}
+		return strings.EqualFold(strings.TrimSuffix(ls, "."), strings.TrimSuffix(rs, ".")), true
+	}
+
+	cases := []struct {
+		name  string
+		lhs   string
+		rhs   string
+		equal bool
+		err   bool
+	}{
+		{
+			name:  "Handled leaves",
+			lhs:   `{"hosts": ["Example.COM.", "a.b"], "id": 1}`,
+			rhs:   `{"id": 1.0, "hosts": ["example.com", "A.B"]}`,
+			equal: true,
+		},
+		{
+			name:  "Handled leaves differ",
+			lhs:   `{"hosts": ["example.com"]}`,
+			rhs:   `{"hosts": ["example.org"]}`,
+			equal: false,
+		},
+		{
+			name:  "Unhandled leaves use the default comparison",
+			lhs:   `{"hosts": [], "name": "Example"}`,
+			rhs:   `{"hosts": [], "name": "example"}`,
+			equal: false,
+		},
+		{
+			name:  "Unhandled non-string leaf",
+			lhs:   `{"hosts": [1]}`,
+			rhs:   `{"hosts": [1.0]}`,
+			equal: true,
+		},
+		{
+			name: "Invalid json",
+			lhs:  `{`,
+			rhs:  `{}`,
+			err:  true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, err := jsonset.EqualFunc([]byte(tt.lhs), []byte(tt.rhs), foldDNS)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.equal, equal)
+		})
+	}
+}
+
+func TestDisjointedDetail(t *testing.T) {
+	cases := []struct {
+		name      string
+		lhs       string
+		rhs       string
+		conflicts []string
+	}{
+		{
+			name: "Disjointed",
+			lhs:  `{"spec": {"image": "x"}}`,
+			rhs:  `{"spec": {"replicas": 1}}`,
+		},
+		{
+			name:      "Nested conflict",
+			lhs:       `{"spec": {"replicas": 1, "image": "x"}, "name": "a"}`,
+			rhs:       `{"spec": {"replicas": 2}, "name": "a"}`,
+			conflicts: []string{"/name", "/spec/replicas"},
+		},
+		{
+			name:      "Array of objects",
+			lhs:       `{"spec": {"containers": [{"name": "a"}]}}`,
+			rhs:       `{"spec": {"containers": [{"env": "b"}]}}`,
+			conflicts: []string{"/spec/containers"},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts, err := jsonset.DisjointedDetail([]byte(tt.lhs), []byte(tt.rhs))
+			require.NoError(t, err)
+			require.Equal(t, tt.conflicts, conflicts)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package jsonset
+
+import "fmt"
+
+// Kind is the kind of a json value.
+type Kind int
+
+const (
+	KindInvalid Kind = iota
+	KindObject
+	KindArray
+	KindString
+	KindNumber
+	KindBool
+	KindNull
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindObject:
+		return "object"
+	case KindArray:
+		return "array"
+	case KindString:
+		return "string"
+	case KindNumber:
+		return "number"
+	case KindBool:
+		return "bool"
+	case KindNull:
+		return "null"
+	default:
+		return "invalid"
+	}
+}
+
+// kindOfValue returns the kind of the valid json value, by its first non-space byte.
+func kindOfValue(v []byte) Kind {
+	switch kindOf(v) {
+	case '{':
+		return KindObject
+	case '[':
+		return KindArray
+	case '"':
+		return KindString
+	case 't', 'f':
+		return KindBool
+	case 'n':
+		return KindNull
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return KindNumber
+	default:
+		return KindInvalid
+	}
+}
+
+// TypeOf returns the kind of the json value referenced by the JSON pointer in the document,
+// without unmarshaling the value.
+// ErrNotFound is returned (can be tested via errors.Is) in case the referenced value doesn't exist.
+func TypeOf(doc []byte, pointer string) (Kind, error) {
+	v, err := Get(doc, pointer)
+	if err != nil {
+		return KindInvalid, err
+	}
+	k := kindOfValue(v)
+	if k == KindInvalid {
+		return KindInvalid, fmt.Errorf("%q: invalid JSON value", pointer)
+	}
+	return k, nil
+}
@@ -0,0 +1,35 @@
+package jsonset_test
+
+import (
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypeOf(t *testing.T) {
+	doc := []byte(`{"o": {}, "a": [], "s": "x", "n": -1.5, "t": true, "f": false, "z": null}`)
+	cases := []struct {
+		pointer string
+		kind    jsonset.Kind
+	}{
+		{pointer: "", kind: jsonset.KindObject},
+		{pointer: "/o", kind: jsonset.KindObject},
+		{pointer: "/a", kind: jsonset.KindArray},
+		{pointer: "/s", kind: jsonset.KindString},
+		{pointer: "/n", kind: jsonset.KindNumber},
+		{pointer: "/t", kind: jsonset.KindBool},
+		{pointer: "/f", kind: jsonset.KindBool},
+		{pointer: "/z", kind: jsonset.KindNull},
+	}
+	for _, tt := range cases {
+		t.Run(tt.pointer, func(t *testing.T) {
+			kind, err := jsonset.TypeOf(doc, tt.pointer)
+			require.NoError(t, err)
+			require.Equal(t, tt.kind, kind)
+		})
+	}
+
+	_, err := jsonset.TypeOf(doc, "/nonexist")
+	require.ErrorIs(t, err, jsonset.ErrNotFound)
+}
@@ -0,0 +1,259 @@
+package jsonset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Merge deep merges the JSON object b into the JSON object a, e.g. to overlay the ephemeral body onto the body
+// before sending the combined payload to the API. Nested objects are merged recursively, while for the other
+// conflicting values (including arrays, which are replaced rather than concatenated), b takes precedence.
+// A null in b is kept as is, rather than deleting the member (unlike MergePatch).
+//
+// It errors if either a or b is not a JSON object. The key order is preserved as MergeOrdered does.
+func Merge(a, b []byte) ([]byte, error) {
+	if !json.Valid(a) || kindOf(a) != '{' {
+		return nil, fmt.Errorf("a is not a JSON object")
+	}
+	if !json.Valid(b) || kindOf(b) != '{' {
+		return nil, fmt.Errorf("b is not a JSON object")
+	}
+	return MergeOrdered(a, b)
+}
+
+// Subtract returns the JSON object a with every path that also exists in the JSON object b removed, e.g. to
+// recover the body from the payload merged by Merge. Nested objects defined in both are subtracted recursively,
+// so that only the overlapping leaves are removed, rather than the whole subtrees. A nested object that becomes
+// empty is kept (rather than pruned), to keep the structure stable. For the other values (including arrays)
+// defined in both, the member is removed from a, regardless of the value of b.
+//
+// It errors if either a or b is not a JSON object. The key order of a is preserved, and the result is in the
+// compact form.
+func Subtract(a, b []byte) ([]byte, error) {
+	if !json.Valid(a) || kindOf(a) != '{' {
+		return nil, fmt.Errorf("a is not a JSON object")
+	}
+	if !json.Valid(b) || kindOf(b) != '{' {
+		return nil, fmt.Errorf("b is not a JSON object")
+	}
+	var buf bytes.Buffer
+	if err := subtractValue(&buf, a, b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// subtractValue writes the object a with the members also defined in the object b subtracted.
+func subtractValue(buf *bytes.Buffer, a, b []byte) error {
+	ams, err := dedupMembers(a)
+	if err != nil {
+		return err
+	}
+	bms, err := dedupMembers(b)
+	if err != nil {
+		return err
+	}
+	bvals := map[string][]byte{}
+	for _, m := range bms {
+		bvals[m.key] = m.value
+	}
+
+	buf.WriteByte('{')
+	first := true
+	for _, am := range ams {
+		bv, ok := bvals[am.key]
+		if ok && (kindOf(am.value) != '{' || kindOf(bv) != '{') {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		kb, _ := json.Marshal(am.key)
+		buf.Write(kb)
+		buf.WriteByte(':')
+		if ok {
+			err = subtractValue(buf, am.value, bv)
+		} else {
+			err = json.Compact(buf, am.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// Intersect returns the JSON object containing only the paths defined in both the JSON objects a and b, with the
+// values taken from a, e.g. to show the overlapping content in a validation error. It follows the same traversal
+// as Disjointed: nested objects defined in both are intersected recursively, and the other values (including
+// arrays) defined in both are taken as a whole. A nested object without any overlapping path is omitted, so the
+// result is {} if and only if a and b are disjointed.
+//
+// It errors if either a or b is not a JSON object. The key order of a is preserved, and the result is in the
+// compact form.
+func Intersect(a, b []byte) ([]byte, error) {
+	if !json.Valid(a) || kindOf(a) != '{' {
+		return nil, fmt.Errorf("a is not a JSON object")
+	}
+	if !json.Valid(b) || kindOf(b) != '{' {
+		return nil, fmt.Errorf("b is not a JSON object")
+	}
+	var buf bytes.Buffer
+	if _, err := intersectValue(&buf, a, b); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// intersectValue writes the members of the object a that are also defined in the object b, and returns the count
+// of the members written.
+func intersectValue(buf *bytes.Buffer, a, b []byte) (int, error) {
+	ams, err := dedupMembers(a)
+	if err != nil {
+		return 0, err
+	}
+	bms, err := dedupMembers(b)
+	if err != nil {
+		return 0, err
+	}
+	bvals := map[string][]byte{}
+	for _, m := range bms {
+		bvals[m.key] = m.value
+	}
+
+	buf.WriteByte('{')
+	n := 0
+	for _, am := range ams {
+		bv, ok := bvals[am.key]
+		if !ok {
+			continue
+		}
+		mark := buf.Len()
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		kb, _ := json.Marshal(am.key)
+		buf.Write(kb)
+		buf.WriteByte(':')
+		if kindOf(am.value) != '{' || kindOf(bv) != '{' {
+			if err := json.Compact(buf, am.value); err != nil {
+				return 0, err
+			}
+			n++
+			continue
+		}
+		cnt, err := intersectValue(buf, am.value, bv)
+		if err != nil {
+			return 0, err
+		}
+		if cnt == 0 {
+			buf.Truncate(mark)
+			continue
+		}
+		n++
+	}
+	buf.WriteByte('}')
+	return n, nil
+}
+
+// MergeOrdered deep merges the overlay json into the base json, while preserving the key order of the objects.
+// For each object:
+//   - Keys of base keep their positions. For the keys also defined in overlay, the values are taken from overlay
+//     (or merged recursively if both are objects), while still placed at base's position.
+//   - Keys only defined in overlay are appended, in overlay's order.
+//
+// Non-object values (including arrays) of the overlay replace the base ones.
+// The result is in the compact form. For duplicate keys in an object, the last value wins, placed at the
+// position of the first occurrence.
+func MergeOrdered(base, overlay []byte) ([]byte, error) {
+	if !json.Valid(base) {
+		return nil, fmt.Errorf("invalid JSON base")
+	}
+	if !json.Valid(overlay) {
+		return nil, fmt.Errorf("invalid JSON overlay")
+	}
+	var buf bytes.Buffer
+	if err := mergeOrderedValue(&buf, base, overlay); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func mergeOrderedValue(buf *bytes.Buffer, base, overlay []byte) error {
+	if kindOf(base) != '{' || kindOf(overlay) != '{' {
+		return json.Compact(buf, overlay)
+	}
+
+	bms, err := dedupMembers(base)
+	if err != nil {
+		return err
+	}
+	oms, err := dedupMembers(overlay)
+	if err != nil {
+		return err
+	}
+	overlayIdx := map[string]int{}
+	for i, m := range oms {
+		overlayIdx[m.key] = i
+	}
+
+	buf.WriteByte('{')
+	first := true
+	writeKey := func(k string) {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		kb, _ := json.Marshal(k)
+		buf.Write(kb)
+		buf.WriteByte(':')
+	}
+	baseKeys := map[string]bool{}
+	for _, bm := range bms {
+		baseKeys[bm.key] = true
+		writeKey(bm.key)
+		if i, ok := overlayIdx[bm.key]; ok {
+			if err := mergeOrderedValue(buf, bm.value, oms[i].value); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := json.Compact(buf, bm.value); err != nil {
+			return err
+		}
+	}
+	for _, om := range oms {
+		if baseKeys[om.key] {
+			continue
+		}
+		writeKey(om.key)
+		if err := json.Compact(buf, om.value); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// dedupMembers returns the members of the json object in the document order. For duplicate keys,
+// the last value wins, placed at the position of the first occurrence.
+func dedupMembers(b []byte) ([]member, error) {
+	members, err := orderedMembers(b)
+	if err != nil {
+		return nil, err
+	}
+	idx := map[string]int{}
+	var out []member
+	for _, m := range members {
+		if i, ok := idx[m.key]; ok {
+			out[i].value = m.value
+			continue
+		}
+		idx[m.key] = len(out)
+		out = append(out, m)
+	}
+	return out, nil
+}
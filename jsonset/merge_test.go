@@ -0,0 +1,304 @@
+package jsonset_test
+
+import (
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeOrdered(t *testing.T) {
+	cases := []struct {
+		name    string
+		base    string
+		overlay string
+		result  string
+		err     bool
+	}{
+		{
+			name:    "Invalid json",
+			base:    `{`,
+			overlay: `{}`,
+			err:     true,
+		},
+		{
+			name:    "Base order kept, new keys appended",
+			base:    `{"z": 1, "a": 2, "m": 3}`,
+			overlay: `{"b": 4, "a": 5}`,
+			result:  `{"z":1,"a":5,"m":3,"b":4}`,
+		},
+		{
+			name:    "Nested objects merged recursively",
+			base:    `{"props": {"y": 1, "x": {"k": 1}}, "id": "a"}`,
+			overlay: `{"props": {"x": {"j": 2}, "w": 3}}`,
+			result:  `{"props":{"y":1,"x":{"k":1,"j":2},"w":3},"id":"a"}`,
+		},
+		{
+			name:    "Arrays are replaced",
+			base:    `{"a": [1, 2], "b": 1}`,
+			overlay: `{"a": [3]}`,
+			result:  `{"a":[3],"b":1}`,
+		},
+		{
+			name:    "Non-object overlay replaces",
+			base:    `{"a": 1}`,
+			overlay: `[ 1, 2 ]`,
+			result:  `[1,2]`,
+		},
+		{
+			name:    "Duplicate keys",
+			base:    `{"a": 1, "b": 2, "a": 3}`,
+			overlay: `{}`,
+			result:  `{"a":3,"b":2}`,
+		},
+		{
+			name:    "Numbers are kept as is",
+			base:    `{"a": 9007199254740993}`,
+			overlay: `{"b": 1.0}`,
+			result:  `{"a":9007199254740993,"b":1.0}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jsonset.MergeOrdered([]byte(tt.base), []byte(tt.overlay))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.result, string(result))
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	cases := []struct {
+		name   string
+		a      string
+		b      string
+		result string
+		err    bool
+	}{
+		{
+			name: "Invalid json",
+			a:    `{`,
+			b:    `{}`,
+			err:  true,
+		},
+		{
+			name: "Non-object",
+			a:    `{"a": 1}`,
+			b:    `[1]`,
+			err:  true,
+		},
+		{
+			name:   "Disjointed",
+			a:      `{"name": "x", "props": {"sku": "basic"}}`,
+			b:      `{"props": {"password": "secret"}}`,
+			result: `{"name":"x","props":{"sku":"basic","password":"secret"}}`,
+		},
+		{
+			name:   "b takes precedence",
+			a:      `{"a": 1, "b": {"c": 2}}`,
+			b:      `{"a": 2, "b": null}`,
+			result: `{"a":2,"b":null}`,
+		},
+		{
+			name:   "Arrays are replaced",
+			a:      `{"a": [1, 2]}`,
+			b:      `{"a": [3]}`,
+			result: `{"a":[3]}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jsonset.Merge([]byte(tt.a), []byte(tt.b))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.result, string(result))
+		})
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	cases := []struct {
+		name   string
+		a      string
+		b      string
+		result string
+		err    bool
+	}{
+		{
+			name: "Invalid json",
+			a:    `{}`,
+			b:    `{`,
+			err:  true,
+		},
+		{
+			name: "Non-object",
+			a:    `[1]`,
+			b:    `{}`,
+			err:  true,
+		},
+		{
+			name:   "Overlapping leaves removed",
+			a:      `{"name": "x", "props": {"sku": "basic", "password": "secret"}, "token": [1]}`,
+			b:      `{"props": {"password": null}, "token": null}`,
+			result: `{"name":"x","props":{"sku":"basic"}}`,
+		},
+		{
+			name:   "Empty object kept",
+			a:      `{"props": {"password": "secret"}}`,
+			b:      `{"props": {"password": "secret"}}`,
+			result: `{"props":{}}`,
+		},
+		{
+			name:   "Object removed by a leaf",
+			a:      `{"props": {"password": "secret"}, "z": 1}`,
+			b:      `{"props": "x"}`,
+			result: `{"z":1}`,
+		},
+		{
+			name:   "Inverse of Merge",
+			a:      `{"name":"x","props":{"sku":"basic","password":"secret"}}`,
+			b:      `{"props": {"password": "secret"}}`,
+			result: `{"name":"x","props":{"sku":"basic"}}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jsonset.Subtract([]byte(tt.a), []byte(tt.b))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.result, string(result))
+		})
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	cases := []struct {
+		name   string
+		a      string
+		b      string
+		result string
+		err    bool
+	}{
+		{
+			name: "Non-object",
+			a:    `{}`,
+			b:    `1`,
+			err:  true,
+		},
+		{
+			name:   "Disjointed",
+			a:      `{"name": "x", "props": {"sku": "basic"}, "e": {}}`,
+			b:      `{"props": {"password": "secret"}, "e": {}}`,
+			result: `{}`,
+		},
+		{
+			name:   "Overlapping",
+			a:      `{"name": "x", "props": {"sku": "basic", "password": "a"}, "tags": [1], "obj": {"k": 1}}`,
+			b:      `{"props": {"password": "b", "z": 1}, "tags": null, "obj": "x"}`,
+			result: `{"props":{"password":"a"},"tags":[1],"obj":{"k":1}}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jsonset.Intersect([]byte(tt.a), []byte(tt.b))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.result, string(result))
+
+			disjointed, err := jsonset.Disjointed([]byte(tt.a), []byte(tt.b))
+			require.NoError(t, err)
+			require.Equal(t, disjointed, tt.result == `{}`)
+		})
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	cases := []struct {
+		name      string
+		a         string
+		b         string
+		conflicts []jsonset.Conflict
+		err       bool
+	}{
+		{
+			name: "Invalid json",
+			a:    `{}`,
+			b:    `[`,
+			err:  true,
+		},
+		{
+			name: "Disjoint keys",
+			a:    `{"a": 1}`,
+			b:    `{"b": 2}`,
+		},
+		{
+			name: "Same scalar values",
+			a:    `{"a": 1.0, "b": "x", "c": null}`,
+			b:    `{"a": 1, "b": "x", "c": null}`,
+		},
+		{
+			name: "Different scalar values",
+			a:    `{"z": {"k": "x", "j": true}, "a": 1}`,
+			b:    `{"a": 2, "z": {"j": false, "k": "x"}}`,
+			conflicts: []jsonset.Conflict{
+				{Path: "/z/j", A: []byte(`true`), B: []byte(`false`)},
+				{Path: "/a", A: []byte(`1`), B: []byte(`2`)},
+			},
+		},
+		{
+			name: "Escaped key",
+			a:    `{"a/b": {"c~d": 1}}`,
+			b:    `{"a/b": {"c~d": null}}`,
+			conflicts: []jsonset.Conflict{
+				{Path: "/a~1b/c~0d", A: []byte(`1`), B: []byte(`null`)},
+			},
+		},
+		{
+			name: "Arrays and type conflicts are skipped",
+			a:    `{"a": [1], "b": {"x": 1}, "c": "x"}`,
+			b:    `{"a": [2], "b": "x", "c": {"x": 1}}`,
+		},
+		{
+			name: "Root scalars",
+			a:    `"x"`,
+			b:    `"y"`,
+			conflicts: []jsonset.Conflict{
+				{Path: "", A: []byte(`"x"`), B: []byte(`"y"`)},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts, err := jsonset.MergeConflicts([]byte(tt.a), []byte(tt.b))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, conflicts, len(tt.conflicts))
+			for i, c := range tt.conflicts {
+				require.Equal(t, c.Path, conflicts[i].Path)
+				require.JSONEq(t, string(c.A), string(conflicts[i].A))
+				require.JSONEq(t, string(c.B), string(conflicts[i].B))
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package jsonset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Conflict is a scalar value defined differently by both sides of a merge.
+type Conflict struct {
+	// Path is the JSON pointer of the conflicting value.
+	Path string
+
+	// A is the value defined in the first json, in the compact form.
+	A json.RawMessage
+
+	// B is the value defined in the second json, in the compact form.
+	B json.RawMessage
+}
+
+// MergeConflicts reports the paths where both a and b define a scalar (string, number, bool or null) value
+// while the values differ, without producing the merge. It follows the same traversal as MergeOrdered,
+// i.e. only objects defined on both sides are merged recursively, so that an empty result means merging
+// a and b (in either order) doesn't override any scalar value.
+//
+// Arrays are not scalars and type conflicts (e.g. an object against a string) are not reported.
+// The conflicts are in the document order of a.
+func MergeConflicts(a, b []byte) ([]Conflict, error) {
+	if !json.Valid(a) {
+		return nil, fmt.Errorf("invalid JSON a")
+	}
+	if !json.Valid(b) {
+		return nil, fmt.Errorf("invalid JSON b")
+	}
+	var conflicts []Conflict
+	if err := mergeConflictValue(&conflicts, nil, a, b); err != nil {
+		return nil, err
+	}
+	return conflicts, nil
+}
+
+func mergeConflictValue(conflicts *[]Conflict, path []string, a, b []byte) error {
+	ka, kb := kindOfValue(a), kindOfValue(b)
+	if ka == KindObject && kb == KindObject {
+		ams, err := dedupMembers(a)
+		if err != nil {
+			return err
+		}
+		bms, err := dedupMembers(b)
+		if err != nil {
+			return err
+		}
+		bIdx := map[string]int{}
+		for i, m := range bms {
+			bIdx[m.key] = i
+		}
+		for _, am := range ams {
+			i, ok := bIdx[am.key]
+			if !ok {
+				continue
+			}
+			if err := mergeConflictValue(conflicts, append(path, am.key), am.value, bms[i].value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !isScalarKind(ka) || !isScalarKind(kb) {
+		return nil
+	}
+	na, err := Normalize(a)
+	if err != nil {
+		return err
+	}
+	nb, err := Normalize(b)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(na, nb) {
+		return nil
+	}
+	var ca, cb bytes.Buffer
+	if err := json.Compact(&ca, a); err != nil {
+		return err
+	}
+	if err := json.Compact(&cb, b); err != nil {
+		return err
+	}
+	*conflicts = append(*conflicts, Conflict{
+		Path: BuildPointer(path...),
+		A:    ca.Bytes(),
+		B:    cb.Bytes(),
+	})
+	return nil
+}
+
+func isScalarKind(k Kind) bool {
+	switch k {
+	case KindString, KindNumber, KindBool, KindNull:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,54 @@
+package jsonset
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MergePatch applies the JSON merge patch (RFC 7396) to the target, and returns the patched json.
+func MergePatch(target, patch []byte) ([]byte, error) {
+	result, _, err := MergePatchResult(target, patch)
+	return result, err
+}
+
+// MergePatchResult is similar to MergePatch, while it also tells whether the patch actually changed the target.
+// The change is determined semantically (via Equal), so a patch that only differs in formatting,
+// key order or number representation is not regarded as a change.
+func MergePatchResult(target, patch []byte) (result []byte, changed bool, err error) {
+	tv, err := unmarshal(target)
+	if err != nil {
+		return nil, false, fmt.Errorf("JSON unmarshal target: %v", err)
+	}
+	pv, err := unmarshal(patch)
+	if err != nil {
+		return nil, false, fmt.Errorf("JSON unmarshal patch: %v", err)
+	}
+	result, err = json.Marshal(mergePatchValue(tv, pv))
+	if err != nil {
+		return nil, false, fmt.Errorf("JSON marshal result: %v", err)
+	}
+	equal, err := Equal(target, result)
+	if err != nil {
+		return nil, false, err
+	}
+	return result, !equal, nil
+}
+
+func mergePatchValue(tv, pv interface{}) interface{} {
+	pm, ok := pv.(map[string]interface{})
+	if !ok {
+		return pv
+	}
+	tm, ok := tv.(map[string]interface{})
+	if !ok {
+		tm = map[string]interface{}{}
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+			continue
+		}
+		tm[k] = mergePatchValue(tm[k], v)
+	}
+	return tm
+}
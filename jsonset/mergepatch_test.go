@@ -0,0 +1,74 @@
+package jsonset_test
+
+import (
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePatchResult(t *testing.T) {
+	cases := []struct {
+		name    string
+		target  []byte
+		patch   []byte
+		result  string
+		changed bool
+		err     bool
+	}{
+		{
+			name:   "Invalid json",
+			target: []byte(`{}`),
+			patch:  nil,
+			err:    true,
+		},
+		{
+			name:    "Add, replace and remove keys",
+			target:  []byte(`{"a": "b", "c": {"d": "e", "f": "g"}}`),
+			patch:   []byte(`{"a": "z", "c": {"f": null}, "h": [1]}`),
+			result:  `{"a": "z", "c": {"d": "e"}, "h": [1]}`,
+			changed: true,
+		},
+		{
+			name:    "Non-object patch replaces the target",
+			target:  []byte(`{"a": 1}`),
+			patch:   []byte(`[1, 2]`),
+			result:  `[1, 2]`,
+			changed: true,
+		},
+		{
+			name:    "Same values in different representation is not a change",
+			target:  []byte(`{"a": 1, "b": {"c": [1, 2]}}`),
+			patch:   []byte(`{"b": {"c": [1.0, 2]}, "a": 1.0}`),
+			result:  `{"a": 1, "b": {"c": [1, 2]}}`,
+			changed: false,
+		},
+		{
+			name:    "Removing absent key is not a change",
+			target:  []byte(`{"a": 1}`),
+			patch:   []byte(`{"b": null}`),
+			result:  `{"a": 1}`,
+			changed: false,
+		},
+		{
+			name:    "Large numbers are kept",
+			target:  []byte(`{"a": 9007199254740992}`),
+			patch:   []byte(`{"a": 9007199254740993}`),
+			result:  `{"a": 9007199254740993}`,
+			changed: true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, changed, err := jsonset.MergePatchResult(tt.target, tt.patch)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.result, string(result))
+			require.Equal(t, tt.changed, changed)
+		})
+	}
+}
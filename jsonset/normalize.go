@@ -0,0 +1,172 @@
+package jsonset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Normalize transforms the JSON document into its normalized form, which is suitable to be sent on the wire,
+// or to be hashed. Two documents are semantically equal (see Equal) if and only if their normalized forms are
+// byte-wise equal. The rules are:
+//   - Insignificant whitespace is removed.
+//   - Object members are sorted by the byte-wise order of their keys.
+//   - Strings are encoded with the minimal escaping: only '"', '\\' and the control characters are escaped, using
+//     the short forms (e.g. "\n") where available, otherwise "\u00XX". HTML characters are not escaped.
+//   - Numbers are converted to their canonical form without precision loss: no leading "+" or zeros, no trailing
+//     zeros in the fraction, no "-0". Numbers whose decimal point falls in the range (-6, 21] relative to the first
+//     significant digit are written in the plain decimal form (e.g. "100", "0.001"), the others in the scientific
+//     form with a lower case "e" (e.g. "1e+21", "1.5e-7").
+func Normalize(b []byte) ([]byte, error) {
+	v, err := unmarshal(b)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := writeNormalized(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeNormalized(buf *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case string:
+		writeNormalizedString(buf, v)
+	case json.Number:
+		n, err := normalizeNumber(v.String())
+		if err != nil {
+			return err
+		}
+		buf.WriteString(n)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, e := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeNormalized(buf, e); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		buf.WriteByte('{')
+		for i, k := range slices.Sorted(maps.Keys(v)) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeNormalizedString(buf, k)
+			buf.WriteByte(':')
+			if err := writeNormalized(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected JSON value of type %T", v)
+	}
+	return nil
+}
+
+func writeNormalizedString(buf *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if c < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hex[c>>4])
+				buf.WriteByte(hex[c&0xf])
+				continue
+			}
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// normalizeNumber converts the JSON number literal to its canonical form. See Normalize for the rules.
+func normalizeNumber(s string) (string, error) {
+	lit := s
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	mantissa, exp := s, 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa = s[:i]
+		e, err := strconv.Atoi(strings.TrimPrefix(s[i+1:], "+"))
+		if err != nil {
+			return "", fmt.Errorf("invalid number %q: %v", lit, err)
+		}
+		exp = e
+	}
+	// The value is digits * 10^exp.
+	digits := mantissa
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		digits = mantissa[:i] + mantissa[i+1:]
+		exp -= len(mantissa) - i - 1
+	}
+	digits = strings.TrimLeft(digits, "0")
+	trimmed := strings.TrimRight(digits, "0")
+	exp += len(digits) - len(trimmed)
+	digits = trimmed
+	if digits == "" {
+		return "0", nil
+	}
+
+	var sb strings.Builder
+	if neg {
+		sb.WriteByte('-')
+	}
+	// point is the position of the decimal point relative to the first significant digit.
+	point := len(digits) + exp
+	switch {
+	case point > 21 || point <= -6:
+		sb.WriteByte(digits[0])
+		if len(digits) > 1 {
+			sb.WriteByte('.')
+			sb.WriteString(digits[1:])
+		}
+		sb.WriteByte('e')
+		if point-1 >= 0 {
+			sb.WriteByte('+')
+		}
+		sb.WriteString(strconv.Itoa(point - 1))
+	case point <= 0:
+		sb.WriteString("0.")
+		sb.WriteString(strings.Repeat("0", -point))
+		sb.WriteString(digits)
+	case point >= len(digits):
+		sb.WriteString(digits)
+		sb.WriteString(strings.Repeat("0", point-len(digits)))
+	default:
+		sb.WriteString(digits[:point])
+		sb.WriteByte('.')
+		sb.WriteString(digits[point:])
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,65 @@
+package jsonset_test
+
+import (
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		result string
+		err    bool
+	}{
+		{
+			name:  "Invalid json",
+			input: `{"a":`,
+			err:   true,
+		},
+		{
+			name:  "Trailing data",
+			input: `{} {}`,
+			err:   true,
+		},
+		{
+			name:   "Whitespace and key order",
+			input:  " {\n\t\"b\": [1, 2],  \"a\": {\"d\": null, \"c\": true} } ",
+			result: `{"a":{"c":true,"d":null},"b":[1,2]}`,
+		},
+		{
+			name:   "Strings",
+			input:  `["<a&b>", "A\"\\\/", "\n\u0001", "日本"]`,
+			result: `["<a&b>","A\"\\/","\n\u0001","日本"]`,
+		},
+		{
+			name:   "Integers",
+			input:  `[0, -0, 100, 1E2, 1.0e+2, 10000e-2, -0.0]`,
+			result: `[0,0,100,100,100,100,0]`,
+		},
+		{
+			name:   "Fractions",
+			input:  `[1.50, 0.001, 1e-6, 1.5e-7, -12.340]`,
+			result: `[1.5,0.001,0.000001,1.5e-7,-12.34]`,
+		},
+		{
+			name:   "Large numbers keep precision",
+			input:  `[9007199254740993, 123456789012345678901, 1e21, 12345678901234567890123]`,
+			result: `[9007199254740993,123456789012345678901,1e+21,1.2345678901234567890123e+22]`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jsonset.Normalize([]byte(tt.input))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.result, string(result))
+		})
+	}
+}
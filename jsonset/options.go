@@ -0,0 +1,116 @@
+package jsonset
+
+import "golang.org/x/text/unicode/norm"
+
+// Options tunes the behaviors of the jsonset functions. The zero value matches the behavior of the
+// option-less functions.
+//
+// Paths in the options are JSON pointers (RFC 6901). A "*" token matches any single object key or array index,
+// and a trailing "**" token matches any (including none) remaining tokens, e.g.:
+//   - "/tags": matches only the top level "tags"
+//   - "/rules/*/ports": matches the "ports" of every element of "rules"
+//   - "/properties/**": matches "properties" and everything beneath it
+type Options struct {
+	// UnorderedArrayPaths are the paths of the arrays that are compared as multisets, i.e. regardless of
+	// the element order. Other arrays are compared element-wise in order.
+	UnorderedArrayPaths []string
+
+	// IgnoreKeys are the object keys that are ignored by EqualOpts wherever they appear, i.e. at every nesting
+	// level (including the objects inside arrays), e.g. the server injected metadata like "etag".
+	// Unlike the paths above, they are plain key names rather than JSON pointers.
+	IgnoreKeys []string
+
+	// LeavesOnly makes DisjointedOpts regard two values as overlapping only at the leaf paths, i.e. the paths of the
+	// non-object values. A null or an empty object contributes no leaf, so that it is regarded as merely declaring
+	// the container, which doesn't conflict with the leaves beneath it from the other side.
+	// E.g. {"properties": null} and {"properties": {"a": 1}} are disjointed.
+	LeavesOnly bool
+
+	// IgnoreNulls makes DisjointedOpts regard a key whose value is null on either side as absent, e.g. for the schemas
+	// where an explicit null means "unset this field". An object that (recursively) contains only null leaves is
+	// also regarded as absent, e.g. {"a": {"b": null}} and {"a": {"c": 1}} are disjointed. Note that an empty object
+	// is not regarded as absent, unless LeavesOnly is also set.
+	IgnoreNulls bool
+
+	// UnicodeNormalizeKeys makes DisjointedOpts compare the object keys after the Unicode normalization to the NFC
+	// form (Normalization Form C, the canonical composition), so that keys that are Unicode equivalent but differently
+	// normalized (e.g. "\u00e9" in NFC, and "e\u0301" in NFD) are regarded as the same key, as a server normalizing
+	// the keys would do. NFC is chosen as it is the form most servers (and the W3C) normalize to.
+	// Distinct keys of the same object that are normalized to the same key result into an error, as Strict does for
+	// the duplicate keys, since which one the server takes is ambiguous.
+	UnicodeNormalizeKeys bool
+
+	// UnorderedArrays makes DisjointedOpts compare two arrays at the same path as unordered sets of elements, rather
+	// than regarding them as always jointed: they are jointed only if they have a common element, regardless of the
+	// order, where the objects are compared structurally, e.g. [{"name": "a"}, {"name": "b"}] and [{"name": "b"}] are
+	// jointed, while [1, 2] and [3] (or an empty array) are disjointed.
+	UnorderedArrays bool
+
+	// KeyNormalizer, if not nil, normalizes the object keys before they are compared by DisjointedOpts, e.g. for a
+	// server that also folds the case of the keys. It is applied after the NFC normalization if UnicodeNormalizeKeys
+	// is also set. The colliding keys result into an error as well.
+	KeyNormalizer func(string) string
+
+	// SmartArrayDiff makes AllDiffsOpts align the arrays by the longest common subsequence of the equal elements,
+	// so that inserting or deleting an element is reported as a single ChangeAdd or ChangeRemove, rather than the
+	// cascading changes of the following indexes. It costs O(n*m) comparisons for arrays of length n and m.
+	SmartArrayDiff bool
+
+	// Strict makes DisjointedOpts (and Conflicts), EqualOpts and AllDiffsOpts error on the json values having duplicate
+	// object keys, e.g. {"a": 1, "a": 2}, rather than silently taking the last one as encoding/json does. As which
+	// duplicate wins is up to the decoder, such a value is ambiguous, e.g. a body and an ephemeral body that are
+	// disjointed as decoded here can be jointed as decoded by the server.
+	Strict bool
+}
+
+// keyNormalizer returns the function normalizing the object keys, as per UnicodeNormalizeKeys and KeyNormalizer,
+// which is nil if neither is set.
+func (opts Options) keyNormalizer() func(string) string {
+	switch {
+	case opts.UnicodeNormalizeKeys && opts.KeyNormalizer != nil:
+		return func(k string) string { return opts.KeyNormalizer(norm.NFC.String(k)) }
+	case opts.UnicodeNormalizeKeys:
+		return norm.NFC.String
+	default:
+		return opts.KeyNormalizer
+	}
+}
+
+// pathPattern is a parsed path in the Options.
+type pathPattern []string
+
+func parsePatterns(paths []string) ([]pathPattern, error) {
+	var patterns []pathPattern
+	for _, p := range paths {
+		tokens, err := parsePointer(p)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, tokens)
+	}
+	return patterns, nil
+}
+
+func (p pathPattern) match(tokens []string) bool {
+	for i, ptk := range p {
+		if ptk == "**" && i == len(p)-1 {
+			return true
+		}
+		if i >= len(tokens) {
+			return false
+		}
+		if ptk != "*" && ptk != tokens[i] {
+			return false
+		}
+	}
+	return len(p) == len(tokens)
+}
+
+func matchAny(patterns []pathPattern, tokens []string) bool {
+	for _, p := range patterns {
+		if p.match(tokens) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,319 @@
+package jsonset
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrTestFailed is returned (wrapped) by ApplyPatch when a "test" operation of the JSON patch fails.
+var ErrTestFailed = errors.New("test failed")
+
+type patchOp struct {
+	op    string
+	path  []string
+	from  []string
+	value interface{}
+	// raw is the raw operation, for error messages.
+	raw json.RawMessage
+}
+
+// ApplyPatch applies the JSON patch (RFC 6902) to the document, and returns the patched json, whose object keys
+// are sorted. The operations are applied in order, a failed "test" operation aborts the patch with an error
+// wrapping ErrTestFailed, in which case the document is left unchanged.
+func ApplyPatch(doc, patch []byte) ([]byte, error) {
+	v, ops, err := preparePatch(doc, patch)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		if v, err = op.apply(v); err != nil {
+			return nil, err
+		}
+	}
+	return marshalPatched(v)
+}
+
+// ApplyPatchConditional is similar to ApplyPatch, while it evaluates the patch in two phases:
+//  1. All the "test" operations are evaluated against the original document, regardless of their positions.
+//  2. Only if every test passes, the remaining operations are applied in order.
+//
+// If any test fails (including testing a nonexistent path), the original document is returned with applied being
+// false, without error. This implements the optimistic concurrency, e.g. a patch guarded by a test of the version.
+func ApplyPatchConditional(doc, patch []byte) (result []byte, applied bool, err error) {
+	v, ops, err := preparePatch(doc, patch)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, op := range ops {
+		if op.op != "test" {
+			continue
+		}
+		if _, err := op.apply(v); err != nil {
+			if errors.Is(err, ErrTestFailed) {
+				return doc, false, nil
+			}
+			return nil, false, err
+		}
+	}
+	for _, op := range ops {
+		if op.op == "test" {
+			continue
+		}
+		if v, err = op.apply(v); err != nil {
+			return nil, false, err
+		}
+	}
+	result, err = marshalPatched(v)
+	if err != nil {
+		return nil, false, err
+	}
+	return result, true, nil
+}
+
+func marshalPatched(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("JSON marshal result: %v", err)
+	}
+	return b, nil
+}
+
+func preparePatch(doc, patch []byte) (interface{}, []patchOp, error) {
+	v, err := unmarshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("JSON unmarshal doc: %v", err)
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(patch, &raws); err != nil {
+		return nil, nil, fmt.Errorf("JSON unmarshal patch: %v", err)
+	}
+	var ops []patchOp
+	for _, raw := range raws {
+		op, err := parsePatchOp(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid patch operation %s: %v", raw, err)
+		}
+		ops = append(ops, op)
+	}
+	return v, ops, nil
+}
+
+func parsePatchOp(raw json.RawMessage) (patchOp, error) {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return patchOp{}, err
+	}
+	op := patchOp{raw: raw}
+	if err := json.Unmarshal(m["op"], &op.op); err != nil {
+		return patchOp{}, fmt.Errorf(`invalid "op": %v`, err)
+	}
+	var path string
+	if err := json.Unmarshal(m["path"], &path); err != nil {
+		return patchOp{}, fmt.Errorf(`invalid "path": %v`, err)
+	}
+	var err error
+	if op.path, err = parsePointer(path); err != nil {
+		return patchOp{}, err
+	}
+	switch op.op {
+	case "add", "replace", "test":
+		b, ok := m["value"]
+		if !ok {
+			return patchOp{}, fmt.Errorf(`missing "value"`)
+		}
+		if op.value, err = unmarshal(b); err != nil {
+			return patchOp{}, fmt.Errorf(`invalid "value": %v`, err)
+		}
+	case "move", "copy":
+		var from string
+		if err := json.Unmarshal(m["from"], &from); err != nil {
+			return patchOp{}, fmt.Errorf(`invalid "from": %v`, err)
+		}
+		if op.from, err = parsePointer(from); err != nil {
+			return patchOp{}, err
+		}
+		if op.op == "move" && len(op.from) < len(op.path) && slices.Equal(op.from, op.path[:len(op.from)]) {
+			return patchOp{}, fmt.Errorf("can't move a value into its own child")
+		}
+	case "remove":
+	default:
+		return patchOp{}, fmt.Errorf("unknown op %q", op.op)
+	}
+	return op, nil
+}
+
+func (op patchOp) apply(doc interface{}) (interface{}, error) {
+	var err error
+	switch op.op {
+	case "add":
+		doc, err = addValue(doc, op.path, op.value)
+	case "remove":
+		doc, _, err = removeValue(doc, op.path)
+	case "replace":
+		if len(op.path) == 0 {
+			doc = op.value
+			break
+		}
+		if doc, _, err = removeValue(doc, op.path); err == nil {
+			doc, err = addValue(doc, op.path, op.value)
+		}
+	case "move":
+		var v interface{}
+		if doc, v, err = removeValue(doc, op.from); err == nil {
+			doc, err = addValue(doc, op.path, v)
+		}
+	case "copy":
+		var v interface{}
+		if v, err = getValue(doc, op.from); err == nil {
+			doc, err = addValue(doc, op.path, deepCopy(v))
+		}
+	case "test":
+		var v interface{}
+		if v, err = getValue(doc, op.path); err != nil {
+			if errors.Is(err, ErrNotFound) {
+				err = fmt.Errorf("%w: %v", ErrTestFailed, err)
+			}
+			break
+		}
+		if !(&comparer{}).equal(nil, v, op.value) {
+			err = ErrTestFailed
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("patch operation %s: %w", op.raw, err)
+	}
+	return doc, nil
+}
+
+func getValue(doc interface{}, tokens []string) (interface{}, error) {
+	for _, tk := range tokens {
+		switch c := doc.(type) {
+		case map[string]interface{}:
+			v, ok := c[tk]
+			if !ok {
+				return nil, ErrNotFound
+			}
+			doc = v
+		case []interface{}:
+			idx, err := arrayIndex(tk, len(c))
+			if err != nil {
+				return nil, err
+			}
+			doc = c[idx]
+		default:
+			return nil, ErrNotFound
+		}
+	}
+	return doc, nil
+}
+
+// addValue adds the value at the location, and returns the updated document.
+func addValue(doc interface{}, tokens []string, v interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return v, nil
+	}
+	tk, rest := tokens[0], tokens[1:]
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			c[tk] = v
+			return c, nil
+		}
+		child, ok := c[tk]
+		if !ok {
+			return nil, ErrNotFound
+		}
+		nc, err := addValue(child, rest, v)
+		if err != nil {
+			return nil, err
+		}
+		c[tk] = nc
+		return c, nil
+	case []interface{}:
+		if len(rest) == 0 {
+			if tk == "-" {
+				return append(c, v), nil
+			}
+			idx, err := arrayIndex(tk, len(c)+1)
+			if err != nil {
+				return nil, err
+			}
+			return slices.Insert(c, idx, v), nil
+		}
+		idx, err := arrayIndex(tk, len(c))
+		if err != nil {
+			return nil, err
+		}
+		nc, err := addValue(c[idx], rest, v)
+		if err != nil {
+			return nil, err
+		}
+		c[idx] = nc
+		return c, nil
+	default:
+		return nil, ErrNotFound
+	}
+}
+
+// removeValue removes the value at the location, and returns the updated document and the removed value.
+func removeValue(doc interface{}, tokens []string) (interface{}, interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("can't remove the root")
+	}
+	tk, rest := tokens[0], tokens[1:]
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		child, ok := c[tk]
+		if !ok {
+			return nil, nil, ErrNotFound
+		}
+		if len(rest) == 0 {
+			delete(c, tk)
+			return c, child, nil
+		}
+		nc, removed, err := removeValue(child, rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		c[tk] = nc
+		return c, removed, nil
+	case []interface{}:
+		idx, err := arrayIndex(tk, len(c))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			removed := c[idx]
+			return slices.Delete(c, idx, idx+1), removed, nil
+		}
+		nc, removed, err := removeValue(c[idx], rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		c[idx] = nc
+		return c, removed, nil
+	default:
+		return nil, nil, ErrNotFound
+	}
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for i, e := range v {
+			l[i] = deepCopy(e)
+		}
+		return l
+	default:
+		return v
+	}
+}
@@ -0,0 +1,191 @@
+package jsonset_test
+
+import (
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	cases := []struct {
+		name       string
+		doc        string
+		patch      string
+		result     string
+		err        bool
+		testFailed bool
+	}{
+		{
+			name:   "Add object member and array elements",
+			doc:    `{"a": [1, 3]}`,
+			patch:  `[{"op": "add", "path": "/b", "value": {"c": null}}, {"op": "add", "path": "/a/1", "value": 2}, {"op": "add", "path": "/a/-", "value": 4}]`,
+			result: `{"a": [1, 2, 3, 4], "b": {"c": null}}`,
+		},
+		{
+			name:   "Remove and replace",
+			doc:    `{"a": [1, 2, 3], "b": 1, "c": {"d": 1}}`,
+			patch:  `[{"op": "remove", "path": "/a/0"}, {"op": "remove", "path": "/b"}, {"op": "replace", "path": "/c/d", "value": "x"}]`,
+			result: `{"a": [2, 3], "c": {"d": "x"}}`,
+		},
+		{
+			name:   "Move and copy",
+			doc:    `{"a": {"b": [1, 2]}, "c": 1}`,
+			patch:  `[{"op": "move", "from": "/c", "path": "/a/c"}, {"op": "copy", "from": "/a/b", "path": "/d"}, {"op": "add", "path": "/d/-", "value": 3}]`,
+			result: `{"a": {"b": [1, 2], "c": 1}, "d": [1, 2, 3]}`,
+		},
+		{
+			name:   "Replace root",
+			doc:    `{"a": 1}`,
+			patch:  `[{"op": "replace", "path": "", "value": [1]}]`,
+			result: `[1]`,
+		},
+		{
+			name:   "Test passes",
+			doc:    `{"version": 1.0, "a": 1}`,
+			patch:  `[{"op": "test", "path": "/version", "value": 1}, {"op": "replace", "path": "/a", "value": 2}]`,
+			result: `{"version": 1.0, "a": 2}`,
+		},
+		{
+			name:       "Test fails",
+			doc:        `{"version": 2, "a": 1}`,
+			patch:      `[{"op": "test", "path": "/version", "value": 1}, {"op": "replace", "path": "/a", "value": 2}]`,
+			err:        true,
+			testFailed: true,
+		},
+		{
+			name:  "Remove nonexistent",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "remove", "path": "/b"}]`,
+			err:   true,
+		},
+		{
+			name:  "Replace nonexistent",
+			doc:   `{"a": {"b": 1}}`,
+			patch: `[{"op": "replace", "path": "/a/c", "value": 1}]`,
+			err:   true,
+		},
+		{
+			name:  "Malformed pointer",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "replace", "path": "a", "value": 1}]`,
+			err:   true,
+		},
+		{
+			name:  "Malformed escape",
+			doc:   `{"a~": 1}`,
+			patch: `[{"op": "remove", "path": "/a~2"}]`,
+			err:   true,
+		},
+		{
+			name:  "Missing value",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "add", "path": "/b"}]`,
+			err:   true,
+		},
+		{
+			name:  "Move into own child",
+			doc:   `{"a": {"b": 1}}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/a/c"}]`,
+			err:   true,
+		},
+		{
+			name:  "Unknown op",
+			doc:   `{}`,
+			patch: `[{"op": "merge", "path": "/a"}]`,
+			err:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jsonset.ApplyPatch([]byte(tt.doc), []byte(tt.patch))
+			if tt.err {
+				require.Error(t, err)
+				if tt.testFailed {
+					require.ErrorIs(t, err, jsonset.ErrTestFailed)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.result, string(result))
+		})
+	}
+}
+
+func TestPatchRoundTrip(t *testing.T) {
+	fixtures := []struct {
+		old string
+		new string
+	}{
+		{old: `{}`, new: `{"a": {"b": {"c": [1, {"d": null}]}}}`},
+		{old: `{"a": {"b": {"c": 1, "d": 2}}, "e": [1, 2]}`, new: `{"a": {"b": {"c": 1.5}, "f": {}}, "e": [2, 1]}`},
+		{old: `{"a/b": {"m~n": "x"}, "c": true}`, new: `{"a/b": {"m~n": "y", "o": null}, "c": false}`},
+		{old: `{"a": {"b": 1}}`, new: `{"a": [{"b": 1}]}`},
+		{old: `[1, 2]`, new: `"x"`},
+	}
+	for _, f := range fixtures {
+		patch, err := jsonset.Patch([]byte(f.old), []byte(f.new))
+		require.NoError(t, err)
+		result, err := jsonset.ApplyPatch([]byte(f.old), patch)
+		require.NoError(t, err)
+
+		nr, err := jsonset.Normalize(result)
+		require.NoError(t, err)
+		nn, err := jsonset.Normalize([]byte(f.new))
+		require.NoError(t, err)
+		require.Equal(t, string(nn), string(nr), string(patch))
+	}
+}
+
+func TestApplyPatchConditional(t *testing.T) {
+	cases := []struct {
+		name    string
+		doc     string
+		patch   string
+		result  string
+		applied bool
+		err     bool
+	}{
+		{
+			name:    "Tests pass",
+			doc:     `{"version": 1, "a": 1}`,
+			patch:   `[{"op": "replace", "path": "/version", "value": 2}, {"op": "test", "path": "/version", "value": 1}, {"op": "replace", "path": "/a", "value": 2}]`,
+			result:  `{"version": 2, "a": 2}`,
+			applied: true,
+		},
+		{
+			name:    "Test fails",
+			doc:     `{"version": 2, "a": 1}`,
+			patch:   `[{"op": "replace", "path": "/a", "value": 2}, {"op": "test", "path": "/version", "value": 1}]`,
+			result:  `{"version": 2, "a": 1}`,
+			applied: false,
+		},
+		{
+			name:    "Test of nonexistent path fails",
+			doc:     `{"a": 1}`,
+			patch:   `[{"op": "test", "path": "/version", "value": 1}, {"op": "replace", "path": "/a", "value": 2}]`,
+			result:  `{"a": 1}`,
+			applied: false,
+		},
+		{
+			name:  "Invalid operation after passed tests",
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "test", "path": "/a", "value": 1}, {"op": "remove", "path": "/b"}]`,
+			err:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, applied, err := jsonset.ApplyPatchConditional([]byte(tt.doc), []byte(tt.patch))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.applied, applied)
+			require.JSONEq(t, tt.result, string(result))
+		})
+	}
+}
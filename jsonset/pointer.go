@@ -0,0 +1,307 @@
+package jsonset
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned when the value referenced by a JSON pointer doesn't exist in the document.
+var ErrNotFound = errors.New("not found")
+
+var (
+	pointerEscaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+// EscapePointerToken escapes the raw key name into a JSON pointer reference token, i.e. "~" is escaped as "~0",
+// and "/" is escaped as "~1".
+func EscapePointerToken(s string) string {
+	return pointerEscaper.Replace(s)
+}
+
+// UnescapePointerToken reverts EscapePointerToken, i.e. unescapes the JSON pointer reference token into the raw key name.
+func UnescapePointerToken(s string) string {
+	return pointerUnescaper.Replace(s)
+}
+
+// BuildPointer builds the JSON pointer from the raw (unescaped) reference tokens.
+// E.g. BuildPointer("a/b", "c~d", "0") results into "/a~1b/c~0d/0". No token results into the root pointer "".
+func BuildPointer(tokens ...string) string {
+	var sb strings.Builder
+	for _, tk := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(EscapePointerToken(tk))
+	}
+	return sb.String()
+}
+
+// parsePointer parses the JSON pointer (RFC 6901) into the unescaped reference tokens.
+// The root pointer "" results into no token.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with %q", pointer, "/")
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, tk := range tokens {
+		for j := 0; j < len(tk); j++ {
+			if tk[j] == '~' && (j+1 == len(tk) || (tk[j+1] != '0' && tk[j+1] != '1')) {
+				return nil, fmt.Errorf("invalid JSON pointer %q: %q must be followed by %q or %q", pointer, "~", "0", "1")
+			}
+		}
+		tokens[i] = UnescapePointerToken(tk)
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses the reference token as an index of an array of the length.
+func arrayIndex(token string, length int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if idx >= length {
+		return 0, ErrNotFound
+	}
+	return idx, nil
+}
+
+// Get returns the json value referenced by the JSON pointer (RFC 6901) in the document.
+// ErrNotFound is returned (can be tested via errors.Is) in case the referenced value doesn't exist.
+func Get(doc []byte, pointer string) (json.RawMessage, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(doc) {
+		return nil, fmt.Errorf("invalid JSON document")
+	}
+	v := json.RawMessage(bytes.TrimSpace(doc))
+	for _, tk := range tokens {
+		v, err = getChild(v, tk)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				return nil, fmt.Errorf("%q: %w", pointer, ErrNotFound)
+			}
+			return nil, fmt.Errorf("%q: %v", pointer, err)
+		}
+	}
+	return v, nil
+}
+
+// GetInto is similar to Get, while it decodes the referenced json value into out, as json.Unmarshal does.
+// The numbers are decoded without precision loss, into json.Number for the interface{} typed values as well.
+// ErrNotFound is returned (can be tested via errors.Is) in case the referenced value doesn't exist.
+func GetInto(doc []byte, pointer string, out interface{}) error {
+	v, err := Get(doc, pointer)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(v))
+	dec.UseNumber()
+	if err := dec.Decode(out); err != nil {
+		return fmt.Errorf("%q: %v", pointer, err)
+	}
+	return nil
+}
+
+// GetAll is similar to Get, while the pointer can contain wildcard "*" tokens, each matches every element
+// of an array, or every member of an object. E.g. "/items/*/id", or "/a/*/b/*/c".
+// The matched values are returned in the document order. An empty slice is returned if nothing matches.
+// Note that a "*" token is always regarded as a wildcard, use Get to reference an object key named "*".
+func GetAll(doc []byte, pointer string) ([]json.RawMessage, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(doc) {
+		return nil, fmt.Errorf("invalid JSON document")
+	}
+	matches := []json.RawMessage{json.RawMessage(bytes.TrimSpace(doc))}
+	for _, tk := range tokens {
+		var next []json.RawMessage
+		for _, v := range matches {
+			if tk != "*" {
+				child, err := getChild(v, tk)
+				if err != nil {
+					if errors.Is(err, ErrNotFound) {
+						continue
+					}
+					return nil, fmt.Errorf("%q: %v", pointer, err)
+				}
+				next = append(next, child)
+				continue
+			}
+			children, err := getChildren(v)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %v", pointer, err)
+			}
+			next = append(next, children...)
+		}
+		matches = next
+	}
+	if matches == nil {
+		matches = []json.RawMessage{}
+	}
+	return matches, nil
+}
+
+// getChild returns the child of the json value referenced by the token.
+// ErrNotFound is returned if the json value is not a container, or the child doesn't exist.
+func getChild(v json.RawMessage, token string) (json.RawMessage, error) {
+	switch kindOf(v) {
+	case '{':
+		var m map[string]json.RawMessage
+		if err := json.Unmarshal(v, &m); err != nil {
+			return nil, err
+		}
+		child, ok := m[token]
+		if !ok {
+			return nil, ErrNotFound
+		}
+		return child, nil
+	case '[':
+		var l []json.RawMessage
+		if err := json.Unmarshal(v, &l); err != nil {
+			return nil, err
+		}
+		idx, err := arrayIndex(token, len(l))
+		if err != nil {
+			return nil, err
+		}
+		return l[idx], nil
+	default:
+		return nil, ErrNotFound
+	}
+}
+
+// getChildren returns the array elements, or the object member values in the document order.
+// Non-container json value has no children.
+func getChildren(v json.RawMessage) ([]json.RawMessage, error) {
+	switch kindOf(v) {
+	case '{':
+		members, err := orderedMembers(v)
+		if err != nil {
+			return nil, err
+		}
+		var out []json.RawMessage
+		for _, m := range members {
+			out = append(out, m.value)
+		}
+		return out, nil
+	case '[':
+		var l []json.RawMessage
+		if err := json.Unmarshal(v, &l); err != nil {
+			return nil, err
+		}
+		return l, nil
+	default:
+		return nil, nil
+	}
+}
+
+// kindOf returns the first non-space byte of the json value, which tells the kind of it.
+func kindOf(v json.RawMessage) byte {
+	v = bytes.TrimSpace(v)
+	if len(v) == 0 {
+		return 0
+	}
+	return v[0]
+}
+
+type member struct {
+	key   string
+	value json.RawMessage
+}
+
+// orderedMembers returns the members of the json object in the document order.
+func orderedMembers(b []byte) ([]member, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	tk, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := tk.(json.Delim); !ok || d != '{' {
+		return nil, fmt.Errorf("not a JSON object")
+	}
+	var members []member
+	for dec.More() {
+		tk, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := tk.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid object key %v", tk)
+		}
+		var v json.RawMessage
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		members = append(members, member{key: key, value: v})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// LeafPaths returns the JSON pointers of all the leaves (i.e. the non-object, non-array values, including null) of
+// the document, descending into the objects and arrays (whose indexes are the reference tokens), in the document
+// order. An empty object or array contributes no leaf, and a non-container document results into the root pointer "".
+func LeafPaths(b []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	paths := []string{}
+	if err := collectLeafPaths(dec, nil, &paths); err != nil {
+		return nil, fmt.Errorf("JSON unmarshal: %v", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("JSON unmarshal: invalid character after top-level value")
+	}
+	return paths, nil
+}
+
+// collectLeafPaths reads the next json value from dec, and appends the JSON pointers of its leaves to paths.
+func collectLeafPaths(dec *json.Decoder, path []string, paths *[]string) error {
+	tk, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tk {
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if err := collectLeafPaths(dec, append(slices.Clip(path), key.(string)), paths); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		return err
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := collectLeafPaths(dec, append(slices.Clip(path), strconv.Itoa(i)), paths); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		return err
+	default:
+		*paths = append(*paths, BuildPointer(path...))
+		return nil
+	}
+}
@@ -0,0 +1,294 @@
+package jsonset_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	doc := []byte(`{"a": {"b": [1, {"c": "x"}]}, "d/e": 2, "f~g": 3}`)
+	cases := []struct {
+		name     string
+		pointer  string
+		result   string
+		notFound bool
+		err      bool
+	}{
+		{
+			name:    "Root",
+			pointer: "",
+			result:  string(doc),
+		},
+		{
+			name:    "Nested",
+			pointer: "/a/b/1/c",
+			result:  `"x"`,
+		},
+		{
+			name:    "Escaped keys",
+			pointer: "/d~1e",
+			result:  `2`,
+		},
+		{
+			name:    "Escaped tilde",
+			pointer: "/f~0g",
+			result:  `3`,
+		},
+		{
+			name:     "Missing key",
+			pointer:  "/a/x",
+			notFound: true,
+		},
+		{
+			name:     "Index out of range",
+			pointer:  "/a/b/2",
+			notFound: true,
+		},
+		{
+			name:     "Descend into primary",
+			pointer:  "/a/b/0/c",
+			notFound: true,
+		},
+		{
+			name:    "Invalid index",
+			pointer: "/a/b/01",
+			err:     true,
+		},
+		{
+			name:    "Invalid pointer",
+			pointer: "a",
+			err:     true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := jsonset.Get(doc, tt.pointer)
+			if tt.notFound {
+				require.ErrorIs(t, err, jsonset.ErrNotFound)
+				return
+			}
+			if tt.err {
+				require.Error(t, err)
+				require.NotErrorIs(t, err, jsonset.ErrNotFound)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.result, string(v))
+		})
+	}
+}
+
+func TestGetAll(t *testing.T) {
+	doc := []byte(`{
+	"items": [
+		{"id": 1, "b": [{"c": "x"}, {"c": "y"}]},
+		{"id": 2, "b": [{"c": "z"}]},
+		{"name": "no id"}
+	],
+	"obj": {"z": 1, "a": 2}
+}`)
+	cases := []struct {
+		name    string
+		pointer string
+		result  []string
+		err     bool
+	}{
+		{
+			name:    "Wildcard on array",
+			pointer: "/items/*/id",
+			result:  []string{`1`, `2`},
+		},
+		{
+			name:    "Nested wildcards",
+			pointer: "/items/*/b/*/c",
+			result:  []string{`"x"`, `"y"`, `"z"`},
+		},
+		{
+			name:    "Wildcard on object keeps document order",
+			pointer: "/obj/*",
+			result:  []string{`1`, `2`},
+		},
+		{
+			name:    "No wildcard",
+			pointer: "/items/0/id",
+			result:  []string{`1`},
+		},
+		{
+			name:    "No match",
+			pointer: "/items/*/nonexist",
+			result:  []string{},
+		},
+		{
+			name:    "Invalid pointer",
+			pointer: "items",
+			err:     true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			vs, err := jsonset.GetAll(doc, tt.pointer)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, vs)
+			var actual []string
+			for _, v := range vs {
+				actual = append(actual, string(v))
+			}
+			if len(tt.result) == 0 {
+				require.Empty(t, actual)
+				return
+			}
+			require.Equal(t, tt.result, actual)
+		})
+	}
+}
+
+func TestPointerToken(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		escaped string
+	}{
+		{
+			name:    "Plain",
+			raw:     "foo",
+			escaped: "foo",
+		},
+		{
+			name:    "Slash",
+			raw:     "a/b",
+			escaped: "a~1b",
+		},
+		{
+			name:    "Tilde",
+			raw:     "a~b",
+			escaped: "a~0b",
+		},
+		{
+			name:    "Tilde followed by one",
+			raw:     "~1",
+			escaped: "~01",
+		},
+		{
+			name:    "Slash and tilde",
+			raw:     "/~",
+			escaped: "~1~0",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.escaped, jsonset.EscapePointerToken(tt.raw))
+			require.Equal(t, tt.raw, jsonset.UnescapePointerToken(tt.escaped))
+		})
+	}
+}
+
+func TestBuildPointer(t *testing.T) {
+	require.Equal(t, "", jsonset.BuildPointer())
+	require.Equal(t, "/a~1b/c~0d/0", jsonset.BuildPointer("a/b", "c~d", "0"))
+	require.Equal(t, "/", jsonset.BuildPointer(""))
+
+	doc := []byte(`{"a/b": {"c~d": [1, 2]}}`)
+	v, err := jsonset.Get(doc, jsonset.BuildPointer("a/b", "c~d", "1"))
+	require.NoError(t, err)
+	require.Equal(t, "2", string(v))
+}
+
+func TestGetInto(t *testing.T) {
+	doc := []byte(`{"a": {"id": 9007199254740993, "name": "x"}, "b": [1.50]}`)
+
+	type item struct {
+		ID   json.Number `json:"id"`
+		Name string      `json:"name"`
+	}
+	var it item
+	require.NoError(t, jsonset.GetInto(doc, "/a", &it))
+	require.Equal(t, item{ID: "9007199254740993", Name: "x"}, it)
+
+	var n json.Number
+	require.NoError(t, jsonset.GetInto(doc, "/b/0", &n))
+	require.Equal(t, json.Number("1.50"), n)
+
+	var v interface{}
+	require.NoError(t, jsonset.GetInto(doc, "/a/id", &v))
+	require.Equal(t, json.Number("9007199254740993"), v)
+
+	var s string
+	err := jsonset.GetInto(doc, "/c", &s)
+	require.ErrorIs(t, err, jsonset.ErrNotFound)
+
+	err = jsonset.GetInto(doc, "/a/id", &s)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, jsonset.ErrNotFound)
+}
+
+func TestLeafPaths(t *testing.T) {
+	cases := []struct {
+		name  string
+		doc   string
+		paths []string
+		err   bool
+	}{
+		{
+			name:  "Object in document order",
+			doc:   `{"z": 1, "a": {"c": null, "b": "x"}}`,
+			paths: []string{"/z", "/a/c", "/a/b"},
+		},
+		{
+			name:  "Array indexes",
+			doc:   `{"a": [1, {"b": true}, [null]]}`,
+			paths: []string{"/a/0", "/a/1/b", "/a/2/0"},
+		},
+		{
+			name:  "Escaped keys",
+			doc:   `{"a/b": {"c~d": 1}}`,
+			paths: []string{"/a~1b/c~0d"},
+		},
+		{
+			name:  "Empty containers contribute no leaf",
+			doc:   `{"a": {}, "b": [], "c": 1}`,
+			paths: []string{"/c"},
+		},
+		{
+			name:  "Empty object",
+			doc:   `{}`,
+			paths: []string{},
+		},
+		{
+			name:  "Scalar",
+			doc:   `"x"`,
+			paths: []string{""},
+		},
+		{
+			name: "Invalid json",
+			doc:  `{"a": `,
+			err:  true,
+		},
+		{
+			name: "Trailing data",
+			doc:  `{} 1`,
+			err:  true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			paths, err := jsonset.LeafPaths([]byte(tt.doc))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.paths, paths)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package jsonset
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Project builds a new json object by selecting the values from doc and placing them at the renamed paths, per
+// the mapping from the source JSON pointer to the destination JSON pointer. E.g. the mapping
+// {"/properties/name": "/displayName", "/id": "/meta/id"} projects `{"id": 1, "properties": {"name": "x"}}` into
+// `{"displayName": "x", "meta": {"id": 1}}`.
+//
+// The destination tokens are always regarded as object keys, the objects are created as needed. The sources
+// that don't exist in doc are skipped. The result is an empty object if nothing is projected.
+//
+// The mapping is rejected, regardless of doc, if two sources map to the same destination, or a destination is
+// a prefix of another destination (as the value of the former would be overwritten by the objects of the latter).
+func Project(doc []byte, mapping map[string]string) ([]byte, error) {
+	v, err := unmarshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("JSON unmarshal doc: %v", err)
+	}
+
+	type projection struct {
+		src, dst       string
+		srcTks, dstTks []string
+	}
+	var projections []projection
+	for src, dst := range mapping {
+		srcTks, err := parsePointer(src)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source %q: %v", src, err)
+		}
+		dstTks, err := parsePointer(dst)
+		if err != nil {
+			return nil, fmt.Errorf("invalid destination %q: %v", dst, err)
+		}
+		if len(dstTks) == 0 {
+			return nil, fmt.Errorf("invalid destination of %q: the root can't be a destination", src)
+		}
+		projections = append(projections, projection{src: src, dst: dst, srcTks: srcTks, dstTks: dstTks})
+	}
+	slices.SortFunc(projections, func(a, b projection) int {
+		if c := slices.Compare(a.dstTks, b.dstTks); c != 0 {
+			return c
+		}
+		return strings.Compare(a.src, b.src)
+	})
+	// As sorted, a destination that is a prefix of the others is always followed by one of them.
+	for i := 1; i < len(projections); i++ {
+		prev, cur := projections[i-1], projections[i]
+		if len(prev.dstTks) <= len(cur.dstTks) && slices.Equal(prev.dstTks, cur.dstTks[:len(prev.dstTks)]) {
+			return nil, fmt.Errorf("conflicting destinations: %q (from %q) and %q (from %q)", prev.dst, prev.src, cur.dst, cur.src)
+		}
+	}
+
+	out := map[string]interface{}{}
+	for _, p := range projections {
+		sv, err := getValue(v, p.srcTks)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return nil, fmt.Errorf("source %q: %v", p.src, err)
+		}
+		m := out
+		for _, tk := range p.dstTks[:len(p.dstTks)-1] {
+			child, ok := m[tk].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				m[tk] = child
+			}
+			m = child
+		}
+		m[p.dstTks[len(p.dstTks)-1]] = sv
+	}
+	return marshalPatched(out)
+}
@@ -0,0 +1,89 @@
+package jsonset_test
+
+import (
+	"testing"
+
+	"github.com/magodo/terraform-plugin-framework-helper/jsonset"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProject(t *testing.T) {
+	doc := `{"id": 1, "properties": {"name": "x", "tags": ["a", "b"], "big": 12345678901234567890}}`
+
+	cases := []struct {
+		name    string
+		mapping map[string]string
+		result  string
+		err     bool
+	}{
+		{
+			name:    "Empty mapping",
+			mapping: nil,
+			result:  `{}`,
+		},
+		{
+			name: "Rename and nest",
+			mapping: map[string]string{
+				"/properties/name": "/displayName",
+				"/id":              "/meta/id",
+				"/properties/big":  "/meta/big",
+			},
+			result: `{"displayName": "x", "meta": {"id": 1, "big": 12345678901234567890}}`,
+		},
+		{
+			name: "Array element and whole object",
+			mapping: map[string]string{
+				"/properties/tags/1": "/tag",
+				"/properties":        "/props",
+			},
+			result: `{"tag": "b", "props": {"name": "x", "tags": ["a", "b"], "big": 12345678901234567890}}`,
+		},
+		{
+			name: "Missing sources skipped",
+			mapping: map[string]string{
+				"/nope":              "/a",
+				"/properties/tags/5": "/b",
+				"/id":                "/c",
+			},
+			result: `{"c": 1}`,
+		},
+		{
+			name: "Same destination",
+			mapping: map[string]string{
+				"/id":              "/a",
+				"/properties/name": "/a",
+			},
+			err: true,
+		},
+		{
+			name: "Destination prefix",
+			mapping: map[string]string{
+				"/id":              "/a",
+				"/properties/name": "/a/b",
+			},
+			err: true,
+		},
+		{
+			name:    "Root destination",
+			mapping: map[string]string{"/id": ""},
+			err:     true,
+		},
+		{
+			name:    "Invalid pointer",
+			mapping: map[string]string{"id": "/a"},
+			err:     true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jsonset.Project([]byte(doc), tt.mapping)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.result, string(result))
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package writeonly
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/dynamic"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+)
+
+// DefaultKey is the private state key of the record of HasChanged and Store.
+const DefaultKey = "write_only"
+
+// HasChanged tells whether the write-only value differs from the one recorded by Store, e.g. in ModifyPlan with the
+// value of the config. The value can be of any type (e.g. a string, a map or an object). It is regarded as changed
+// if it is not fully known, or if no value is recorded yet while it is non-null, or if a value is recorded while it
+// is null.
+func HasChanged(ctx context.Context, priv ephemeral.PrivateData, cfgValue attr.Value) (bool, diag.Diagnostics) {
+	return HasChangedAt(ctx, priv, DefaultKey, cfgValue)
+}
+
+// Store records the hash of the write-only value in the private state, e.g. in Create and Update with the value
+// of the config. A null value removes the record. The hash is salted (see ephemeral.Options.SaltHash), as the
+// write-only values are typically low entropy secrets, e.g. passwords.
+func Store(ctx context.Context, priv ephemeral.PrivateData, cfgValue attr.Value) diag.Diagnostics {
+	return StoreAt(ctx, priv, DefaultKey, cfgValue)
+}
+
+// HasChangedAt is similar to HasChanged, while the value is recorded at the private state key, for the resources
+// having multiple write-only attributes, e.g. keyed by the attribute names. See ephemeral.New for the valid keys.
+func HasChangedAt(ctx context.Context, priv ephemeral.PrivateData, key string, cfgValue attr.Value) (bool, diag.Diagnostics) {
+	s, diags := newStore(key)
+	if diags.HasError() {
+		return false, diags
+	}
+	v := toDynamic(cfgValue)
+	if !v.IsNull() && !dynamic.IsFullyKnown(v) {
+		return true, diags
+	}
+	return s.Diff(ctx, priv, v)
+}
+
+// StoreAt is similar to Store, while the value is recorded at the private state key. See HasChangedAt.
+func StoreAt(ctx context.Context, priv ephemeral.PrivateData, key string, cfgValue attr.Value) diag.Diagnostics {
+	s, diags := newStore(key)
+	if diags.HasError() {
+		return diags
+	}
+	v := toDynamic(cfgValue)
+	if v.IsNull() {
+		return s.Clear(ctx, priv)
+	}
+	if !dynamic.IsFullyKnown(v) {
+		diags.AddError(
+			`Invalid write-only value`,
+			`The write-only value to record is not fully known`,
+		)
+		return diags
+	}
+	b, err := dynamic.ToJSON(v)
+	if err != nil {
+		diags.AddError(
+			`Error to marshal the write-only value`,
+			err.Error(),
+		)
+		return diags
+	}
+	return s.SetWithOptions(ctx, priv, b, ephemeral.Options{SaltHash: true})
+}
+
+func newStore(key string) (*ephemeral.Store, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	s, err := ephemeral.New(key)
+	if err != nil {
+		diags.AddError(
+			`Invalid write-only private state key`,
+			err.Error(),
+		)
+		return nil, diags
+	}
+	return s, diags
+}
+
+// toDynamic wraps the value into a dynamic value, which is null or unknown if the (underlying) value is.
+func toDynamic(v attr.Value) types.Dynamic {
+	if dv, ok := v.(types.Dynamic); ok && !dv.IsNull() && !dv.IsUnknown() {
+		v = dv.UnderlyingValue()
+	}
+	switch {
+	case v == nil || v.IsNull():
+		return types.DynamicNull()
+	case v.IsUnknown():
+		return types.DynamicUnknown()
+	default:
+		return types.DynamicValue(v)
+	}
+}
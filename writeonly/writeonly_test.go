@@ -0,0 +1,94 @@
+package writeonly_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/magodo/terraform-plugin-framework-helper/ephemeral"
+	"github.com/magodo/terraform-plugin-framework-helper/writeonly"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreHasChanged(t *testing.T) {
+	ctx := context.Background()
+	tags := func(v string) types.Map {
+		return types.MapValueMust(types.StringType, map[string]attr.Value{"a": types.StringValue(v)})
+	}
+
+	cases := []struct {
+		name    string
+		value   attr.Value
+		other   attr.Value
+		unknown attr.Value
+	}{
+		{
+			name:    "string",
+			value:   types.StringValue("foo"),
+			other:   types.StringValue("bar"),
+			unknown: types.StringUnknown(),
+		},
+		{
+			name:    "map",
+			value:   tags("foo"),
+			other:   tags("bar"),
+			unknown: types.MapValueMust(types.StringType, map[string]attr.Value{"a": types.StringUnknown()}),
+		},
+		{
+			name:    "dynamic",
+			value:   types.DynamicValue(types.StringValue("foo")),
+			other:   types.DynamicValue(types.StringValue("bar")),
+			unknown: types.DynamicValue(types.StringUnknown()),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			d := ephemeral.NewMemoryPrivateData()
+
+			changed, diags := writeonly.HasChanged(ctx, d, tt.value)
+			require.False(t, diags.HasError())
+			require.True(t, changed)
+
+			require.False(t, writeonly.Store(ctx, d, tt.value).HasError())
+			changed, diags = writeonly.HasChanged(ctx, d, tt.value)
+			require.False(t, diags.HasError())
+			require.False(t, changed)
+			changed, diags = writeonly.HasChanged(ctx, d, tt.other)
+			require.False(t, diags.HasError())
+			require.True(t, changed)
+			changed, diags = writeonly.HasChanged(ctx, d, tt.unknown)
+			require.False(t, diags.HasError())
+			require.True(t, changed)
+			require.True(t, writeonly.Store(ctx, d, tt.unknown).HasError())
+
+			// The value is unset.
+			changed, diags = writeonly.HasChanged(ctx, d, types.StringNull())
+			require.False(t, diags.HasError())
+			require.True(t, changed)
+			require.False(t, writeonly.Store(ctx, d, types.StringNull()).HasError())
+			changed, diags = writeonly.HasChanged(ctx, d, types.StringNull())
+			require.False(t, diags.HasError())
+			require.False(t, changed)
+		})
+	}
+}
+
+func TestStoreAt(t *testing.T) {
+	ctx := context.Background()
+	d := ephemeral.NewMemoryPrivateData()
+
+	require.False(t, writeonly.StoreAt(ctx, d, "password", types.StringValue("foo")).HasError())
+	require.False(t, writeonly.StoreAt(ctx, d, "token", types.StringValue("bar")).HasError())
+
+	changed, diags := writeonly.HasChangedAt(ctx, d, "password", types.StringValue("foo"))
+	require.False(t, diags.HasError())
+	require.False(t, changed)
+	changed, diags = writeonly.HasChangedAt(ctx, d, "token", types.StringValue("foo"))
+	require.False(t, diags.HasError())
+	require.True(t, changed)
+
+	_, diags = writeonly.HasChangedAt(ctx, d, "", types.StringValue("foo"))
+	require.True(t, diags.HasError())
+	require.True(t, writeonly.StoreAt(ctx, d, ".reserved", types.StringValue("foo")).HasError())
+}